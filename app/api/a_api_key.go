@@ -0,0 +1,179 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/errors"
+	gohttp "github.com/shasw94/projX/pkg/http/wrapper"
+	"github.com/shasw94/projX/validation"
+)
+
+// APIKeyAPI handle service account and api key api
+type APIKeyAPI struct {
+	service interfaces.IAPIKeyService
+}
+
+// NewAPIKeyAPI return new APIKeyAPI pointer
+func NewAPIKeyAPI(service interfaces.IAPIKeyService) *APIKeyAPI {
+	return &APIKeyAPI{service: service}
+}
+
+// apiKeySchema convert api key model to schema
+func apiKeySchema(key *models.APIKey) schema.APIKey {
+	return schema.APIKey{
+		ID:          key.ID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		Permissions: key.PermissionList(),
+		CreatedAt:   key.CreatedAt,
+		ExpiresAt:   key.ExpiresAt,
+		LastUsedAt:  key.LastUsedAt,
+		RevokedAt:   key.RevokedAt,
+	}
+}
+
+// CreateServiceAccount godoc
+// @Tags APIKey
+// @Summary api create service account
+// @Description api create user which authenticates by api keys only
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body schema.ServiceAccountBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/service-accounts [post]
+func (a *APIKeyAPI) CreateServiceAccount(c *gin.Context) gohttp.Response {
+	var params schema.ServiceAccountBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: errors.InvalidParams.Newm(err.Error()),
+		}
+	}
+
+	user, err := a.service.CreateServiceAccount(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data: schema.ServiceAccount{
+			ID:        user.ID,
+			Username:  user.Username,
+			FullName:  user.FullName,
+			RoleID:    user.RoleID,
+			CreatedAt: user.CreatedAt,
+		},
+	}
+}
+
+// CreateKey godoc
+// @Tags APIKey
+// @Summary api create api key
+// @Description api mint api key for service account, the key is only returned once
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Service account ID"
+// @Param body body schema.APIKeyBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/service-accounts/{id}/api-keys [post]
+func (a *APIKeyAPI) CreateKey(c *gin.Context) gohttp.Response {
+	var params schema.APIKeyBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: errors.InvalidParams.Newm(err.Error()),
+		}
+	}
+
+	key, value, err := a.service.CreateKey(c, c.Param("id"), &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data: schema.APIKeyCredentials{
+			APIKey: apiKeySchema(key),
+			Key:    value,
+		},
+	}
+}
+
+// ListKeys godoc
+// @Tags APIKey
+// @Summary api list api keys
+// @Description api list api keys of service account
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Service account ID"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/service-accounts/{id}/api-keys [get]
+func (a *APIKeyAPI) ListKeys(c *gin.Context) gohttp.Response {
+	keys, err := a.service.ListKeys(c, c.Param("id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	res := make([]schema.APIKey, 0, len(*keys))
+	for i := range *keys {
+		res = append(res, apiKeySchema(&(*keys)[i]))
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  res,
+	}
+}
+
+// RevokeKey godoc
+// @Tags APIKey
+// @Summary api revoke api key
+// @Description api revoke api key of service account
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Service account ID"
+// @Param key_id path string true "API key ID"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/service-accounts/{id}/api-keys/{key_id} [delete]
+func (a *APIKeyAPI) RevokeKey(c *gin.Context) gohttp.Response {
+	err := a.service.RevokeKey(c, c.Param("id"), c.Param("key_id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
@@ -4,10 +4,21 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/shasw94/projX/app/interfaces"
 	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
 	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/app"
 	"github.com/shasw94/projX/pkg/errors"
 	gohttp "github.com/shasw94/projX/pkg/http/wrapper"
+	"github.com/shasw94/projX/pkg/utils"
 	"github.com/shasw94/projX/validation"
+	"io"
+)
+
+const (
+	// defaultAccessExpired lifetime of access token in seconds when not configured
+	defaultAccessExpired = 7200
+	// defaultRefreshExpired lifetime of refresh token in hours when not configured
+	defaultRefreshExpired = 24
 )
 
 // AuthAPI handle authentication api
@@ -20,10 +31,76 @@ func NewAuthAPI(service interfaces.IAuthService) *AuthAPI {
 	return &AuthAPI{service: service}
 }
 
+// invalidParams error of request failing validation, the response message lists the failed rules
+func invalidParams(err error) error {
+	return errors.AddErrorContext(errors.InvalidParams.New(), "body", err.Error())
+}
+
+// bindClientInfo fill client info of the request
+func bindClientInfo(c *gin.Context, info *schema.ClientInfo) {
+	info.UserAgent = c.Request.UserAgent()
+	info.IP = c.ClientIP()
+}
+
+// sessionCookieOptions attributes of session cookies from config
+func sessionCookieOptions() app.CookieOptions {
+	cfg := config.Config.SessionCookie
+	return app.CookieOptions{
+		Domain:   cfg.Domain,
+		Path:     cfg.Path,
+		Secure:   cfg.Secure,
+		SameSite: app.ParseSameSite(cfg.SameSite),
+	}
+}
+
+// writeSessionCookies in cookie mode, move tokens of the response into HttpOnly cookies
+// and issue a new csrf token. The returned token info no longer carries the tokens
+func writeSessionCookies(c *gin.Context, tokenInfo *schema.UserTokenInfo) (*schema.UserTokenInfo, error) {
+	if !config.Config.SessionCookie.Enable || tokenInfo == nil || tokenInfo.AccessToken == "" {
+		return tokenInfo, nil
+	}
+
+	csrfToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := config.Config.JWTAuth
+	accessMaxAge, refreshMaxAge := defaultAccessExpired, defaultRefreshExpired*3600
+	if conf.Expired > 0 {
+		accessMaxAge = conf.Expired
+	}
+	if conf.ExpiredRefreshToken > 0 {
+		refreshMaxAge = conf.ExpiredRefreshToken * 3600
+	}
+
+	opts := sessionCookieOptions()
+	app.SetCookie(c, opts, app.AccessTokenCookie, tokenInfo.AccessToken, accessMaxAge, true)
+	app.SetCookie(c, opts, app.RefreshTokenCookie, tokenInfo.RefreshToken, refreshMaxAge, true)
+	app.SetCookie(c, opts, app.CSRFTokenCookie, csrfToken, refreshMaxAge, false)
+
+	result := *tokenInfo
+	result.AccessToken = ""
+	result.RefreshToken = ""
+	return &result, nil
+}
+
+// clearSessionCookies in cookie mode, remove session cookies from the browser
+func clearSessionCookies(c *gin.Context) {
+	if !config.Config.SessionCookie.Enable {
+		return
+	}
+
+	opts := sessionCookieOptions()
+	app.ClearCookie(c, opts, app.AccessTokenCookie, true)
+	app.ClearCookie(c, opts, app.RefreshTokenCookie, true)
+	app.ClearCookie(c, opts, app.CSRFTokenCookie, false)
+}
+
 // Login godoc
 // @Tags Auth
 // @Summary api login
-// @Description api login
+// @Description api login, in cookie session mode the tokens are set as HttpOnly cookies
 // @Accept json
 // @Produce json
 // @Param body body schema.LoginBodyParams true "Body"
@@ -37,6 +114,7 @@ func (a *AuthAPI) Login(c *gin.Context) gohttp.Response {
 			Error: errors.InvalidParams.New(),
 		}
 	}
+	bindClientInfo(c, &params.ClientInfo)
 
 	validator := validation.New()
 	if err := validator.ValidateStruct(params); err != nil {
@@ -53,6 +131,62 @@ func (a *AuthAPI) Login(c *gin.Context) gohttp.Response {
 		}
 	}
 
+	tokenInfo, err = writeSessionCookies(c, tokenInfo)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  tokenInfo,
+	}
+}
+
+// LoginMFA godoc
+// @Tags Auth
+// @Summary api finish login with two-factor authentication
+// @Description api finish login with the mfa pending token and a totp code or a recovery code
+// @Accept json
+// @Produce json
+// @Param body body schema.LoginMFABodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /login/mfa [post]
+func (a *AuthAPI) LoginMFA(c *gin.Context) gohttp.Response {
+	var params schema.LoginMFABodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+	bindClientInfo(c, &params.ClientInfo)
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	tokenInfo, err := a.service.LoginMFA(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	tokenInfo, err = writeSessionCookies(c, tokenInfo)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
 	return gohttp.Response{
 		Error: errors.Success.New(),
 		Data:  tokenInfo,
@@ -76,11 +210,12 @@ func (a *AuthAPI) Register(c *gin.Context) gohttp.Response {
 			Error: errors.InvalidParams.New(),
 		}
 	}
+	bindClientInfo(c, &params.ClientInfo)
 
 	validator := validation.New()
 	if err := validator.ValidateStruct(params); err != nil {
 		return gohttp.Response{
-			Error: errors.InvalidParams.New(),
+			Error: invalidParams(err),
 		}
 	}
 
@@ -92,6 +227,14 @@ func (a *AuthAPI) Register(c *gin.Context) gohttp.Response {
 			Error: err,
 		}
 	}
+
+	tokenInfo, err = writeSessionCookies(c, tokenInfo)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
 	return gohttp.Response{
 		Error: errors.Success.New(),
 		Data:  tokenInfo,
@@ -101,7 +244,7 @@ func (a *AuthAPI) Register(c *gin.Context) gohttp.Response {
 // Refresh godoc
 // @Tags Auth
 // @Summary api refresh token
-// @Description api refresh token
+// @Description api refresh token, in cookie session mode the refresh token cookie is used when the body has none
 // @Accept  json
 // @Produce json
 // @Param body body schema.RefreshBodyParams true "Body"
@@ -109,12 +252,17 @@ func (a *AuthAPI) Register(c *gin.Context) gohttp.Response {
 // @Router /refresh [post]
 func (a *AuthAPI) Refresh(c *gin.Context) gohttp.Response {
 	var params schema.RefreshBodyParams
-	if err := c.ShouldBindJSON(&params); err != nil {
+	// browser sessions may send no body, the refresh token comes from the cookie
+	if err := c.ShouldBindJSON(&params); err != nil && err != io.EOF {
 		logger.Error(err.Error())
 		return gohttp.Response{
 			Error: errors.InvalidParams.New(),
 		}
 	}
+	if params.RefreshToken == "" && config.Config.SessionCookie.Enable {
+		params.RefreshToken, _ = c.Cookie(app.RefreshTokenCookie)
+	}
+	bindClientInfo(c, &params.ClientInfo)
 
 	validator := validation.New()
 	if err := validator.ValidateStruct(params); err != nil {
@@ -132,6 +280,14 @@ func (a *AuthAPI) Refresh(c *gin.Context) gohttp.Response {
 		}
 	}
 
+	tokenInfo, err = writeSessionCookies(c, tokenInfo)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
 	return gohttp.Response{
 		Error: errors.Success.New(),
 		Data:  tokenInfo,
@@ -141,13 +297,16 @@ func (a *AuthAPI) Refresh(c *gin.Context) gohttp.Response {
 // Logout godoc
 // @Tags Auth
 // @Summary api logout
-// @Description api logout
+// @Description api logout, clears the session cookies in cookie session mode
 // @Accept  json
 // @Produce json
 // @Security ApiKeyAuth
 // @Success 200 {object} schema.BaseResponse
 // @Router /logout [post]
 func (a *AuthAPI) Logout(c *gin.Context) gohttp.Response {
+	// the browser is signed out even if the session cannot be revoked
+	clearSessionCookies(c)
+
 	err := a.service.Logout(c)
 	if err != nil {
 		logger.Error(err.Error())
@@ -160,3 +319,248 @@ func (a *AuthAPI) Logout(c *gin.Context) gohttp.Response {
 		Error: errors.Success.New(),
 	}
 }
+
+// ChangePassword godoc
+// @Tags Auth
+// @Summary api change password
+// @Description api change password, all sessions of the user are signed out
+// @Accept  json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body schema.ChangePasswordBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /password/change [post]
+func (a *AuthAPI) ChangePassword(c *gin.Context) gohttp.Response {
+	var params schema.ChangePasswordBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: invalidParams(err),
+		}
+	}
+
+	err := a.service.ChangePassword(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
+
+// ForgotPassword godoc
+// @Tags Auth
+// @Summary api forgot password
+// @Description api send password reset link, the response does not tell whether the email belongs to an account
+// @Accept  json
+// @Produce json
+// @Param body body schema.ForgotPasswordBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /password/forgot [post]
+func (a *AuthAPI) ForgotPassword(c *gin.Context) gohttp.Response {
+	var params schema.ForgotPasswordBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	err := a.service.ForgotPassword(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
+
+// ResetPassword godoc
+// @Tags Auth
+// @Summary api reset password
+// @Description api set new password by password reset token, all sessions of the user are signed out
+// @Accept  json
+// @Produce json
+// @Param body body schema.ResetPasswordBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /password/reset [post]
+func (a *AuthAPI) ResetPassword(c *gin.Context) gohttp.Response {
+	var params schema.ResetPasswordBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: invalidParams(err),
+		}
+	}
+
+	err := a.service.ResetPassword(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
+
+// VerifyEmail godoc
+// @Tags Auth
+// @Summary api verify email
+// @Description api mark email address as verified by email verification token
+// @Accept  json
+// @Produce json
+// @Param body body schema.VerifyEmailBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /email/verify [post]
+func (a *AuthAPI) VerifyEmail(c *gin.Context) gohttp.Response {
+	var params schema.VerifyEmailBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	err := a.service.VerifyEmail(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
+
+// SendEmailVerification godoc
+// @Tags Auth
+// @Summary api resend email verification
+// @Description api send new verification link to current user
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.BaseResponse
+// @Router /email/verify/resend [post]
+func (a *AuthAPI) SendEmailVerification(c *gin.Context) gohttp.Response {
+	err := a.service.SendEmailVerification(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
+
+// SignOutUser godoc
+// @Tags Auth
+// @Summary api force sign out user
+// @Description api force sign out all sessions of user
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/signout [post]
+func (a *AuthAPI) SignOutUser(c *gin.Context) gohttp.Response {
+	err := a.service.SignOutUser(c, c.Param("id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
+
+// UnlockUser godoc
+// @Tags Auth
+// @Summary api unlock user
+// @Description api clear failed logins and lockout of user
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/unlock [post]
+func (a *AuthAPI) UnlockUser(c *gin.Context) gohttp.Response {
+	err := a.service.UnlockUser(c, c.Param("id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
+
+// Impersonate godoc
+// @Tags Auth
+// @Summary api impersonate user
+// @Description api issue short-lived access token to act as the user, admins cannot be impersonated
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} schema.ImpersonationTokenInfo
+// @Router /admin/users/{id}/impersonate [post]
+func (a *AuthAPI) Impersonate(c *gin.Context) gohttp.Response {
+	tokenInfo, err := a.service.Impersonate(c, c.Param("id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  tokenInfo,
+	}
+}
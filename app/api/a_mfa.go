@@ -0,0 +1,146 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/errors"
+	gohttp "github.com/shasw94/projX/pkg/http/wrapper"
+	"github.com/shasw94/projX/validation"
+)
+
+// MFAAPI handle two-factor authentication api
+type MFAAPI struct {
+	service interfaces.IMFAService
+}
+
+// NewMFAAPI return new MFAAPI pointer
+func NewMFAAPI(service interfaces.IMFAService) *MFAAPI {
+	return &MFAAPI{service: service}
+}
+
+// bindMFACode bind and validate totp code of the request
+func bindMFACode(c *gin.Context) (*schema.MFACodeBodyParams, error) {
+	var params schema.MFACodeBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return nil, errors.InvalidParams.New()
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return nil, errors.InvalidParams.New()
+	}
+	return &params, nil
+}
+
+// Enroll godoc
+// @Tags MFA
+// @Summary api enroll totp of current user
+// @Description api generate totp secret and provisioning uri, confirm it with a first code
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.BaseResponse
+// @Router /api/v1/me/mfa/totp [post]
+func (m *MFAAPI) Enroll(c *gin.Context) gohttp.Response {
+	enrollment, err := m.service.Enroll(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  enrollment,
+	}
+}
+
+// Confirm godoc
+// @Tags MFA
+// @Summary api confirm totp of current user
+// @Description api enable totp by its first code, recovery codes are only returned once
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body schema.MFACodeBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /api/v1/me/mfa/totp/confirm [post]
+func (m *MFAAPI) Confirm(c *gin.Context) gohttp.Response {
+	params, err := bindMFACode(c)
+	if err != nil {
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	codes, err := m.service.Confirm(c, params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  codes,
+	}
+}
+
+// RegenerateRecoveryCodes godoc
+// @Tags MFA
+// @Summary api regenerate recovery codes of current user
+// @Description api replace recovery codes, requires a valid totp code
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body schema.MFACodeBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /api/v1/me/mfa/recovery-codes [post]
+func (m *MFAAPI) RegenerateRecoveryCodes(c *gin.Context) gohttp.Response {
+	params, err := bindMFACode(c)
+	if err != nil {
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	codes, err := m.service.RegenerateRecoveryCodes(c, params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  codes,
+	}
+}
+
+// Reset godoc
+// @Tags MFA
+// @Summary api reset two-factor authentication of user
+// @Description api remove totp and recovery codes of user
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/mfa [delete]
+func (m *MFAAPI) Reset(c *gin.Context) gohttp.Response {
+	err := m.service.Reset(c, c.Param("id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
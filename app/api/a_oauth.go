@@ -0,0 +1,278 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/errors"
+	gohttp "github.com/shasw94/projX/pkg/http/wrapper"
+	"github.com/shasw94/projX/validation"
+	"net/http"
+	"net/url"
+)
+
+// oauthErrorCodes error codes of token endpoint as in RFC 6749
+var oauthErrorCodes = map[errors.ErrorType]string{
+	errors.InvalidParams:             "invalid_request",
+	errors.ErrorInvalidClient:        "invalid_client",
+	errors.ErrorInvalidGrant:         "invalid_grant",
+	errors.ErrorInvalidScope:         "invalid_scope",
+	errors.ErrorUnauthorizedClient:   "unauthorized_client",
+	errors.ErrorUnsupportedGrantType: "unsupported_grant_type",
+}
+
+// OAuthAPI handle oauth2 api
+type OAuthAPI struct {
+	service interfaces.IOAuthService
+}
+
+// NewOAuthAPI return new OAuthAPI pointer
+func NewOAuthAPI(service interfaces.IOAuthService) *OAuthAPI {
+	return &OAuthAPI{service: service}
+}
+
+// oauthClientSchema convert oauth client model to schema
+func oauthClientSchema(client *models.OAuthClient) schema.OAuthClient {
+	return schema.OAuthClient{
+		ID:           client.ID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIList(),
+		Scopes:       client.ScopeList(),
+		GrantTypes:   client.GrantTypeList(),
+		Public:       client.Public,
+		CreatedAt:    client.CreatedAt,
+		RevokedAt:    client.RevokedAt,
+	}
+}
+
+// Token godoc
+// @Tags OAuth
+// @Summary api oauth2 token endpoint
+// @Description api issue access token for client_credentials and authorization_code grants.
+// @Description Client authenticates by HTTP basic auth or client_id and client_secret form fields
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "client_credentials or authorization_code"
+// @Param client_id formData string false "Client ID"
+// @Param client_secret formData string false "Client secret"
+// @Param scope formData string false "Space separated scopes"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI of the authorization request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Success 200 {object} schema.OAuthTokenResponse
+// @Failure 400 {object} schema.OAuthErrorResponse
+// @Failure 401 {object} schema.OAuthErrorResponse
+// @Router /oauth/token [post]
+func (o *OAuthAPI) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var params schema.OAuthTokenParams
+	if err := c.ShouldBind(&params); err != nil {
+		o.tokenError(c, errors.InvalidParams.Newm(err.Error()))
+		return
+	}
+
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		params.ClientID, _ = url.QueryUnescape(id)
+		params.ClientSecret, _ = url.QueryUnescape(secret)
+	}
+
+	token, err := o.service.Token(c, &params)
+	if err != nil {
+		o.tokenError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// tokenError write error response of token endpoint
+func (o *OAuthAPI) tokenError(c *gin.Context, err error) {
+	code, ok := oauthErrorCodes[errors.GetType(err)]
+	if !ok {
+		logger.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, schema.OAuthErrorResponse{Error: "server_error"})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if errors.GetType(err) == errors.ErrorInvalidClient {
+		status = http.StatusUnauthorized
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	c.JSON(status, schema.OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: err.Error(),
+	})
+}
+
+// Authorize godoc
+// @Tags OAuth
+// @Summary api approve authorization request of client
+// @Description api issue authorization code to client on behalf of current user, PKCE with S256 is required
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body schema.OAuthAuthorizeBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /oauth/authorize [post]
+func (o *OAuthAPI) Authorize(c *gin.Context) gohttp.Response {
+	var params schema.OAuthAuthorizeBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: errors.InvalidParams.Newm(err.Error()),
+		}
+	}
+
+	result, err := o.service.Authorize(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  result,
+	}
+}
+
+// CreateClient godoc
+// @Tags OAuth
+// @Summary api register oauth client
+// @Description api register oauth client, the client secret is only returned once
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body schema.OAuthClientBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/oauth/clients [post]
+func (o *OAuthAPI) CreateClient(c *gin.Context) gohttp.Response {
+	var params schema.OAuthClientBodyParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return gohttp.Response{
+			Error: errors.InvalidParams.Newm(err.Error()),
+		}
+	}
+
+	client, secret, err := o.service.CreateClient(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data: schema.OAuthClientCredentials{
+			OAuthClient:  oauthClientSchema(client),
+			ClientSecret: secret,
+		},
+	}
+}
+
+// ListClients godoc
+// @Tags OAuth
+// @Summary api list oauth clients
+// @Description api list oauth clients
+// @Produce json
+// @Security ApiKeyAuth
+// @Param offset query int false "Offset"
+// @Param limit query int false "Limit"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/oauth/clients [get]
+func (o *OAuthAPI) ListClients(c *gin.Context) gohttp.Response {
+	var params schema.OAuthClientQueryParam
+	if err := c.ShouldBindQuery(&params); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: errors.InvalidParams.New(),
+		}
+	}
+
+	clients, err := o.service.ListClients(c, &params)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	res := make([]schema.OAuthClient, 0, len(*clients))
+	for i := range *clients {
+		res = append(res, oauthClientSchema(&(*clients)[i]))
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  res,
+	}
+}
+
+// GetClient godoc
+// @Tags OAuth
+// @Summary api get oauth client
+// @Description api get oauth client
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Client ID"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/oauth/clients/{id} [get]
+func (o *OAuthAPI) GetClient(c *gin.Context) gohttp.Response {
+	client, err := o.service.GetClient(c, c.Param("id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  oauthClientSchema(client),
+	}
+}
+
+// RevokeClient godoc
+// @Tags OAuth
+// @Summary api revoke oauth client
+// @Description api revoke oauth client and its client credentials tokens
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Client ID"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/oauth/clients/{id} [delete]
+func (o *OAuthAPI) RevokeClient(c *gin.Context) gohttp.Response {
+	err := o.service.RevokeClient(c, c.Param("id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
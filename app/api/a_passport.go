@@ -0,0 +1,578 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+	gohttp "github.com/shasw94/projX/pkg/http/wrapper"
+	"github.com/shasw94/projX/pkg/utils"
+	"github.com/shasw94/projX/validation"
+)
+
+// PassportAPI handle admin api of roles and permissions
+type PassportAPI struct {
+	passport interfaces.IPassport
+}
+
+// NewPassportAPI return new PassportAPI pointer
+func NewPassportAPI(passport interfaces.IPassport) *PassportAPI {
+	return &PassportAPI{passport: passport}
+}
+
+// bindPassportQuery bind paging query of listings, the limit is capped by max_limit
+func bindPassportQuery(c *gin.Context) (*schema.PassportQueryParam, *utils.Pagination, error) {
+	var query schema.PassportQueryParam
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.Error(err.Error())
+		return nil, nil, errors.InvalidParams.New()
+	}
+
+	pagination := &utils.Pagination{Page: query.Page, Limit: query.Limit}
+	if maxLimit := config.Config.MaxLimit; maxLimit > 0 && pagination.GetLimit() > maxLimit {
+		pagination.Limit = maxLimit
+	}
+	return &query, pagination, nil
+}
+
+// bindValidJSON bind json body and validate it
+func bindValidJSON(c *gin.Context, params interface{}) error {
+	if err := c.ShouldBindJSON(params); err != nil {
+		logger.Error(err.Error())
+		return errors.InvalidParams.New()
+	}
+
+	validator := validation.New()
+	if err := validator.ValidateStruct(params); err != nil {
+		return invalidParams(err)
+	}
+	return nil
+}
+
+// passportResponse response of passport call, data is returned on success
+func passportResponse(data interface{}, err error) gohttp.Response {
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  data,
+	}
+}
+
+// ListRoles godoc
+// @Tags Passport
+// @Summary api list roles
+// @Description api list roles, paginated
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Param with_permissions query bool false "Include permissions of the roles"
+// @Success 200 {object} schema.RoleList
+// @Router /admin/roles [get]
+func (p *PassportAPI) ListRoles(c *gin.Context) gohttp.Response {
+	query, pagination, err := bindPassportQuery(c)
+	if err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	roles, total, err := p.passport.GetAllRoles(&schema.RoleOption{
+		WithPermissions: query.WithPermissions,
+		Pagination:      pagination,
+	})
+	if err != nil {
+		return passportResponse(nil, err)
+	}
+	return passportResponse(schema.RoleList{Roles: roles.Origin(), PageInfo: schema.NewPageInfo(pagination, total)}, nil)
+}
+
+// CreateRole godoc
+// @Tags Passport
+// @Summary api create role
+// @Description api create role, an existing role of the same name is returned
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body schema.RoleBodyParams true "Body"
+// @Success 200 {object} models.Role
+// @Router /admin/roles [post]
+func (p *PassportAPI) CreateRole(c *gin.Context) gohttp.Response {
+	var params schema.RoleBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(p.passport.CreateRole(params.Name, params.Description))
+}
+
+// GetRole godoc
+// @Tags Passport
+// @Summary api get role
+// @Description api get role with its permissions
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Success 200 {object} models.Role
+// @Router /admin/roles/{id} [get]
+func (p *PassportAPI) GetRole(c *gin.Context) gohttp.Response {
+	return passportResponse(p.passport.GetRole(c.Param("id"), true))
+}
+
+// UpdateRole godoc
+// @Tags Passport
+// @Summary api update role
+// @Description api rename role or change its description
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Param body body schema.RoleUpdateBodyParams true "Body"
+// @Success 200 {object} models.Role
+// @Router /admin/roles/{id} [put]
+func (p *PassportAPI) UpdateRole(c *gin.Context) gohttp.Response {
+	var params schema.RoleUpdateBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(p.passport.UpdateRole(c.Param("id"), params.Name, params.Description))
+}
+
+// DeleteRole godoc
+// @Tags Passport
+// @Summary api delete role
+// @Description api delete role and its assignments, the admin role cannot be deleted
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/roles/{id} [delete]
+func (p *PassportAPI) DeleteRole(c *gin.Context) gohttp.Response {
+	return passportResponse(nil, p.passport.DeleteRole(c.Param("id")))
+}
+
+// ListRolePermissions godoc
+// @Tags Passport
+// @Summary api list permissions of role
+// @Description api list permissions of role, paginated
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} schema.PermissionList
+// @Router /admin/roles/{id}/permissions [get]
+func (p *PassportAPI) ListRolePermissions(c *gin.Context) gohttp.Response {
+	_, pagination, err := bindPassportQuery(c)
+	if err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	permissions, total, err := p.passport.GetPermissionsOfRoles(c.Param("id"), &schema.PermissionOption{Pagination: pagination})
+	if err != nil {
+		return passportResponse(nil, err)
+	}
+	return passportResponse(schema.PermissionList{Permissions: permissions.Origin(), PageInfo: schema.NewPageInfo(pagination, total)}, nil)
+}
+
+// AttachRolePermissions godoc
+// @Tags Passport
+// @Summary api attach permissions to role
+// @Description api attach permissions to role by names or ids
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Param body body schema.PermissionsBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/roles/{id}/permissions [post]
+func (p *PassportAPI) AttachRolePermissions(c *gin.Context) gohttp.Response {
+	var params schema.PermissionsBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(nil, p.passport.AddPermissionsToRole(c.Param("id"), params.Permissions))
+}
+
+// ReplaceRolePermissions godoc
+// @Tags Passport
+// @Summary api replace permissions of role
+// @Description api replace permissions of role, an empty list detaches all of them
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Param body body schema.PermissionsBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/roles/{id}/permissions [put]
+func (p *PassportAPI) ReplaceRolePermissions(c *gin.Context) gohttp.Response {
+	var params schema.PermissionsBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(nil, p.passport.ReplacePermissionsToRole(c.Param("id"), params.Permissions))
+}
+
+// DetachRolePermission godoc
+// @Tags Passport
+// @Summary api detach permission from role
+// @Description api detach permission from role
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Param permission_id path string true "Permission ID or name"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/roles/{id}/permissions/{permission_id} [delete]
+func (p *PassportAPI) DetachRolePermission(c *gin.Context) gohttp.Response {
+	return passportResponse(nil, p.passport.RemovePermissionsFromRole(c.Param("id"), c.Param("permission_id")))
+}
+
+// GetRoleEffectivePermissions godoc
+// @Tags Passport
+// @Summary api effective permissions of role
+// @Description api permissions of role resolved through the role hierarchy with the roles granting them
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Success 200 {object} schema.EffectivePermissionList
+// @Router /admin/roles/{id}/effective-permissions [get]
+func (p *PassportAPI) GetRoleEffectivePermissions(c *gin.Context) gohttp.Response {
+	return passportResponse(p.passport.GetEffectivePermissionsOfRole(c.Param("id")))
+}
+
+// ListRoleParents godoc
+// @Tags Passport
+// @Summary api list parents of role
+// @Description api list roles whose permissions are inherited by the role
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Success 200 {array} models.Role
+// @Router /admin/roles/{id}/parents [get]
+func (p *PassportAPI) ListRoleParents(c *gin.Context) gohttp.Response {
+	return passportResponse(p.passport.GetParentsOfRole(c.Param("id")))
+}
+
+// AttachRoleParents godoc
+// @Tags Passport
+// @Summary api attach parents to role
+// @Description api make role inherit permissions of the parents by names or ids, cycles are rejected
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Param body body schema.RolesBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/roles/{id}/parents [post]
+func (p *PassportAPI) AttachRoleParents(c *gin.Context) gohttp.Response {
+	var params schema.RolesBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(nil, p.passport.AddParentsToRole(c.Param("id"), params.Roles))
+}
+
+// DetachRoleParent godoc
+// @Tags Passport
+// @Summary api detach parent from role
+// @Description api stop role inheriting permissions of the parent
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Role ID or name"
+// @Param parent_id path string true "Parent role ID or name"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/roles/{id}/parents/{parent_id} [delete]
+func (p *PassportAPI) DetachRoleParent(c *gin.Context) gohttp.Response {
+	return passportResponse(nil, p.passport.RemoveParentsFromRole(c.Param("id"), c.Param("parent_id")))
+}
+
+// ListPermissions godoc
+// @Tags Passport
+// @Summary api list permissions
+// @Description api list permissions, paginated
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} schema.PermissionList
+// @Router /admin/permissions [get]
+func (p *PassportAPI) ListPermissions(c *gin.Context) gohttp.Response {
+	_, pagination, err := bindPassportQuery(c)
+	if err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	permissions, total, err := p.passport.GetAllPermissions(&schema.PermissionOption{Pagination: pagination})
+	if err != nil {
+		return passportResponse(nil, err)
+	}
+	return passportResponse(schema.PermissionList{Permissions: permissions.Origin(), PageInfo: schema.NewPageInfo(pagination, total)}, nil)
+}
+
+// CreatePermission godoc
+// @Tags Passport
+// @Summary api create permission
+// @Description api create permission, an existing permission of the same name is returned
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param body body schema.PermissionBodyParams true "Body"
+// @Success 200 {object} models.Permission
+// @Router /admin/permissions [post]
+func (p *PassportAPI) CreatePermission(c *gin.Context) gohttp.Response {
+	var params schema.PermissionBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	permission, err := p.passport.CreatePermission(params.Name, params.Description)
+	if err != nil || params.Resource == "" {
+		return passportResponse(permission, err)
+	}
+	return passportResponse(p.passport.SetPermissionResource(permission.ID, params.Resource, params.Action))
+}
+
+// GetPermission godoc
+// @Tags Passport
+// @Summary api get permission
+// @Description api get permission
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Permission ID or name"
+// @Success 200 {object} models.Permission
+// @Router /admin/permissions/{id} [get]
+func (p *PassportAPI) GetPermission(c *gin.Context) gohttp.Response {
+	return passportResponse(p.passport.GetPermission(c.Param("id")))
+}
+
+// UpdatePermission godoc
+// @Tags Passport
+// @Summary api update permission
+// @Description api rename permission, change its description or the resource it grants, the resource is kept when omitted
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Permission ID or name"
+// @Param body body schema.PermissionUpdateBodyParams true "Body"
+// @Success 200 {object} models.Permission
+// @Router /admin/permissions/{id} [put]
+func (p *PassportAPI) UpdatePermission(c *gin.Context) gohttp.Response {
+	var params schema.PermissionUpdateBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	permission, err := p.passport.UpdatePermission(c.Param("id"), params.Name, params.Description)
+	if err != nil || params.Resource == nil {
+		return passportResponse(permission, err)
+	}
+	return passportResponse(p.passport.SetPermissionResource(permission.ID, *params.Resource, params.Action))
+}
+
+// DeletePermission godoc
+// @Tags Passport
+// @Summary api delete permission
+// @Description api delete permission and its assignments to roles and users
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Permission ID or name"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/permissions/{id} [delete]
+func (p *PassportAPI) DeletePermission(c *gin.Context) gohttp.Response {
+	return passportResponse(nil, p.passport.DeletePermission(c.Param("id")))
+}
+
+// ListUserRoles godoc
+// @Tags Passport
+// @Summary api list roles of user
+// @Description api list roles assigned to user, paginated
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Param with_permissions query bool false "Include permissions of the roles"
+// @Success 200 {object} schema.RoleList
+// @Router /admin/users/{id}/roles [get]
+func (p *PassportAPI) ListUserRoles(c *gin.Context) gohttp.Response {
+	query, pagination, err := bindPassportQuery(c)
+	if err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	roles, total, err := p.passport.GetRolesOfUser(c.Param("id"), &schema.RoleOption{
+		WithPermissions: query.WithPermissions,
+		Pagination:      pagination,
+	})
+	if err != nil {
+		return passportResponse(nil, err)
+	}
+	return passportResponse(schema.RoleList{Roles: roles.Origin(), PageInfo: schema.NewPageInfo(pagination, total)}, nil)
+}
+
+// AssignUserRoles godoc
+// @Tags Passport
+// @Summary api assign roles to user
+// @Description api assign roles to user by names or ids, starts_at and expires_at bound the assignment in time
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param body body schema.UserRolesBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/roles [post]
+func (p *PassportAPI) AssignUserRoles(c *gin.Context) gohttp.Response {
+	var params schema.UserRolesBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(nil, p.passport.AddRolesToUser(c.Param("id"), params.Roles, params.Grant(app.GetUserID(c))))
+}
+
+// ReplaceUserRoles godoc
+// @Tags Passport
+// @Summary api replace roles of user
+// @Description api replace roles of user, an empty list removes all of them
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param body body schema.RolesBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/roles [put]
+func (p *PassportAPI) ReplaceUserRoles(c *gin.Context) gohttp.Response {
+	var params schema.RolesBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(nil, p.passport.ReplaceRolesToUser(c.Param("id"), params.Roles))
+}
+
+// RemoveUserRole godoc
+// @Tags Passport
+// @Summary api remove role from user
+// @Description api remove role from user
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param role_id path string true "Role ID or name"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/roles/{role_id} [delete]
+func (p *PassportAPI) RemoveUserRole(c *gin.Context) gohttp.Response {
+	return passportResponse(nil, p.passport.RemoveRolesFromUser(c.Param("id"), c.Param("role_id")))
+}
+
+// ListUserPermissions godoc
+// @Tags Passport
+// @Summary api list direct permissions of user
+// @Description api list permissions granted to user directly, paginated
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Limit"
+// @Success 200 {object} schema.PermissionList
+// @Router /admin/users/{id}/permissions [get]
+func (p *PassportAPI) ListUserPermissions(c *gin.Context) gohttp.Response {
+	_, pagination, err := bindPassportQuery(c)
+	if err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	permissions, total, err := p.passport.GetDirectPermissionsOfUser(c.Param("id"), &schema.PermissionOption{Pagination: pagination})
+	if err != nil {
+		return passportResponse(nil, err)
+	}
+	return passportResponse(schema.PermissionList{Permissions: permissions.Origin(), PageInfo: schema.NewPageInfo(pagination, total)}, nil)
+}
+
+// GrantUserPermissions godoc
+// @Tags Passport
+// @Summary api grant permissions to user
+// @Description api grant permissions to user directly by names or ids, starts_at and expires_at bound the grant in time
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param body body schema.UserPermissionsBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/permissions [post]
+func (p *PassportAPI) GrantUserPermissions(c *gin.Context) gohttp.Response {
+	var params schema.UserPermissionsBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(nil, p.passport.AddPermissionsToUser(c.Param("id"), params.Permissions, params.Grant(app.GetUserID(c))))
+}
+
+// ReplaceUserPermissions godoc
+// @Tags Passport
+// @Summary api replace direct permissions of user
+// @Description api replace direct permissions of user, an empty list revokes all of them
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param body body schema.PermissionsBodyParams true "Body"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/permissions [put]
+func (p *PassportAPI) ReplaceUserPermissions(c *gin.Context) gohttp.Response {
+	var params schema.PermissionsBodyParams
+	if err := bindValidJSON(c, &params); err != nil {
+		return gohttp.Response{Error: err}
+	}
+
+	return passportResponse(nil, p.passport.ReplacePermissionsToUser(c.Param("id"), params.Permissions))
+}
+
+// RevokeUserPermission godoc
+// @Tags Passport
+// @Summary api revoke direct permission of user
+// @Description api revoke permission granted to user directly
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "User ID"
+// @Param permission_id path string true "Permission ID or name"
+// @Success 200 {object} schema.BaseResponse
+// @Router /admin/users/{id}/permissions/{permission_id} [delete]
+func (p *PassportAPI) RevokeUserPermission(c *gin.Context) gohttp.Response {
+	return passportResponse(nil, p.passport.RemovePermissionsFromUser(c.Param("id"), c.Param("permission_id")))
+}
+
+// ExplainAuthz godoc
+// @Tags Passport
+// @Summary api explain permission check of user
+// @Description api report whether the user has the permission, the direct, role, inherited role or wildcard grants giving it and inactive assignments which would give it
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user query string true "User ID"
+// @Param permission query string true "Permission name or ID"
+// @Success 200 {object} schema.AuthzExplanation
+// @Router /admin/authz/explain [get]
+func (p *PassportAPI) ExplainAuthz(c *gin.Context) gohttp.Response {
+	var query schema.AuthzExplainQueryParam
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{Error: errors.InvalidParams.New()}
+	}
+	validator := validation.New()
+	if err := validator.ValidateStruct(query); err != nil {
+		return gohttp.Response{Error: invalidParams(err)}
+	}
+
+	return passportResponse(p.passport.ExplainUserPermission(query.User, query.Permission))
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+	gohttp "github.com/shasw94/projX/pkg/http/wrapper"
+)
+
+// SessionAPI handle session api of current user
+type SessionAPI struct {
+	service interfaces.ISessionService
+}
+
+// NewSessionAPI return new SessionAPI pointer
+func NewSessionAPI(service interfaces.ISessionService) *SessionAPI {
+	return &SessionAPI{service: service}
+}
+
+// List godoc
+// @Tags Session
+// @Summary api list sessions of current user
+// @Description api list sessions of current user
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.BaseResponse
+// @Router /api/v1/me/sessions [get]
+func (s *SessionAPI) List(c *gin.Context) gohttp.Response {
+	sessions, err := s.service.List(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	current := app.GetSessionID(c)
+	res := make([]schema.Session, 0, len(*sessions))
+	for _, session := range *sessions {
+		res = append(res, schema.Session{
+			ID:          session.ID,
+			DeviceLabel: session.DeviceLabel,
+			UserAgent:   session.UserAgent,
+			IP:          session.IP,
+			CreatedAt:   session.CreatedAt,
+			LastUsedAt:  session.LastUsedAt,
+			Current:     session.ID == current,
+		})
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+		Data:  res,
+	}
+}
+
+// Revoke godoc
+// @Tags Session
+// @Summary api revoke a session of current user
+// @Description api revoke a session of current user
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} schema.BaseResponse
+// @Router /api/v1/me/sessions/{id} [delete]
+func (s *SessionAPI) Revoke(c *gin.Context) gohttp.Response {
+	err := s.service.Revoke(c, c.Param("id"))
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
+
+// RevokeOthers godoc
+// @Tags Session
+// @Summary api revoke all sessions of current user but the current one
+// @Description api revoke all sessions of current user but the current one
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} schema.BaseResponse
+// @Router /api/v1/me/sessions [delete]
+func (s *SessionAPI) RevokeOthers(c *gin.Context) gohttp.Response {
+	err := s.service.RevokeOthers(c)
+	if err != nil {
+		logger.Error(err.Error())
+		return gohttp.Response{
+			Error: err,
+		}
+	}
+
+	return gohttp.Response{
+		Error: errors.Success.New(),
+	}
+}
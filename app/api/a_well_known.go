@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/pkg/jwt"
+	"net/http"
+)
+
+// WellKnownAPI handle /.well-known api
+type WellKnownAPI struct {
+	jwt jwt.IJWTAuth
+}
+
+// NewWellKnownAPI return new WellKnownAPI pointer
+func NewWellKnownAPI(jwt jwt.IJWTAuth) *WellKnownAPI {
+	return &WellKnownAPI{jwt: jwt}
+}
+
+// JWKS godoc
+// @Tags Auth
+// @Summary api public keys of access token
+// @Description api public keys of access token in JWKS format
+// @Produce json
+// @Success 200 {object} jwt.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (w *WellKnownAPI) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, w.jwt.JWKS())
+}
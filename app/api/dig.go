@@ -6,6 +6,11 @@ import "go.uber.org/dig"
 func Inject(container *dig.Container) error {
 	_ = container.Provide(NewAuthAPI)
 	_ = container.Provide(NewUserAPI)
-	_ = container.Provide(NewRoleAPI)
+	_ = container.Provide(NewPassportAPI)
+	_ = container.Provide(NewSessionAPI)
+	_ = container.Provide(NewWellKnownAPI)
+	_ = container.Provide(NewOAuthAPI)
+	_ = container.Provide(NewMFAAPI)
+	_ = container.Provide(NewAPIKeyAPI)
 	return nil
 }
@@ -5,10 +5,19 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/shasw94/projX/app/api"
 	"github.com/shasw94/projX/app/dbs"
+	"github.com/shasw94/projX/app/lockout"
+	"github.com/shasw94/projX/app/mailer"
+	"github.com/shasw94/projX/app/passport"
+	"github.com/shasw94/projX/app/permcache"
+	"github.com/shasw94/projX/app/policy"
+	"github.com/shasw94/projX/app/ratelimit"
+	"github.com/shasw94/projX/app/rbac"
 	"github.com/shasw94/projX/app/repositories"
+	"github.com/shasw94/projX/app/revocation"
 	"github.com/shasw94/projX/app/router"
 	"github.com/shasw94/projX/app/services"
 	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/hasher"
 	"github.com/shasw94/projX/pkg/jwt"
 	"go.uber.org/dig"
 	"time"
@@ -17,7 +26,12 @@ import (
 func BuildContainer() *dig.Container {
 	container := dig.New()
 
+	hasher.SetDefault(InitPasswordHasher())
+
 	auth, err := InitAuth()
+	if err != nil {
+		logger.Fatal("Failed to init auth: ", err)
+	}
 	_ = container.Provide(func() jwt.IJWTAuth {
 		return auth
 	})
@@ -33,6 +47,57 @@ func BuildContainer() *dig.Container {
 		logger.Error("Failed to inject repositories", err)
 	}
 
+	err = revocation.Inject(container)
+	if err != nil {
+		logger.Error("Failed to inject revocation store", err)
+	}
+
+	err = lockout.Inject(container)
+	if err != nil {
+		logger.Error("Failed to inject login lockout", err)
+	}
+
+	err = ratelimit.Inject(container)
+	if err != nil {
+		logger.Error("Failed to inject rate limiter", err)
+	}
+
+	err = permcache.Inject(container)
+	if err != nil {
+		logger.Error("Failed to inject permission cache", err)
+	}
+
+	err = passport.Inject(container)
+	if err != nil {
+		logger.Error("Failed to inject passport", err)
+	}
+
+	err = rbac.Inject(container)
+	if err != nil {
+		logger.Error("Failed to inject rbac syncer", err)
+	}
+
+	_ = container.Provide(InitCasbin)
+	err = policy.Inject(container)
+	if err != nil {
+		logger.Error("Failed to inject casbin policies", err)
+	}
+
+	err = container.Invoke(syncPolicies)
+	if err != nil {
+		logger.Error("Failed to init casbin", err)
+	}
+
+	err = container.Invoke(sweepGrants)
+	if err != nil {
+		logger.Error("Failed to start grant sweeper", err)
+	}
+
+	err = mailer.Inject(container)
+	if err != nil {
+		logger.Error("Failed to inject mailer", err)
+	}
+
 	err = services.Inject(container)
 	if err != nil {
 		logger.Error("Failed to inject services", err)
@@ -52,7 +117,7 @@ func InitGinEngine(container *dig.Container) *gin.Engine {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "OPTIONS", "HEAD"},
-		AllowHeaders:     []string{"Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Origin,Accept, X-Requested-With, Content-Type, Access-Control-Request-Method, Access-Control-Request-Headers, Authorization"},
+		AllowHeaders:     []string{"Access-Control-Allow-Headers", "Access-Control-Allow-Headers, Origin,Accept, X-Requested-With, Content-Type, Access-Control-Request-Method, Access-Control-Request-Headers, Authorization, X-CSRF-Token"},
 		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
 		AllowCredentials: true,
 		//AllowOriginFunc: func(origin string) bool {
@@ -5,22 +5,31 @@ import (
 	"github.com/shasw94/projX/config"
 	"github.com/shasw94/projX/pkg/errors"
 	jwtAuth "github.com/shasw94/projX/pkg/jwt"
+	"time"
 )
 
 func InitAuth() (jwtAuth.IJWTAuth, error) {
 	conf := config.Config.JWTAuth
 	var opts []jwtAuth.Option
 	// access token
-	opts = append(opts, jwtAuth.WithKeyFunc(func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.ErrTokenInvalid
+	if len(conf.Keys) > 0 {
+		keySet, err := initKeySet()
+		if err != nil {
+			return nil, err
 		}
-		return []byte(conf.SigningKey), nil
-	}))
+		opts = append(opts, jwtAuth.WithKeySet(keySet))
+	} else {
+		opts = append(opts, jwtAuth.WithKeyFunc(func(t *jwt.Token) (interface{}, error) {
+			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.ErrTokenInvalid
+			}
+			return []byte(conf.SigningKey), nil
+		}))
+		opts = append(opts, jwtAuth.WithSigningKey([]byte(conf.SigningKey)))
+	}
 	if conf.Expired != 0 {
 		opts = append(opts, jwtAuth.WithExpired(conf.Expired))
 	}
-	opts = append(opts, jwtAuth.WithSigningKey([]byte(conf.SigningKey)))
 
 	// refresh token
 	opts = append(opts, jwtAuth.WithKeyFuncRefresh(func(t *jwt.Token) (interface{}, error) {
@@ -36,3 +45,27 @@ func InitAuth() (jwtAuth.IJWTAuth, error) {
 	opts = append(opts, jwtAuth.WithSigningKeyRefresh([]byte(conf.SigningRefreshKey)))
 	return jwtAuth.NewJWTAuth(opts...), nil
 }
+
+// initKeySet load asymmetric keys of access token from PEM files
+func initKeySet() (*jwtAuth.KeySet, error) {
+	conf := config.Config.JWTAuth
+	var keys []*jwtAuth.SigningKey
+	for _, k := range conf.Keys {
+		var retireAt time.Time
+		if k.RetireAt != "" {
+			t, err := time.Parse(time.RFC3339, k.RetireAt)
+			if err != nil {
+				return nil, errors.Wrapf(err, "jwt key %s: invalid retire_at", k.KID)
+			}
+			retireAt = t
+		}
+
+		key, err := jwtAuth.LoadPEMKey(k.KID, k.Algorithm, k.PrivateKeyFile, k.PublicKeyFile, retireAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return jwtAuth.NewKeySet(keys, conf.SigningKID)
+}
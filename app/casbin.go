@@ -2,35 +2,57 @@ package app
 
 import (
 	"github.com/casbin/casbin/v2"
-	"github.com/casbin/casbin/v2/persist"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/policy"
 	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/logger"
 	"time"
 )
 
-func InitCasbin(adapter persist.Adapter) (*casbin.SyncedEnforcer, func(), error) {
+// InitCasbin build the casbin enforcer, policies are stored in the database when casbin is enabled.
+// The bundled rbac with paths model is used unless casbin.model points to a model file
+func InitCasbin(db interfaces.IDatabase) (*casbin.SyncedEnforcer, error) {
 	cfg := config.Config.Casbin
-	if cfg.Model == "" {
-		return new(casbin.SyncedEnforcer), nil, nil
+	m, err := policy.LoadModel(cfg.Model)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Enable {
+		e, err := casbin.NewSyncedEnforcer(m)
+		if err != nil {
+			return nil, err
+		}
+		e.EnableEnforce(false)
+		return e, nil
 	}
 
-	e, err := casbin.NewSyncedEnforcer(cfg.Model)
+	adapter, err := policy.NewAdapter(db)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	e.EnableLog(cfg.Debug)
-
-	err = e.InitWithModelAndAdapter(e.GetModel(), adapter)
+	e, err := casbin.NewSyncedEnforcer(m, adapter)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	e.EnableEnforce(cfg.Enable)
+	e.EnableLog(cfg.Debug)
 
-	cleanFunc := func() {}
 	if cfg.AutoLoad {
-		e.StartAutoLoadPolicy(time.Duration(cfg.AutoLoadInternal) * time.Second)
-		cleanFunc = func() {
-			e.StopAutoLoadPolicy()
-		}
+		e.StartAutoLoadPolicy(time.Duration(cfg.AutoLoadInterval) * time.Second)
+	}
+	return e, nil
+}
+
+// syncPolicies load Passport state into casbin and keep it in line with later changes
+func syncPolicies(passport interfaces.IPassport, syncer interfaces.IPolicySyncer) {
+	if !config.Config.Casbin.Enable {
+		return
+	}
+	if err := syncer.Sync(); err != nil {
+		logger.Warn("Failed to sync casbin policies: ", err)
 	}
-	return e, cleanFunc, nil
+	passport.OnChange(func() {
+		if err := syncer.Sync(); err != nil {
+			logger.Error("Failed to sync casbin policies: ", err)
+		}
+	})
 }
@@ -7,6 +7,7 @@ type (
 	noTransCtx   struct{}
 	transLockCtx struct{}
 	userIDCtx    struct{}
+	actorIDCtx   struct{}
 	userNameCtx  struct{}
 	traceIDCtx   struct{}
 )
@@ -53,6 +54,22 @@ func FromUserID(ctx context.Context) string {
 	return ""
 }
 
+// NewActorID wrap id of the user acting on behalf of the user of the context, e.g. an impersonating admin
+func NewActorID(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorIDCtx{}, actorID)
+}
+
+// FromActorID id of the acting user, empty unless the request is made by impersonation
+func FromActorID(ctx context.Context) string {
+	v := ctx.Value(actorIDCtx{})
+	if v != nil {
+		if s, ok := v.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
 func NewUserName(ctx context.Context, userName string) context.Context {
 	return context.WithValue(ctx, userNameCtx{}, userName)
 }
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/utils"
+	"time"
+)
+
+// DefaultGrantSweepInterval interval between sweeps of expired grants when grant_sweeper.interval is not set
+const DefaultGrantSweepInterval = time.Minute
+
+// sweepGrants delete expired role and permission assignments in the background
+func sweepGrants(passport interfaces.IPassport, auditRepo interfaces.IAuditRepository) {
+	cfg := config.Config.GrantSweeper
+	if !cfg.Enable {
+		return
+	}
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval <= 0 {
+		interval = DefaultGrantSweepInterval
+	}
+
+	go func() {
+		since := time.Now()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			expired, err := passport.SweepGrants(since, now)
+			if err != nil {
+				logger.Error("Failed to sweep expired grants: ", err)
+			}
+			if expired != nil {
+				auditExpiredGrants(auditRepo, expired)
+			}
+			since = now
+		}
+	}()
+}
+
+// auditExpiredGrants record audit event of every expired assignment, failures are only logged
+func auditExpiredGrants(auditRepo interfaces.IAuditRepository, expired *schema.ExpiredGrants) {
+	for _, grant := range expired.Roles {
+		audit(auditRepo, &models.AuditEvent{
+			Event:   models.AuditRoleExpired,
+			UserID:  grant.UserID,
+			ActorID: grant.GrantedBy,
+			Detail: utils.JSONMarshalToString(map[string]interface{}{
+				"role_id":    grant.RoleID,
+				"expires_at": grant.ExpiresAt,
+				"reason":     grant.Reason,
+			}),
+		})
+	}
+	for _, grant := range expired.Permissions {
+		audit(auditRepo, &models.AuditEvent{
+			Event:   models.AuditPermissionExpired,
+			UserID:  grant.UserID,
+			ActorID: grant.GrantedBy,
+			Detail: utils.JSONMarshalToString(map[string]interface{}{
+				"permission_id": grant.PermissionID,
+				"expires_at":    grant.ExpiresAt,
+				"reason":        grant.Reason,
+			}),
+		})
+	}
+}
+
+// audit record the audit event, failures are only logged
+func audit(auditRepo interfaces.IAuditRepository, event *models.AuditEvent) {
+	if err := auditRepo.Create(event); err != nil {
+		logger.Error("Failed to record audit event: ", err)
+	}
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"github.com/shasw94/projX/app/models"
+	"time"
+)
+
+// IAPIKeyRepository interface
+type IAPIKeyRepository interface {
+	Create(key *models.APIKey) error
+	GetByPrefix(prefix string) (*models.APIKey, error)
+	ListByUser(userID string) (*[]models.APIKey, error)
+	Revoke(userID string, id string) (bool, error)
+	Touch(id string, usedAt time.Time) error
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/pkg/jwt"
+)
+
+// IAPIKeyService interface
+type IAPIKeyService interface {
+	CreateServiceAccount(ctx context.Context, param *schema.ServiceAccountBodyParams) (*models.User, error)
+	CreateKey(ctx context.Context, userID string, param *schema.APIKeyBodyParams) (*models.APIKey, string, error)
+	ListKeys(ctx context.Context, userID string) (*[]models.APIKey, error)
+	RevokeKey(ctx context.Context, userID string, id string) error
+	Authenticate(key string) (*jwt.AccessClaims, error)
+}
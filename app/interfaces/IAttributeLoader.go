@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/schema"
+)
+
+// IAttributeLoader load attributes of resources of one type evaluated by authorization rules
+type IAttributeLoader interface {
+	LoadAttributes(ctx context.Context, id string) (*schema.ResourceAttributes, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/shasw94/projX/app/models"
+
+// IAuditRepository interface
+type IAuditRepository interface {
+	Create(event *models.AuditEvent) error
+}
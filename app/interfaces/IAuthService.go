@@ -10,4 +10,13 @@ type IAuthService interface {
 	Register(ctx context.Context, param *schema.RegisterBodyParams) (*schema.UserTokenInfo, error)
 	Refresh(ctx context.Context, bodyParam *schema.RefreshBodyParams) (*schema.UserTokenInfo, error)
 	Logout(ctx context.Context) error
+	ChangePassword(ctx context.Context, bodyParam *schema.ChangePasswordBodyParams) error
+	LoginMFA(ctx context.Context, bodyParam *schema.LoginMFABodyParams) (*schema.UserTokenInfo, error)
+	SignOutUser(ctx context.Context, userID string) error
+	UnlockUser(ctx context.Context, userID string) error
+	Impersonate(ctx context.Context, userID string) (*schema.ImpersonationTokenInfo, error)
+	ForgotPassword(ctx context.Context, bodyParam *schema.ForgotPasswordBodyParams) error
+	ResetPassword(ctx context.Context, bodyParam *schema.ResetPasswordBodyParams) error
+	SendEmailVerification(ctx context.Context) error
+	VerifyEmail(ctx context.Context, bodyParam *schema.VerifyEmailBodyParams) error
 }
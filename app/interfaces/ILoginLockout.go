@@ -0,0 +1,11 @@
+package interfaces
+
+import "time"
+
+// ILoginLockout interface, tracks failed logins per username and per ip
+type ILoginLockout interface {
+	Check(username, ip string) (retryAfter time.Duration, disabled bool, err error)
+	Fail(username, ip string) error
+	Succeed(username string) error
+	Unlock(username string) error
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import "github.com/shasw94/projX/app/models"
+
+// IMFARepository interface
+type IMFARepository interface {
+	GetByUserID(userID string) (*models.UserMFA, error)
+	IsEnabled(userID string) (bool, error)
+	Save(mfa *models.UserMFA) error
+	Confirm(userID string) error
+	UseStep(userID string, step int64) (bool, error)
+	Delete(userID string) error
+
+	ReplaceRecoveryCodes(userID string, codeHashes []string) error
+	UseRecoveryCode(userID string, codeHash string) (bool, error)
+
+	CreateChallenge(challenge *models.MFAChallenge) error
+	GetChallengeByHash(tokenHash string) (*models.MFAChallenge, error)
+	IncrementChallengeAttempts(id string, max int) (bool, error)
+	UseChallenge(id string) (bool, error)
+}
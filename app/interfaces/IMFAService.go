@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/schema"
+)
+
+// IMFAService interface
+type IMFAService interface {
+	Enroll(ctx context.Context) (*schema.MFAEnrollment, error)
+	Confirm(ctx context.Context, bodyParam *schema.MFACodeBodyParams) (*schema.MFARecoveryCodes, error)
+	RegenerateRecoveryCodes(ctx context.Context, bodyParam *schema.MFACodeBodyParams) (*schema.MFARecoveryCodes, error)
+	Reset(ctx context.Context, userID string) error
+}
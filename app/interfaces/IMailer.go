@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/schema"
+)
+
+// IMailer send email messages
+type IMailer interface {
+	Send(ctx context.Context, msg schema.MailMessage) error
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+)
+
+// IOAuthClientRepository interface
+type IOAuthClientRepository interface {
+	Create(client *models.OAuthClient) error
+	GetByID(id string) (*models.OAuthClient, error)
+	List(param *schema.OAuthClientQueryParam) (*[]models.OAuthClient, error)
+	Revoke(id string) error
+}
@@ -0,0 +1,10 @@
+package interfaces
+
+import "github.com/shasw94/projX/app/models"
+
+// IOAuthCodeRepository interface
+type IOAuthCodeRepository interface {
+	Create(code *models.OAuthAuthorizationCode) error
+	GetByHash(codeHash string) (*models.OAuthAuthorizationCode, error)
+	MarkUsed(id string) (bool, error)
+}
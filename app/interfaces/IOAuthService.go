@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+)
+
+// IOAuthService interface
+type IOAuthService interface {
+	CreateClient(ctx context.Context, param *schema.OAuthClientBodyParams) (*models.OAuthClient, string, error)
+	GetClient(ctx context.Context, id string) (*models.OAuthClient, error)
+	ListClients(ctx context.Context, param *schema.OAuthClientQueryParam) (*[]models.OAuthClient, error)
+	RevokeClient(ctx context.Context, id string) error
+	Authorize(ctx context.Context, param *schema.OAuthAuthorizeBodyParams) (*schema.OAuthAuthorizeResult, error)
+	Token(ctx context.Context, param *schema.OAuthTokenParams) (*schema.OAuthTokenResponse, error)
+}
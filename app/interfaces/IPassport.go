@@ -3,32 +3,42 @@ package interfaces
 import (
 	"github.com/shasw94/projX/app/models"
 	"github.com/shasw94/projX/app/schema"
+	"time"
 )
 
+// IPassport role and permission engine, roles and permissions are referenced by name or id.
+// Assignments to users may be time-bound, inactive ones are ignored by every check
 type IPassport interface {
 	GetRole(r interface{}, withPermissions bool) (*models.Role, error)
 	GetRoles(r interface{}, withPermissions bool) (*schema.Roles, error)
 	GetAllRoles(option *schema.RoleOption) (roles *schema.Roles, totalCount int64, err error)
 	GetRolesOfUser(userID string, option *schema.RoleOption) (roles *schema.Roles, totalCount int64, err error)
-	CreateRole(name string, description string) error
+	CreateRole(name string, description string) (*models.Role, error)
+	UpdateRole(r interface{}, name string, description string) (*models.Role, error)
 	DeleteRole(r interface{}) error
 	AddPermissionsToRole(r interface{}, p interface{}) error
 	ReplacePermissionsToRole(r interface{}, p interface{}) error
 	RemovePermissionsFromRole(r interface{}, p interface{}) error
+	GetParentsOfRole(r interface{}) (*schema.Roles, error)
+	AddParentsToRole(r interface{}, parents interface{}) error
+	RemoveParentsFromRole(r interface{}, parents interface{}) error
+	GetEffectivePermissionsOfRole(r interface{}) (*schema.EffectivePermissionList, error)
 	GetPermission(p interface{}) (permission *models.Permission, err error)
 	GetPermissions(p interface{}) (permissions *schema.Permission, err error)
 	GetAllPermissions(option *schema.PermissionOption) (permissions *schema.Permission, totalCount int64, err error)
-	GetDirectPermissionsOfUser(userID uint, option *schema.PermissionOption) (permissions *schema.Permission, totalCount int64, err error)
+	GetDirectPermissionsOfUser(userID string, option *schema.PermissionOption) (permissions *schema.Permission, totalCount int64, err error)
 	GetPermissionsOfRoles(r interface{}, option *schema.PermissionOption) (permissions *schema.Permission, totalCount int64, err error)
 	GetAllPermissionsOfUser(userID string) (permissions *schema.Permission, err error)
-	CreatePermission(name string, description string) error
+	CreatePermission(name string, description string) (*models.Permission, error)
+	UpdatePermission(p interface{}, name string, description string) (*models.Permission, error)
 	DeletePermission(p interface{}) error
-	AddPermissionsToUser(userID string, p interface{}) error
+	AddPermissionsToUser(userID string, p interface{}, grant *schema.Grant) error
 	ReplacePermissionsToUser(userID string, p interface{}) error
 	RemovePermissionsFromUser(userID string, p interface{}) error
-	AddRolesToUser(userID string, r interface{}) error
+	AddRolesToUser(userID string, r interface{}, grant *schema.Grant) error
 	ReplaceRolesToUser(userID string, r interface{}) error
 	RemoveRolesFromUser(userID string, r interface{}) error
+	SweepGrants(since time.Time, now time.Time) (*schema.ExpiredGrants, error)
 	RoleHasPermission(r interface{}, p interface{}) (b bool, err error)
 	RoleHasAllPermissions(r interface{}, p interface{}) (b bool, err error)
 	RoleHasAnyPermissions(r interface{}, p interface{}) (b bool, err error)
@@ -40,4 +50,8 @@ type IPassport interface {
 	UserHasAnyDirectPermissions(userID string, p interface{}) (b bool, err error)
 	UserHasPermission(userID string, p interface{}) (b bool, err error)
 	UserHasAllPermissions(userID string, p interface{}) (b bool, err error)
+	UserHasAnyPermissions(userID string, p interface{}) (b bool, err error)
+	ExplainUserPermission(userID string, permission string) (*schema.AuthzExplanation, error)
+	SetPermissionResource(p interface{}, resource string, action string) (*models.Permission, error)
+	OnChange(listener func())
 }
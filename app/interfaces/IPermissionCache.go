@@ -0,0 +1,13 @@
+package interfaces
+
+import "github.com/shasw94/projX/app/schema"
+
+// IPermissionCache versioned cache of the effective permissions of users
+type IPermissionCache interface {
+	// Get cached permission set of the user and the current version, the set is nil on miss
+	Get(userID string) (*schema.PermissionSet, int64, error)
+	// Set store the permission set of the user computed at the version
+	Set(userID string, version int64, set *schema.PermissionSet) error
+	// Invalidate bump the version, every cached permission set becomes stale
+	Invalidate() error
+}
@@ -0,0 +1,6 @@
+package interfaces
+
+// IPolicySyncer keep casbin policies in line with the roles and permissions of Passport
+type IPolicySyncer interface {
+	Sync() error
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/shasw94/projX/app/schema"
+
+// IRateLimiter interface, counts requests per key
+type IRateLimiter interface {
+	Allow(key string, policy schema.RateLimitPolicy) (*schema.RateLimitResult, error)
+}
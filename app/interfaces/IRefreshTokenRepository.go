@@ -0,0 +1,12 @@
+package interfaces
+
+import "github.com/shasw94/projX/app/models"
+
+// IRefreshTokenRepository interface
+type IRefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	GetByID(id string) (*models.RefreshToken, error)
+	MarkUsed(id string, replacedBy string) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeByUser(userID string) error
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"github.com/shasw94/projX/pkg/jwt"
+	"time"
+)
+
+// IRevocationStore interface
+type IRevocationStore interface {
+	RevokeToken(jti string, expiresAt time.Time) error
+	RevokeSession(sessionID string) error
+	RevokeUser(userID string) error
+	RevokeClient(clientID string) error
+	IsRevoked(claims *jwt.AccessClaims) (bool, error)
+}
@@ -29,6 +29,7 @@ type IRoleRepository interface {
 	GetRoleIDs(scopes.GormPager) ([]string, int64, error)
 	GetRoleIDsOfUser(string, scopes.GormPager) ([]string, int64, error)
 	GetRoleIDsOfPermission(string, scopes.GormPager) ([]string, int64, error)
+	GetParentIDsOfRoles([]string) ([]string, error)
 
 	// FirstOrCreate & Updates & Delete
 
@@ -42,6 +43,8 @@ type IRoleRepository interface {
 	ReplacePermissions(*models.Role, *schema.Permission) error
 	RemovePermissions(*models.Role, *schema.Permission) error
 	ClearPermissions(*models.Role) error
+	AddParents(*models.Role, *schema.Roles) error
+	RemoveParents(*models.Role, *schema.Roles) error
 
 	// Controls
 
@@ -0,0 +1,12 @@
+package interfaces
+
+import "github.com/shasw94/projX/app/models"
+
+// ISessionRepository interface
+type ISessionRepository interface {
+	Create(session *models.Session) error
+	GetByID(id string) (*models.Session, error)
+	ListActiveByUser(userID string) (*[]models.Session, error)
+	Touch(session *models.Session) error
+	Revoke(id string) error
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/models"
+)
+
+// ISessionService interface
+type ISessionService interface {
+	List(ctx context.Context) (*[]models.Session, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeOthers(ctx context.Context) error
+}
@@ -2,21 +2,24 @@ package interfaces
 
 import (
 	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/models/pivot"
 	"github.com/shasw94/projX/app/schema"
+	"time"
 )
 
 type IUserRepository interface {
 	Create(user *models.User) error
 	GetByID(id string) (*models.User, error)
-	GetUserByToken(token string) (*models.User, error)
+	GetByEmail(email string) (*models.User, error)
 	List(queryParam *schema.UserQueryParam) (*[]models.User, error)
 	Login(item *schema.LoginBodyParams) (*models.User, error)
-	RemoveToken(userID string) (*models.User, error)
 	Update(userID string, bodyParam *schema.UserUpdateBodyParam) (*models.User, error)
-	AddPermissions(userID string, permissions schema.Permission) (err error)
+	UpdatePassword(userID string, hashed string) error
+	VerifyEmail(userID string) error
+	AddPermissions(userID string, permissions schema.Permission, grant *schema.Grant) (err error)
 	ReplacePermissions(userID string, permissions schema.Permission) (err error)
 	RemovePermissions(userID string, permissiosn schema.Permission) (err error)
-	AddRoles(userID string, roles schema.Roles) (err error)
+	AddRoles(userID string, roles schema.Roles, grant *schema.Grant) (err error)
 	ReplaceRoles(userID string, roles schema.Roles) (err error)
 	RemoveRoles(userID string, roles schema.Roles) (err error)
 	ClearRoles(userID string) (err error)
@@ -27,4 +30,7 @@ type IUserRepository interface {
 	HasDirectPermission(userID string, permission models.Permission) (b bool, err error)
 	HasAllDirectPermissions(userID string, permissions schema.Permission) (b bool, err error)
 	HasAnyDirectPermissions(userID string, permissions schema.Permission) (b bool, err error)
+	DeleteExpiredGrants(now time.Time) (roles []pivot.UserRole, permissions []pivot.UserPermission, err error)
+	CountStartedGrants(since time.Time, now time.Time) (int64, error)
+	GetInactiveGrants(userID string, now time.Time) (roles []pivot.UserRole, permissions []pivot.UserPermission, err error)
 }
@@ -7,6 +7,7 @@ import (
 )
 
 type IUserService interface {
+	IAttributeLoader
 	GetByID(ctx context.Context, id string) (*models.User, error)
 	List(ctx context.Context, param *schema.UserQueryParam) (*[]models.User, error)
 }
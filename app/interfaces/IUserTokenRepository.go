@@ -0,0 +1,11 @@
+package interfaces
+
+import "github.com/shasw94/projX/app/models"
+
+// IUserTokenRepository interface
+type IUserTokenRepository interface {
+	Create(token *models.UserToken) error
+	GetByHash(purpose string, tokenHash string) (*models.UserToken, error)
+	Use(id string) (bool, error)
+	InvalidateByUser(userID string, purpose string) error
+}
@@ -0,0 +1,9 @@
+package lockout
+
+import "go.uber.org/dig"
+
+// Inject login lockout
+func Inject(container *dig.Container) error {
+	_ = container.Provide(New)
+	return nil
+}
@@ -0,0 +1,176 @@
+package lockout
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/middleware/cache"
+	"github.com/shasw94/projX/config"
+	"strings"
+	"time"
+)
+
+const (
+	keyPrefix = "login:"
+
+	defaultMaxAttempts   = 5
+	defaultIPMaxAttempts = 20
+	defaultWindow        = 15 * time.Minute
+	defaultBaseDelay     = 30 * time.Second
+	defaultMaxDelay      = time.Hour
+)
+
+// backend keeps failure counters and locks
+type backend interface {
+	// incr increase counter of key, the counter expires window after the last failure
+	incr(key string, window time.Duration) (int64, error)
+	// lock set lock of key, a zero duration locks until unlocked
+	lock(key string, d time.Duration) error
+	// lockedFor return the longest remaining lock of the keys
+	lockedFor(keys ...string) (time.Duration, error)
+	// exists the key is set
+	exists(key string) (bool, error)
+	del(keys ...string) error
+}
+
+// Options of lockout
+type Options struct {
+	// MaxAttempts failed attempts per username before it is locked
+	MaxAttempts int64
+	// IPMaxAttempts failed attempts per ip before it is locked
+	IPMaxAttempts int64
+	// DisableAfter failed attempts per username before it is disabled until admin unlock, 0 never
+	DisableAfter int64
+	// Window failures are forgotten once no failure happened for the window
+	Window time.Duration
+	// BaseDelay first lock duration, doubled by every further failure up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Store login lockout with progressive backoff.
+// Usernames and ips are locked independently, locks of usernames apply
+// whether or not the account exists so responses do not reveal accounts
+type Store struct {
+	backend backend
+	opts    Options
+}
+
+// New return redis lockout if redis is available, otherwise in-memory lockout
+func New() interfaces.ILoginLockout {
+	opts := optionsFromConfig()
+	if r := cache.Redis(); r != nil {
+		return NewRedisStore(r, opts)
+	}
+	return NewMemoryStore(opts)
+}
+
+// optionsFromConfig read options from config, unset values fall back to defaults
+func optionsFromConfig() Options {
+	conf := config.Config.Lockout
+	opts := Options{
+		MaxAttempts:   int64(conf.MaxAttempts),
+		IPMaxAttempts: int64(conf.IPMaxAttempts),
+		DisableAfter:  int64(conf.DisableAfter),
+		Window:        time.Duration(conf.Window) * time.Second,
+		BaseDelay:     time.Duration(conf.BaseDelay) * time.Second,
+		MaxDelay:      time.Duration(conf.MaxDelay) * time.Second,
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.IPMaxAttempts <= 0 {
+		opts.IPMaxAttempts = defaultIPMaxAttempts
+	}
+	if opts.Window <= 0 {
+		opts.Window = defaultWindow
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = defaultBaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = defaultMaxDelay
+	}
+	return opts
+}
+
+func userKey(username string) string {
+	return strings.ToLower(username)
+}
+
+func failKey(kind, id string) string {
+	return keyPrefix + "fail:" + kind + ":" + id
+}
+
+func lockKey(kind, id string) string {
+	return keyPrefix + "lock:" + kind + ":" + id
+}
+
+func disabledKey(username string) string {
+	return keyPrefix + "disabled:" + userKey(username)
+}
+
+// delay lock duration after the nth failure beyond the allowed attempts
+func (s *Store) delay(n int64) time.Duration {
+	if n > 30 {
+		return s.opts.MaxDelay
+	}
+	d := s.opts.BaseDelay << uint(n)
+	if d <= 0 || d > s.opts.MaxDelay {
+		return s.opts.MaxDelay
+	}
+	return d
+}
+
+// Check return how long login is locked for the username and ip,
+// disabled if the username has to be unlocked by admin
+func (s *Store) Check(username, ip string) (time.Duration, bool, error) {
+	disabled, err := s.backend.exists(disabledKey(username))
+	if err != nil || disabled {
+		return 0, disabled, err
+	}
+
+	keys := []string{lockKey("user", userKey(username))}
+	if ip != "" {
+		keys = append(keys, lockKey("ip", ip))
+	}
+	retryAfter, err := s.backend.lockedFor(keys...)
+	return retryAfter, false, err
+}
+
+// Fail record failed login, lock username and ip once they exceed their attempts
+func (s *Store) Fail(username, ip string) error {
+	count, err := s.backend.incr(failKey("user", userKey(username)), s.opts.Window)
+	if err != nil {
+		return err
+	}
+	if s.opts.DisableAfter > 0 && count >= s.opts.DisableAfter {
+		err = s.backend.lock(disabledKey(username), 0)
+	} else if count >= s.opts.MaxAttempts {
+		err = s.backend.lock(lockKey("user", userKey(username)), s.delay(count-s.opts.MaxAttempts))
+	}
+	if err != nil || ip == "" {
+		return err
+	}
+
+	count, err = s.backend.incr(failKey("ip", ip), s.opts.Window)
+	if err != nil {
+		return err
+	}
+	if count >= s.opts.IPMaxAttempts {
+		return s.backend.lock(lockKey("ip", ip), s.delay(count-s.opts.IPMaxAttempts))
+	}
+	return nil
+}
+
+// Succeed forget failures of the username after successful login
+func (s *Store) Succeed(username string) error {
+	return s.backend.del(failKey("user", userKey(username)))
+}
+
+// Unlock remove failures, lock and disabled state of the username
+func (s *Store) Unlock(username string) error {
+	return s.backend.del(
+		failKey("user", userKey(username)),
+		lockKey("user", userKey(username)),
+		disabledKey(username),
+	)
+}
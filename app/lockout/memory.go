@@ -0,0 +1,96 @@
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// expired zero expiresAt never expires
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+type memoryBackend struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryStore return lockout kept in memory of current process
+func NewMemoryStore(opts Options) *Store {
+	return &Store{backend: &memoryBackend{entries: map[string]memoryEntry{}}, opts: opts}
+}
+
+// prune remove expired entries, caller holds the lock
+func (m *memoryBackend) prune(now time.Time) {
+	for k, entry := range m.entries {
+		if entry.expired(now) {
+			delete(m.entries, k)
+		}
+	}
+}
+
+func (m *memoryBackend) incr(key string, window time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.prune(now)
+	entry := m.entries[key]
+	entry.count++
+	entry.expiresAt = now.Add(window)
+	m.entries[key] = entry
+	return entry.count, nil
+}
+
+func (m *memoryBackend) lock(key string, d time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := memoryEntry{count: 1}
+	if d > 0 {
+		entry.expiresAt = time.Now().Add(d)
+	}
+	m.entries[key] = entry
+	return nil
+}
+
+func (m *memoryBackend) lockedFor(keys ...string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var longest time.Duration
+	now := time.Now()
+	for _, key := range keys {
+		entry, ok := m.entries[key]
+		if !ok || entry.expired(now) || entry.expiresAt.IsZero() {
+			continue
+		}
+		if d := entry.expiresAt.Sub(now); d > longest {
+			longest = d
+		}
+	}
+	return longest, nil
+}
+
+func (m *memoryBackend) exists(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	return ok && !entry.expired(time.Now()), nil
+}
+
+func (m *memoryBackend) del(keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.entries, key)
+	}
+	return nil
+}
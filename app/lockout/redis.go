@@ -0,0 +1,61 @@
+package lockout
+
+import (
+	"context"
+	"github.com/go-redis/redis/v9"
+	"github.com/shasw94/projX/app/middleware/cache"
+	"time"
+)
+
+var ctx = context.Background()
+
+type redisBackend struct {
+	client *redis.Client
+}
+
+// NewRedisStore return lockout sharing the redis connection of cache
+func NewRedisStore(r *cache.GRedis, opts Options) *Store {
+	return &Store{backend: &redisBackend{client: r.Client()}, opts: opts}
+}
+
+func (r *redisBackend) incr(key string, window time.Duration) (int64, error) {
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (r *redisBackend) lock(key string, d time.Duration) error {
+	return r.client.Set(ctx, key, time.Now().Unix(), d).Err()
+}
+
+func (r *redisBackend) lockedFor(keys ...string) (time.Duration, error) {
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.DurationCmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipe.PTTL(ctx, key))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	var longest time.Duration
+	for _, cmd := range cmds {
+		if d := cmd.Val(); d > longest {
+			longest = d
+		}
+	}
+	return longest, nil
+}
+
+func (r *redisBackend) exists(key string) (bool, error) {
+	n, err := r.client.Exists(ctx, key).Result()
+	return n > 0, err
+}
+
+func (r *redisBackend) del(keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
+}
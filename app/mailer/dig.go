@@ -0,0 +1,9 @@
+package mailer
+
+import "go.uber.org/dig"
+
+// Inject mailer
+func Inject(container *dig.Container) error {
+	_ = container.Provide(New)
+	return nil
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/shasw94/projX/app/schema"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer write messages as .eml files into a directory, for local development
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer return new FileMailer pointer
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send message
+func (m *FileMailer) Send(ctx context.Context, msg schema.MailMessage) error {
+	if msg.From == "" {
+		msg.From = m.from
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := time.Now().Format("20060102T150405") + "-" + uuid.New().String() + ".eml"
+	return os.WriteFile(filepath.Join(m.dir, name), encode(msg), 0o600)
+}
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/logger"
+)
+
+// Drivers of mailer
+const (
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverMemory = "memory"
+
+	defaultDir = "storage/mails"
+)
+
+// New return mailer of the configured driver, file mailer by default
+func New() interfaces.IMailer {
+	conf := config.Config.Mailer
+	switch conf.Driver {
+	case DriverSMTP:
+		return NewSMTPMailer(conf.SMTP.Host, conf.SMTP.Port, conf.SMTP.Username, conf.SMTP.Password, conf.From)
+	case DriverMemory:
+		return NewMemoryMailer(conf.From)
+	case DriverFile, "":
+	default:
+		logger.Warnf("Unknown mailer driver %s, mails are written to files", conf.Driver)
+	}
+
+	dir := conf.Dir
+	if dir == "" {
+		dir = defaultDir
+	}
+	return NewFileMailer(dir, conf.From)
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/schema"
+	"sync"
+)
+
+// MemoryMailer keep messages in memory, for tests
+type MemoryMailer struct {
+	mu       sync.Mutex
+	from     string
+	messages []schema.MailMessage
+}
+
+// NewMemoryMailer return new MemoryMailer pointer
+func NewMemoryMailer(from string) *MemoryMailer {
+	return &MemoryMailer{from: from}
+}
+
+// Send message
+func (m *MemoryMailer) Send(ctx context.Context, msg schema.MailMessage) error {
+	if msg.From == "" {
+		msg.From = m.from
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages return all sent messages
+func (m *MemoryMailer) Messages() []schema.MailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]schema.MailMessage(nil), m.messages...)
+}
+
+// Last return the latest message sent to the address
+func (m *MemoryMailer) Last(to string) (schema.MailMessage, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return schema.MailMessage{}, false
+}
+
+// Reset remove all messages
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"github.com/shasw94/projX/app/schema"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// SMTPMailer send messages through smtp server
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer return new SMTPMailer pointer, plain auth is used when username is set
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+// Send message
+func (m *SMTPMailer) Send(ctx context.Context, msg schema.MailMessage) error {
+	if msg.From == "" {
+		msg.From = m.from
+	}
+	return smtp.SendMail(m.addr, m.auth, msg.From, []string{msg.To}, encode(msg))
+}
+
+// encode message in RFC 5322 format
+func encode(msg schema.MailMessage) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
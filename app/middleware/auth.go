@@ -1,32 +1,120 @@
 package middleware
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/contextx"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/logger"
 	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
 	"github.com/shasw94/projX/pkg/http/wrapper"
 	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/shasw94/projX/pkg/utils"
 )
 
-func wrapUserAuthContext(c *gin.Context, userId string) {
-	app.SetUserID(c, userId)
-	c.Request = c.Request.WithContext(c)
+// wrapUserAuthContext keep the effective user, the impersonating actor if any and the claims in context
+func wrapUserAuthContext(c *gin.Context, claims *jwt.AccessClaims) {
+	app.SetUserID(c, claims.Subject)
+	app.SetSessionID(c, claims.SessionID)
+	app.SetClaims(c, claims)
+
+	var ctx context.Context = c
+	ctx = contextx.NewUserID(ctx, claims.Subject)
+	if actorID := claims.ActorID(); actorID != "" {
+		app.SetActorID(c, actorID)
+		ctx = contextx.NewActorID(ctx, actorID)
+	}
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// auditImpersonatedRequest record request made by an admin acting as another user
+func auditImpersonatedRequest(c *gin.Context, audit interfaces.IAuditRepository, claims *jwt.AccessClaims) {
+	logger.Infof("Impersonated request %s %s by %s as %s: %d",
+		c.Request.Method, c.Request.URL.Path, claims.ActorID(), claims.Subject, c.Writer.Status())
+
+	err := audit.Create(&models.AuditEvent{
+		Event:   models.AuditImpersonatedCall,
+		UserID:  claims.Subject,
+		ActorID: claims.ActorID(),
+		Detail: utils.JSONMarshalToString(map[string]interface{}{
+			"token_id": claims.ID,
+			"method":   c.Request.Method,
+			"path":     c.Request.URL.Path,
+			"status":   c.Writer.Status(),
+			"ip":       c.ClientIP(),
+		}),
+	})
+	if err != nil {
+		logger.Error("Failed to record audit event: ", err)
+	}
 }
 
-// UserAuthMiddleware User Auth Middleware
-func UserAuthMiddleware(a jwt.IJWTAuth, skippers ...SkipperFunc) gin.HandlerFunc {
+// UserAuthMiddleware User Auth Middleware, reject revoked access tokens.
+// Claims of the token are kept in context, see app.GetClaims. Requests made by impersonation are audited
+func UserAuthMiddleware(a jwt.IJWTAuth, store interfaces.IRevocationStore, audit interfaces.IAuditRepository, skippers ...SkipperFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if SkipHandler(c, skippers...) {
 			c.Next()
 			return
 		}
 
-		userID, err := a.ParseUserID(app.GetToken(c), false)
+		claims, err := a.ParseAccessToken(app.GetToken(c))
 		if err != nil {
 			wrapper.Translate(c, wrapper.Response{Error: err})
 			c.Abort()
 			return
 		}
-		wrapUserAuthContext(c, userID)
+
+		revoked, err := store.IsRevoked(claims)
+		if err != nil {
+			logger.Error("Failed to check token revocation: ", err)
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrorInternalServer.New()})
+			c.Abort()
+			return
+		}
+		if revoked {
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrTokenRevoked})
+			c.Abort()
+			return
+		}
+		wrapUserAuthContext(c, claims)
 		c.Next()
+
+		if claims.ActorID() != "" {
+			auditImpersonatedRequest(c, audit, claims)
+		}
+	}
+}
+
+// APIKeyAuthMiddleware authenticate service accounts by X-API-Key header or Authorization header with ApiKey scheme.
+// Claims of the key are kept in context like the ones of access tokens
+func APIKeyAuthMiddleware(service interfaces.IAPIKeyService, skippers ...SkipperFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if SkipHandler(c, skippers...) {
+			c.Next()
+			return
+		}
+
+		claims, err := service.Authenticate(app.GetAPIKey(c))
+		if err != nil {
+			wrapper.Translate(c, wrapper.Response{Error: err})
+			c.Abort()
+			return
+		}
+		wrapUserAuthContext(c, claims)
+		c.Next()
+	}
+}
+
+// UserOrAPIKeyAuthMiddleware authenticate by api key when the request carries one, otherwise by access token
+func UserOrAPIKeyAuthMiddleware(userAuth gin.HandlerFunc, apiKeyAuth gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if app.GetAPIKey(c) != "" {
+			apiKeyAuth(c)
+			return
+		}
+		userAuth(c)
 	}
 }
@@ -23,6 +23,14 @@ func New() Cache {
 
 var cache = New()
 
+// Redis return shared redis connection, nil if redis is not available
+func Redis() *GRedis {
+	if r, ok := cache.(*GRedis); ok && r != nil && r.IsConnected() {
+		return r
+	}
+	return nil
+}
+
 type responseBodyWriter struct {
 	gin.ResponseWriter
 	body *bytes.Buffer
@@ -43,6 +43,11 @@ func NewRedis() *GRedis {
 	return &GRedis{client: rdb, expiryTime: expiryTime}
 }
 
+// Client return underlying redis client
+func (g *GRedis) Client() *redis.Client {
+	return g.client
+}
+
 // IsConnected check redis is connected or not
 func (g *GRedis) IsConnected() bool {
 	if g.client == nil {
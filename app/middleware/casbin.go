@@ -5,11 +5,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/shasw94/projX/app/contextx"
 	"github.com/shasw94/projX/config"
-	"github.com/shasw94/projX/pkg/utils"
-	"net/http"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/http/wrapper"
 )
 
-// CasbinMiddleware Valid user interface permission
+// CasbinMiddleware enforce casbin policies of the user on the request path and method
 func CasbinMiddleware(enforcer *casbin.SyncedEnforcer, skippers ...SkipperFunc) gin.HandlerFunc {
 	cfg := config.Config.Casbin
 	if !cfg.Enable {
@@ -26,13 +27,12 @@ func CasbinMiddleware(enforcer *casbin.SyncedEnforcer, skippers ...SkipperFunc)
 		m := c.Request.Method
 		userID := contextx.FromUserID(c.Request.Context())
 		if b, err := enforcer.Enforce(userID, p, m); err != nil {
-			c.JSON(http.StatusUnauthorized, utils.PrepareResponse(nil, "Unauthorized", utils.ErrorAuth))
-
+			logger.Error("Failed to enforce casbin policy: ", err)
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrorInternalServer.New()})
 			c.Abort()
 			return
 		} else if !b {
-			c.JSON(http.StatusUnauthorized, utils.PrepareResponse(nil, "Unauthorized", utils.ErrorAuth))
-
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrorNoPermission.New()})
 			c.Abort()
 			return
 		}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/http/wrapper"
+	"net/http"
+)
+
+// CSRFMiddleware double-submit csrf protection of cookie sessions. State-changing requests
+// carrying session cookies without Authorization header must echo the csrf_token cookie
+// in the X-CSRF-Token header
+func CSRFMiddleware(skippers ...SkipperFunc) gin.HandlerFunc {
+	if !config.Config.SessionCookie.Enable {
+		return EmptyMiddleware()
+	}
+
+	return func(c *gin.Context) {
+		if SkipHandler(c, skippers...) || isSafeMethod(c.Request.Method) ||
+			c.GetHeader("Authorization") != "" || !app.HasSessionCookie(c) {
+			c.Next()
+			return
+		}
+
+		cookie, _ := c.Cookie(app.CSRFTokenCookie)
+		header := c.GetHeader(app.CSRFTokenHeader)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrorCSRFTokenInvalid.New()})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// isSafeMethod the method does not change state
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
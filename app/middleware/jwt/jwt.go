@@ -1,88 +0,0 @@
-package jwt
-
-import (
-	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/jinzhu/copier"
-	"github.com/shasw94/projX/logger"
-	"github.com/shasw94/projX/pkg/utils"
-	"net/http"
-	"strings"
-	"time"
-)
-
-const (
-	TokenExpiredTime = 30
-)
-
-func GenerateToken(payload interface{}) string {
-	tokenContent := jwt.MapClaims{
-		"payload": payload,
-		"exp":     time.Now().Add(time.Second * TokenExpiredTime).Unix(),
-	}
-	jwtToken := jwt.NewWithClaims(jwt.GetSigningMethod("HS256"), tokenContent)
-	token, err := jwtToken.SignedString([]byte("TokenPassword"))
-	if err != nil {
-		logger.Error("Failed to generate token: ", err)
-		return ""
-	}
-
-	return token
-}
-
-// ValidateToken validate jwt token
-func ValidateToken(jwtToken string) (map[string]interface{}, error) {
-	cleanJWT := strings.Replace(jwtToken, "Bearer ", "", -1)
-	tokenData := jwt.MapClaims{}
-	token, err := jwt.ParseWithClaims(cleanJWT, tokenData, func(token *jwt.Token) (interface{}, error) {
-		return []byte("TokenPassword"), nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	if !token.Valid {
-		return nil, jwt.ErrInvalidKey
-	}
-
-	var data map[string]interface{}
-	copier.Copy(&data, tokenData["payload"])
-	return data, nil
-}
-
-// JWT middleware
-func JWT() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var code string
-
-		code = utils.Success
-		token := c.GetHeader("Authorization")
-
-		if token == "" {
-			code = utils.InvalidParams
-			c.JSON(http.StatusUnauthorized, utils.PrepareResponse(nil, "Unauthorized", code))
-
-			c.Abort()
-			return
-		}
-
-		_, err := ValidateToken(token)
-		if err != nil {
-			switch err.(*jwt.ValidationError).Errors {
-			case jwt.ValidationErrorExpired:
-				code = utils.ErrorAuthCheckTokenTimeout
-			default:
-				code = utils.ErrorAuthCheckTokenFail
-			}
-		}
-
-		if code != utils.Success {
-			c.JSON(http.StatusUnauthorized, utils.PrepareResponse(nil, "Unauthorized", code))
-
-			c.Abort()
-			return
-		}
-		c.Next()
-	}
-}
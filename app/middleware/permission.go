@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/contextx"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/http/wrapper"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/shasw94/projX/pkg/utils"
+)
+
+// Authorizer builds route guards checking roles and permissions of the authenticated user with Passport,
+// and rules on the attributes of resources. Must be used after the authentication middleware
+type Authorizer struct {
+	passport interfaces.IPassport
+	loaders  map[string]interfaces.IAttributeLoader
+}
+
+// NewAuthorizer return new Authorizer
+func NewAuthorizer(passport interfaces.IPassport) *Authorizer {
+	return &Authorizer{passport: passport, loaders: map[string]interfaces.IAttributeLoader{}}
+}
+
+// checkFunc decide whether the authenticated principal is allowed
+type checkFunc func(userID string, claims *jwt.AccessClaims) (bool, error)
+
+// RequirePermission allow the request when the user has the permission
+func (a *Authorizer) RequirePermission(permission string) gin.HandlerFunc {
+	return a.RequireAllPermissions(permission)
+}
+
+// RequireAllPermissions allow the request when the user has all the permissions
+func (a *Authorizer) RequireAllPermissions(permissions ...string) gin.HandlerFunc {
+	return a.guard(func(userID string, claims *jwt.AccessClaims) (bool, error) {
+		for _, permission := range permissions {
+			if !delegatedAllows(claims, permission) {
+				return false, nil
+			}
+		}
+		if isClientToken(claims) {
+			return true, nil
+		}
+		return notExistAsDenied(a.passport.UserHasAllPermissions(userID, permissions))
+	})
+}
+
+// RequireAnyPermission allow the request when the user has at least one of the permissions
+func (a *Authorizer) RequireAnyPermission(permissions ...string) gin.HandlerFunc {
+	return a.guard(func(userID string, claims *jwt.AccessClaims) (bool, error) {
+		for _, permission := range permissions {
+			if !delegatedAllows(claims, permission) {
+				continue
+			}
+			if isClientToken(claims) {
+				return true, nil
+			}
+			ok, err := notExistAsDenied(a.passport.UserHasPermission(userID, permission))
+			if ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	})
+}
+
+// RequireAnyRole allow the request when the user has at least one of the roles.
+// Delegated tokens never satisfy role guards, roles are not part of what is delegated
+func (a *Authorizer) RequireAnyRole(roles ...string) gin.HandlerFunc {
+	return a.guard(func(userID string, claims *jwt.AccessClaims) (bool, error) {
+		if isDelegated(claims) {
+			return false, nil
+		}
+		for _, role := range roles {
+			ok, err := notExistAsDenied(a.passport.UserHasRole(userID, role))
+			if ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	})
+}
+
+// RequireAllRoles allow the request when the user has all the roles, delegated tokens are refused
+func (a *Authorizer) RequireAllRoles(roles ...string) gin.HandlerFunc {
+	return a.guard(func(userID string, claims *jwt.AccessClaims) (bool, error) {
+		if isDelegated(claims) {
+			return false, nil
+		}
+		return notExistAsDenied(a.passport.UserHasAllRoles(userID, roles))
+	})
+}
+
+// RequireDirect allow only tokens issued to the user directly. Delegated tokens of oauth clients,
+// api keys and impersonating admins act within their grant and cannot manage the account of the user
+func (a *Authorizer) RequireDirect() gin.HandlerFunc {
+	return a.guard(func(userID string, claims *jwt.AccessClaims) (bool, error) {
+		return !isDelegated(claims), nil
+	})
+}
+
+// guard run the check against the authenticated principal, abort with ErrorNoPermission when denied
+func (a *Authorizer) guard(check checkFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := contextx.FromUserID(c.Request.Context())
+		claims := app.GetClaims(c)
+		if userID == "" || claims == nil {
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrorAuthCheckTokenFail.New()})
+			c.Abort()
+			return
+		}
+
+		ok, err := check(userID, claims)
+		if err != nil {
+			logger.Error("Failed to check permission: ", err)
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrorInternalServer.New()})
+			c.Abort()
+			return
+		}
+		if !ok {
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrorNoPermission.New()})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// isDelegated the token acts for the user through an oauth client, an api key or an impersonating actor,
+// so it only carries the permissions granted to the delegate
+func isDelegated(claims *jwt.AccessClaims) bool {
+	_, apiKey := claims.Extra["api_key"]
+	return claims.ClientID != "" || apiKey || claims.ActorID() != ""
+}
+
+// isClientToken the token is issued to an oauth client acting on its own behalf (client credentials)
+func isClientToken(claims *jwt.AccessClaims) bool {
+	return claims.ClientID != "" && claims.Subject == claims.ClientID
+}
+
+// delegatedAllows delegated tokens must carry the permission or a wildcard covering it,
+// other tokens are checked by Passport only
+func delegatedAllows(claims *jwt.AccessClaims, permission string) bool {
+	if !isDelegated(claims) {
+		return true
+	}
+	return utils.MatchAnyGuard(claims.Permissions, utils.Guard(permission))
+}
+
+// notExistAsDenied unknown roles and permissions cannot be held by anyone
+func notExistAsDenied(ok bool, err error) (bool, error) {
+	switch errors.GetType(err) {
+	case errors.ErrorNotExistRole, errors.ErrorNotExistPermission:
+		return false, nil
+	}
+	return ok, err
+}
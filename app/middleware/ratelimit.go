@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/http/wrapper"
+	"github.com/shasw94/projX/pkg/utils"
+	"math"
+	"strconv"
+	"time"
+)
+
+// rate limit keys
+const (
+	RateLimitByIP     = "ip"
+	RateLimitByUser   = "user"
+	RateLimitByAPIKey = "api_key"
+)
+
+// rateLimitKey identify client of the request, user and api key fall back to ip for anonymous requests
+func rateLimitKey(c *gin.Context, keyBy string) string {
+	switch keyBy {
+	case RateLimitByAPIKey:
+		if key := app.GetAPIKey(c); key != "" {
+			return "key:" + utils.HashToken(key)
+		}
+		fallthrough
+	case RateLimitByUser:
+		if userID := app.GetUserID(c); userID != "" {
+			return "user:" + userID
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// seconds round duration up to whole seconds
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// RateLimitMiddleware limit requests by the configured policy of the route group.
+// Keying by user requires the middleware to run after UserAuthMiddleware.
+// Requests are let through when the limiter fails
+func RateLimitMiddleware(limiter interfaces.IRateLimiter, name string, skippers ...SkipperFunc) gin.HandlerFunc {
+	conf, ok := config.Config.RateLimit.Policies[name]
+	if !config.Config.RateLimit.Enable || !ok || conf.Limit <= 0 || conf.Period <= 0 {
+		return EmptyMiddleware()
+	}
+	policy := schema.RateLimitPolicy{
+		Algorithm: conf.Algorithm,
+		Limit:     int64(conf.Limit),
+		Period:    time.Duration(conf.Period) * time.Second,
+	}
+
+	return func(c *gin.Context) {
+		if SkipHandler(c, skippers...) {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Allow(name+":"+rateLimitKey(c, conf.KeyBy), policy)
+		if err != nil {
+			logger.Error("Failed to check rate limit: ", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		c.Header("X-RateLimit-Reset", seconds(result.Reset))
+		if !result.Allowed {
+			c.Header("Retry-After", seconds(result.RetryAfter))
+			wrapper.Translate(c, wrapper.Response{Error: errors.ErrorTooManyRequest.New()})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/jwt"
+)
+
+// Rule decide whether the request may access the resource, e.g.
+// AnyOf(IsOwner(), HasPermission(models.PermissionUsersRead))
+type Rule func(r *RuleContext) (bool, error)
+
+// RuleContext request and resource a rule is evaluated for, attributes of the resource are loaded once on first use
+type RuleContext struct {
+	Context  context.Context
+	UserID   string
+	Claims   *jwt.AccessClaims
+	Resource string
+	ID       string
+
+	passport   interfaces.IPassport
+	loader     interfaces.IAttributeLoader
+	attributes *schema.ResourceAttributes
+}
+
+// Attributes attributes of the resource loaded by the loader of the resource type
+func (r *RuleContext) Attributes() (*schema.ResourceAttributes, error) {
+	if r.attributes == nil {
+		attributes, err := r.loader.LoadAttributes(r.Context, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.attributes = attributes
+	}
+	return r.attributes, nil
+}
+
+// IsOwner the authenticated user owns the resource. Delegated tokens are never owners,
+// they only carry the permissions granted to the delegate. Resources which cannot be loaded have no owner
+func IsOwner() Rule {
+	return func(r *RuleContext) (bool, error) {
+		if isDelegated(r.Claims) {
+			return false, nil
+		}
+		attributes, err := r.Attributes()
+		if err != nil {
+			logger.Warn("Failed to load attributes of ", r.Resource, " ", r.ID, ": ", err)
+			return false, nil
+		}
+		return attributes.OwnerID != "" && attributes.OwnerID == r.UserID, nil
+	}
+}
+
+// HasPermission the authenticated user has the permission, checked like RequirePermission
+func HasPermission(permission string) Rule {
+	return func(r *RuleContext) (bool, error) {
+		if !delegatedAllows(r.Claims, permission) {
+			return false, nil
+		}
+		if isClientToken(r.Claims) {
+			return true, nil
+		}
+		return notExistAsDenied(r.passport.UserHasPermission(r.UserID, permission))
+	}
+}
+
+// AnyOf at least one of the rules holds, rules are evaluated in order until one holds
+func AnyOf(rules ...Rule) Rule {
+	return func(r *RuleContext) (bool, error) {
+		for _, rule := range rules {
+			ok, err := rule(r)
+			if ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+}
+
+// AllOf every rule holds, rules are evaluated in order until one does not
+func AllOf(rules ...Rule) Rule {
+	return func(r *RuleContext) (bool, error) {
+		for _, rule := range rules {
+			ok, err := rule(r)
+			if !ok || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// RegisterLoader use the loader for the attributes of resources of the type
+func (a *Authorizer) RegisterLoader(resource string, loader interfaces.IAttributeLoader) {
+	a.loaders[resource] = loader
+}
+
+// Require allow the request when the rule holds for the resource of the type identified by the path parameter.
+// The loader of the resource type must be registered before the route is served
+func (a *Authorizer) Require(resource string, param string, rule Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a.guard(func(userID string, claims *jwt.AccessClaims) (bool, error) {
+			loader, ok := a.loaders[resource]
+			if !ok {
+				return false, errors.Newf("no attribute loader registered for resource %s", resource)
+			}
+			return rule(&RuleContext{
+				Context:  c.Request.Context(),
+				UserID:   userID,
+				Claims:   claims,
+				Resource: resource,
+				ID:       c.Param(param),
+				passport: a.passport,
+				loader:   loader,
+			})
+		})(c)
+	}
+}
@@ -1,37 +1,17 @@
 package migration
 
 import (
-	"fmt"
 	"github.com/shasw94/projX/app/interfaces"
 	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/models/pivot"
+	"github.com/shasw94/projX/config"
 	"go.uber.org/dig"
 )
 
-// CreateAdmin create new user role admin
+// CreateAdmin create the roles, permissions and bootstrap users of the rbac seed file, existing records are kept
 func CreateAdmin(container *dig.Container) error {
-	return container.Invoke(func(
-		userRepo interfaces.IUserRepository,
-		roleRepo interfaces.IRoleRepository,
-	) error {
-		fmt.Println("reached here")
-		adminRole := &models.Role{Name: "admin", Description: "Admin"}
-		userRole := &models.Role{Name: "user", Description: "User"}
-		err := roleRepo.Create(adminRole)
-		err = roleRepo.Create(userRole)
-		if err != nil {
-			return err
-		}
-		err = userRepo.Create(&models.User{
-			Username: "admin",
-			Password: "admin",
-			Email:    "admin@admin.com",
-			RoleID:   adminRole.ID,
-		})
-		if err != nil {
-			return err
-		}
-		return nil
-	})
+	_, err := SyncRBAC(container, config.Config.RBAC.SeedFile, false, false)
+	return err
 }
 
 // Migrate migrate to database
@@ -39,8 +19,22 @@ func Migrate(container *dig.Container) error {
 	return container.Invoke(func(db interfaces.IDatabase) error {
 		User := models.User{}
 		Role := models.Role{}
+		Permission := models.Permission{}
+		UserRole := pivot.UserRole{}
+		UserPermission := pivot.UserPermission{}
+		RefreshToken := models.RefreshToken{}
+		Session := models.Session{}
+		AuditEvent := models.AuditEvent{}
+		OAuthClient := models.OAuthClient{}
+		OAuthCode := models.OAuthAuthorizationCode{}
+		UserMFA := models.UserMFA{}
+		MFARecoveryCode := models.MFARecoveryCode{}
+		MFAChallenge := models.MFAChallenge{}
+		UserToken := models.UserToken{}
+		APIKey := models.APIKey{}
+		CasbinRule := models.CasbinRule{}
 
-		db.GetInstance().AutoMigrate(&User, &Role)
+		db.GetInstance().AutoMigrate(&User, &Permission, &Role, &UserRole, &UserPermission, &RefreshToken, &Session, &AuditEvent, &OAuthClient, &OAuthCode, &UserMFA, &MFARecoveryCode, &MFAChallenge, &UserToken, &APIKey, &CasbinRule)
 		return nil
 	})
 }
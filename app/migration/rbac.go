@@ -0,0 +1,21 @@
+package migration
+
+import (
+	"github.com/shasw94/projX/app/rbac"
+	"github.com/shasw94/projX/config"
+	"go.uber.org/dig"
+)
+
+// SyncRBAC bring roles, permissions and bootstrap users in line with the seed file.
+// Roles and permissions missing from the file are deleted when prune is set, nothing is changed when dryRun is set
+func SyncRBAC(container *dig.Container, path string, prune, dryRun bool) (plan *rbac.Plan, err error) {
+	seed, err := rbac.Load(config.Path(path))
+	if err != nil {
+		return nil, err
+	}
+	err = container.Invoke(func(syncer *rbac.Syncer) error {
+		plan, err = syncer.Sync(seed, prune, dryRun)
+		return err
+	})
+	return plan, err
+}
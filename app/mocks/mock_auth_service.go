@@ -35,6 +35,49 @@ func (m *MockIAuthService) EXPECT() *MockIAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockIAuthService) ChangePassword(arg0 context.Context, arg1 *schema.ChangePasswordBodyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockIAuthServiceMockRecorder) ChangePassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockIAuthService)(nil).ChangePassword), arg0, arg1)
+}
+
+// ForgotPassword mocks base method.
+func (m *MockIAuthService) ForgotPassword(arg0 context.Context, arg1 *schema.ForgotPasswordBodyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockIAuthServiceMockRecorder) ForgotPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockIAuthService)(nil).ForgotPassword), arg0, arg1)
+}
+
+// Impersonate mocks base method.
+func (m *MockIAuthService) Impersonate(arg0 context.Context, arg1 string) (*schema.ImpersonationTokenInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonate", arg0, arg1)
+	ret0, _ := ret[0].(*schema.ImpersonationTokenInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Impersonate indicates an expected call of Impersonate.
+func (mr *MockIAuthServiceMockRecorder) Impersonate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonate", reflect.TypeOf((*MockIAuthService)(nil).Impersonate), arg0, arg1)
+}
+
 // Login mocks base method.
 func (m *MockIAuthService) Login(arg0 context.Context, arg1 *schema.LoginBodyParams) (*schema.UserTokenInfo, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +93,21 @@ func (mr *MockIAuthServiceMockRecorder) Login(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockIAuthService)(nil).Login), arg0, arg1)
 }
 
+// LoginMFA mocks base method.
+func (m *MockIAuthService) LoginMFA(arg0 context.Context, arg1 *schema.LoginMFABodyParams) (*schema.UserTokenInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginMFA", arg0, arg1)
+	ret0, _ := ret[0].(*schema.UserTokenInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginMFA indicates an expected call of LoginMFA.
+func (mr *MockIAuthServiceMockRecorder) LoginMFA(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginMFA", reflect.TypeOf((*MockIAuthService)(nil).LoginMFA), arg0, arg1)
+}
+
 // Logout mocks base method.
 func (m *MockIAuthService) Logout(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -93,3 +151,73 @@ func (mr *MockIAuthServiceMockRecorder) Register(arg0, arg1 interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockIAuthService)(nil).Register), arg0, arg1)
 }
+
+// ResetPassword mocks base method.
+func (m *MockIAuthService) ResetPassword(arg0 context.Context, arg1 *schema.ResetPasswordBodyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockIAuthServiceMockRecorder) ResetPassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockIAuthService)(nil).ResetPassword), arg0, arg1)
+}
+
+// SendEmailVerification mocks base method.
+func (m *MockIAuthService) SendEmailVerification(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailVerification", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmailVerification indicates an expected call of SendEmailVerification.
+func (mr *MockIAuthServiceMockRecorder) SendEmailVerification(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailVerification", reflect.TypeOf((*MockIAuthService)(nil).SendEmailVerification), arg0)
+}
+
+// SignOutUser mocks base method.
+func (m *MockIAuthService) SignOutUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignOutUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignOutUser indicates an expected call of SignOutUser.
+func (mr *MockIAuthServiceMockRecorder) SignOutUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignOutUser", reflect.TypeOf((*MockIAuthService)(nil).SignOutUser), arg0, arg1)
+}
+
+// UnlockUser mocks base method.
+func (m *MockIAuthService) UnlockUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockIAuthServiceMockRecorder) UnlockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockIAuthService)(nil).UnlockUser), arg0, arg1)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockIAuthService) VerifyEmail(arg0 context.Context, arg1 *schema.VerifyEmailBodyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockIAuthServiceMockRecorder) VerifyEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockIAuthService)(nil).VerifyEmail), arg0, arg1)
+}
@@ -9,6 +9,8 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/shasw94/projX/app/models"
+	scopes "github.com/shasw94/projX/app/repositories/scopes"
+	schema "github.com/shasw94/projX/app/schema"
 )
 
 // MockIRoleRepository is a mock of IRoleRepository interface.
@@ -34,6 +36,48 @@ func (m *MockIRoleRepository) EXPECT() *MockIRoleRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddParents mocks base method.
+func (m *MockIRoleRepository) AddParents(arg0 *models.Role, arg1 *schema.Roles) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddParents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddParents indicates an expected call of AddParents.
+func (mr *MockIRoleRepositoryMockRecorder) AddParents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddParents", reflect.TypeOf((*MockIRoleRepository)(nil).AddParents), arg0, arg1)
+}
+
+// AddPermissions mocks base method.
+func (m *MockIRoleRepository) AddPermissions(arg0 *models.Role, arg1 *schema.Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPermissions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPermissions indicates an expected call of AddPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) AddPermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).AddPermissions), arg0, arg1)
+}
+
+// ClearPermissions mocks base method.
+func (m *MockIRoleRepository) ClearPermissions(arg0 *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearPermissions", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearPermissions indicates an expected call of ClearPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) ClearPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).ClearPermissions), arg0)
+}
+
 // Create mocks base method.
 func (m *MockIRoleRepository) Create(arg0 *models.Role) error {
 	m.ctrl.T.Helper()
@@ -48,6 +92,34 @@ func (mr *MockIRoleRepositoryMockRecorder) Create(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIRoleRepository)(nil).Create), arg0)
 }
 
+// Delete mocks base method.
+func (m *MockIRoleRepository) Delete(arg0 *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIRoleRepositoryMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIRoleRepository)(nil).Delete), arg0)
+}
+
+// FirstOrCreate mocks base method.
+func (m *MockIRoleRepository) FirstOrCreate(arg0 *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FirstOrCreate", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FirstOrCreate indicates an expected call of FirstOrCreate.
+func (mr *MockIRoleRepositoryMockRecorder) FirstOrCreate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FirstOrCreate", reflect.TypeOf((*MockIRoleRepository)(nil).FirstOrCreate), arg0)
+}
+
 // GetByName mocks base method.
 func (m *MockIRoleRepository) GetByName(arg0 string) (*models.Role, error) {
 	m.ctrl.T.Helper()
@@ -62,3 +134,287 @@ func (mr *MockIRoleRepositoryMockRecorder) GetByName(arg0 interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockIRoleRepository)(nil).GetByName), arg0)
 }
+
+// GetParentIDsOfRoles mocks base method.
+func (m *MockIRoleRepository) GetParentIDsOfRoles(arg0 []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParentIDsOfRoles", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParentIDsOfRoles indicates an expected call of GetParentIDsOfRoles.
+func (mr *MockIRoleRepositoryMockRecorder) GetParentIDsOfRoles(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParentIDsOfRoles", reflect.TypeOf((*MockIRoleRepository)(nil).GetParentIDsOfRoles), arg0)
+}
+
+// GetRoleByGuardName mocks base method.
+func (m *MockIRoleRepository) GetRoleByGuardName(arg0 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleByGuardName", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleByGuardName indicates an expected call of GetRoleByGuardName.
+func (mr *MockIRoleRepositoryMockRecorder) GetRoleByGuardName(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleByGuardName", reflect.TypeOf((*MockIRoleRepository)(nil).GetRoleByGuardName), arg0)
+}
+
+// GetRoleByGuardNameWithPermissions mocks base method.
+func (m *MockIRoleRepository) GetRoleByGuardNameWithPermissions(arg0 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleByGuardNameWithPermissions", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleByGuardNameWithPermissions indicates an expected call of GetRoleByGuardNameWithPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) GetRoleByGuardNameWithPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleByGuardNameWithPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).GetRoleByGuardNameWithPermissions), arg0)
+}
+
+// GetRoleByID mocks base method.
+func (m *MockIRoleRepository) GetRoleByID(arg0 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleByID", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleByID indicates an expected call of GetRoleByID.
+func (mr *MockIRoleRepositoryMockRecorder) GetRoleByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleByID", reflect.TypeOf((*MockIRoleRepository)(nil).GetRoleByID), arg0)
+}
+
+// GetRoleByIDWithPermissions mocks base method.
+func (m *MockIRoleRepository) GetRoleByIDWithPermissions(arg0 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleByIDWithPermissions", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleByIDWithPermissions indicates an expected call of GetRoleByIDWithPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) GetRoleByIDWithPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleByIDWithPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).GetRoleByIDWithPermissions), arg0)
+}
+
+// GetRoleIDs mocks base method.
+func (m *MockIRoleRepository) GetRoleIDs(arg0 scopes.GormPager) ([]string, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRoleIDs indicates an expected call of GetRoleIDs.
+func (mr *MockIRoleRepositoryMockRecorder) GetRoleIDs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleIDs", reflect.TypeOf((*MockIRoleRepository)(nil).GetRoleIDs), arg0)
+}
+
+// GetRoleIDsOfPermission mocks base method.
+func (m *MockIRoleRepository) GetRoleIDsOfPermission(arg0 string, arg1 scopes.GormPager) ([]string, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleIDsOfPermission", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRoleIDsOfPermission indicates an expected call of GetRoleIDsOfPermission.
+func (mr *MockIRoleRepositoryMockRecorder) GetRoleIDsOfPermission(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleIDsOfPermission", reflect.TypeOf((*MockIRoleRepository)(nil).GetRoleIDsOfPermission), arg0, arg1)
+}
+
+// GetRoleIDsOfUser mocks base method.
+func (m *MockIRoleRepository) GetRoleIDsOfUser(arg0 string, arg1 scopes.GormPager) ([]string, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleIDsOfUser", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRoleIDsOfUser indicates an expected call of GetRoleIDsOfUser.
+func (mr *MockIRoleRepositoryMockRecorder) GetRoleIDsOfUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleIDsOfUser", reflect.TypeOf((*MockIRoleRepository)(nil).GetRoleIDsOfUser), arg0, arg1)
+}
+
+// GetRoles mocks base method.
+func (m *MockIRoleRepository) GetRoles(arg0 []string) (*schema.Roles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoles", arg0)
+	ret0, _ := ret[0].(*schema.Roles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles.
+func (mr *MockIRoleRepositoryMockRecorder) GetRoles(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockIRoleRepository)(nil).GetRoles), arg0)
+}
+
+// GetRolesByGuardNames mocks base method.
+func (m *MockIRoleRepository) GetRolesByGuardNames(arg0 []string) (*schema.Roles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRolesByGuardNames", arg0)
+	ret0, _ := ret[0].(*schema.Roles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRolesByGuardNames indicates an expected call of GetRolesByGuardNames.
+func (mr *MockIRoleRepositoryMockRecorder) GetRolesByGuardNames(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRolesByGuardNames", reflect.TypeOf((*MockIRoleRepository)(nil).GetRolesByGuardNames), arg0)
+}
+
+// GetRolesByGuardNamesWithPermissions mocks base method.
+func (m *MockIRoleRepository) GetRolesByGuardNamesWithPermissions(arg0 []string) (*schema.Roles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRolesByGuardNamesWithPermissions", arg0)
+	ret0, _ := ret[0].(*schema.Roles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRolesByGuardNamesWithPermissions indicates an expected call of GetRolesByGuardNamesWithPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) GetRolesByGuardNamesWithPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRolesByGuardNamesWithPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).GetRolesByGuardNamesWithPermissions), arg0)
+}
+
+// GetRolesWithPermissions mocks base method.
+func (m *MockIRoleRepository) GetRolesWithPermissions(arg0 []string) (*schema.Roles, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRolesWithPermissions", arg0)
+	ret0, _ := ret[0].(*schema.Roles)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRolesWithPermissions indicates an expected call of GetRolesWithPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) GetRolesWithPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRolesWithPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).GetRolesWithPermissions), arg0)
+}
+
+// HasAllPermissions mocks base method.
+func (m *MockIRoleRepository) HasAllPermissions(arg0 *schema.Roles, arg1 *schema.Permission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasAllPermissions", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasAllPermissions indicates an expected call of HasAllPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) HasAllPermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAllPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).HasAllPermissions), arg0, arg1)
+}
+
+// HasAnyPermissions mocks base method.
+func (m *MockIRoleRepository) HasAnyPermissions(arg0 *schema.Roles, arg1 *schema.Permission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasAnyPermissions", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasAnyPermissions indicates an expected call of HasAnyPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) HasAnyPermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAnyPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).HasAnyPermissions), arg0, arg1)
+}
+
+// HasPermission mocks base method.
+func (m *MockIRoleRepository) HasPermission(arg0 *schema.Roles, arg1 *models.Permission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPermission", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPermission indicates an expected call of HasPermission.
+func (mr *MockIRoleRepositoryMockRecorder) HasPermission(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPermission", reflect.TypeOf((*MockIRoleRepository)(nil).HasPermission), arg0, arg1)
+}
+
+// RemoveParents mocks base method.
+func (m *MockIRoleRepository) RemoveParents(arg0 *models.Role, arg1 *schema.Roles) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveParents", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveParents indicates an expected call of RemoveParents.
+func (mr *MockIRoleRepositoryMockRecorder) RemoveParents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveParents", reflect.TypeOf((*MockIRoleRepository)(nil).RemoveParents), arg0, arg1)
+}
+
+// RemovePermissions mocks base method.
+func (m *MockIRoleRepository) RemovePermissions(arg0 *models.Role, arg1 *schema.Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePermissions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePermissions indicates an expected call of RemovePermissions.
+func (mr *MockIRoleRepositoryMockRecorder) RemovePermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePermissions", reflect.TypeOf((*MockIRoleRepository)(nil).RemovePermissions), arg0, arg1)
+}
+
+// ReplacePermissions mocks base method.
+func (m *MockIRoleRepository) ReplacePermissions(arg0 *models.Role, arg1 *schema.Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePermissions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplacePermissions indicates an expected call of ReplacePermissions.
+func (mr *MockIRoleRepositoryMockRecorder) ReplacePermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePermissions", reflect.TypeOf((*MockIRoleRepository)(nil).ReplacePermissions), arg0, arg1)
+}
+
+// Updates mocks base method.
+func (m *MockIRoleRepository) Updates(arg0 *models.Role, arg1 map[string]interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Updates", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Updates indicates an expected call of Updates.
+func (mr *MockIRoleRepositoryMockRecorder) Updates(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Updates", reflect.TypeOf((*MockIRoleRepository)(nil).Updates), arg0, arg1)
+}
@@ -6,9 +6,11 @@ package mocks
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/shasw94/projX/app/models"
+	pivot "github.com/shasw94/projX/app/models/pivot"
 	schema "github.com/shasw94/projX/app/schema"
 )
 
@@ -35,6 +37,77 @@ func (m *MockIUserRepository) EXPECT() *MockIUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddPermissions mocks base method.
+func (m *MockIUserRepository) AddPermissions(arg0 string, arg1 schema.Permission, arg2 *schema.Grant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPermissions", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPermissions indicates an expected call of AddPermissions.
+func (mr *MockIUserRepositoryMockRecorder) AddPermissions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPermissions", reflect.TypeOf((*MockIUserRepository)(nil).AddPermissions), arg0, arg1, arg2)
+}
+
+// AddRoles mocks base method.
+func (m *MockIUserRepository) AddRoles(arg0 string, arg1 schema.Roles, arg2 *schema.Grant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRoles", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRoles indicates an expected call of AddRoles.
+func (mr *MockIUserRepositoryMockRecorder) AddRoles(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRoles", reflect.TypeOf((*MockIUserRepository)(nil).AddRoles), arg0, arg1, arg2)
+}
+
+// ClearPermissions mocks base method.
+func (m *MockIUserRepository) ClearPermissions(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearPermissions", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearPermissions indicates an expected call of ClearPermissions.
+func (mr *MockIUserRepositoryMockRecorder) ClearPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearPermissions", reflect.TypeOf((*MockIUserRepository)(nil).ClearPermissions), arg0)
+}
+
+// ClearRoles mocks base method.
+func (m *MockIUserRepository) ClearRoles(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearRoles", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearRoles indicates an expected call of ClearRoles.
+func (mr *MockIUserRepositoryMockRecorder) ClearRoles(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearRoles", reflect.TypeOf((*MockIUserRepository)(nil).ClearRoles), arg0)
+}
+
+// CountStartedGrants mocks base method.
+func (m *MockIUserRepository) CountStartedGrants(arg0, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountStartedGrants", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountStartedGrants indicates an expected call of CountStartedGrants.
+func (mr *MockIUserRepositoryMockRecorder) CountStartedGrants(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountStartedGrants", reflect.TypeOf((*MockIUserRepository)(nil).CountStartedGrants), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockIUserRepository) Create(arg0 *models.User) error {
 	m.ctrl.T.Helper()
@@ -49,6 +122,37 @@ func (mr *MockIUserRepositoryMockRecorder) Create(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIUserRepository)(nil).Create), arg0)
 }
 
+// DeleteExpiredGrants mocks base method.
+func (m *MockIUserRepository) DeleteExpiredGrants(arg0 time.Time) ([]pivot.UserRole, []pivot.UserPermission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredGrants", arg0)
+	ret0, _ := ret[0].([]pivot.UserRole)
+	ret1, _ := ret[1].([]pivot.UserPermission)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DeleteExpiredGrants indicates an expected call of DeleteExpiredGrants.
+func (mr *MockIUserRepositoryMockRecorder) DeleteExpiredGrants(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredGrants", reflect.TypeOf((*MockIUserRepository)(nil).DeleteExpiredGrants), arg0)
+}
+
+// GetByEmail mocks base method.
+func (m *MockIUserRepository) GetByEmail(arg0 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", arg0)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockIUserRepositoryMockRecorder) GetByEmail(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockIUserRepository)(nil).GetByEmail), arg0)
+}
+
 // GetByID mocks base method.
 func (m *MockIUserRepository) GetByID(arg0 string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -64,19 +168,110 @@ func (mr *MockIUserRepositoryMockRecorder) GetByID(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockIUserRepository)(nil).GetByID), arg0)
 }
 
-// GetUserByToken mocks base method.
-func (m *MockIUserRepository) GetUserByToken(arg0 string) (*models.User, error) {
+// GetInactiveGrants mocks base method.
+func (m *MockIUserRepository) GetInactiveGrants(arg0 string, arg1 time.Time) ([]pivot.UserRole, []pivot.UserPermission, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByToken", arg0)
-	ret0, _ := ret[0].(*models.User)
+	ret := m.ctrl.Call(m, "GetInactiveGrants", arg0, arg1)
+	ret0, _ := ret[0].([]pivot.UserRole)
+	ret1, _ := ret[1].([]pivot.UserPermission)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetInactiveGrants indicates an expected call of GetInactiveGrants.
+func (mr *MockIUserRepositoryMockRecorder) GetInactiveGrants(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInactiveGrants", reflect.TypeOf((*MockIUserRepository)(nil).GetInactiveGrants), arg0, arg1)
+}
+
+// HasAllDirectPermissions mocks base method.
+func (m *MockIUserRepository) HasAllDirectPermissions(arg0 string, arg1 schema.Permission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasAllDirectPermissions", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasAllDirectPermissions indicates an expected call of HasAllDirectPermissions.
+func (mr *MockIUserRepositoryMockRecorder) HasAllDirectPermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAllDirectPermissions", reflect.TypeOf((*MockIUserRepository)(nil).HasAllDirectPermissions), arg0, arg1)
+}
+
+// HasAllRoles mocks base method.
+func (m *MockIUserRepository) HasAllRoles(arg0 string, arg1 schema.Roles) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasAllRoles", arg0, arg1)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByToken indicates an expected call of GetUserByToken.
-func (mr *MockIUserRepositoryMockRecorder) GetUserByToken(arg0 interface{}) *gomock.Call {
+// HasAllRoles indicates an expected call of HasAllRoles.
+func (mr *MockIUserRepositoryMockRecorder) HasAllRoles(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByToken", reflect.TypeOf((*MockIUserRepository)(nil).GetUserByToken), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAllRoles", reflect.TypeOf((*MockIUserRepository)(nil).HasAllRoles), arg0, arg1)
+}
+
+// HasAnyDirectPermissions mocks base method.
+func (m *MockIUserRepository) HasAnyDirectPermissions(arg0 string, arg1 schema.Permission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasAnyDirectPermissions", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasAnyDirectPermissions indicates an expected call of HasAnyDirectPermissions.
+func (mr *MockIUserRepositoryMockRecorder) HasAnyDirectPermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAnyDirectPermissions", reflect.TypeOf((*MockIUserRepository)(nil).HasAnyDirectPermissions), arg0, arg1)
+}
+
+// HasAnyRoles mocks base method.
+func (m *MockIUserRepository) HasAnyRoles(arg0 string, arg1 schema.Roles) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasAnyRoles", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasAnyRoles indicates an expected call of HasAnyRoles.
+func (mr *MockIUserRepositoryMockRecorder) HasAnyRoles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAnyRoles", reflect.TypeOf((*MockIUserRepository)(nil).HasAnyRoles), arg0, arg1)
+}
+
+// HasDirectPermission mocks base method.
+func (m *MockIUserRepository) HasDirectPermission(arg0 string, arg1 models.Permission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasDirectPermission", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasDirectPermission indicates an expected call of HasDirectPermission.
+func (mr *MockIUserRepositoryMockRecorder) HasDirectPermission(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDirectPermission", reflect.TypeOf((*MockIUserRepository)(nil).HasDirectPermission), arg0, arg1)
+}
+
+// HasRole mocks base method.
+func (m *MockIUserRepository) HasRole(arg0 string, arg1 models.Role) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasRole", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasRole indicates an expected call of HasRole.
+func (mr *MockIUserRepositoryMockRecorder) HasRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasRole", reflect.TypeOf((*MockIUserRepository)(nil).HasRole), arg0, arg1)
 }
 
 // List mocks base method.
@@ -109,19 +304,60 @@ func (mr *MockIUserRepositoryMockRecorder) Login(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockIUserRepository)(nil).Login), arg0)
 }
 
-// RemoveToken mocks base method.
-func (m *MockIUserRepository) RemoveToken(arg0 string) (*models.User, error) {
+// RemovePermissions mocks base method.
+func (m *MockIUserRepository) RemovePermissions(arg0 string, arg1 schema.Permission) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RemoveToken", arg0)
-	ret0, _ := ret[0].(*models.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "RemovePermissions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// RemoveToken indicates an expected call of RemoveToken.
-func (mr *MockIUserRepositoryMockRecorder) RemoveToken(arg0 interface{}) *gomock.Call {
+// RemovePermissions indicates an expected call of RemovePermissions.
+func (mr *MockIUserRepositoryMockRecorder) RemovePermissions(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveToken", reflect.TypeOf((*MockIUserRepository)(nil).RemoveToken), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePermissions", reflect.TypeOf((*MockIUserRepository)(nil).RemovePermissions), arg0, arg1)
+}
+
+// RemoveRoles mocks base method.
+func (m *MockIUserRepository) RemoveRoles(arg0 string, arg1 schema.Roles) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRoles", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRoles indicates an expected call of RemoveRoles.
+func (mr *MockIUserRepositoryMockRecorder) RemoveRoles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRoles", reflect.TypeOf((*MockIUserRepository)(nil).RemoveRoles), arg0, arg1)
+}
+
+// ReplacePermissions mocks base method.
+func (m *MockIUserRepository) ReplacePermissions(arg0 string, arg1 schema.Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplacePermissions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplacePermissions indicates an expected call of ReplacePermissions.
+func (mr *MockIUserRepositoryMockRecorder) ReplacePermissions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplacePermissions", reflect.TypeOf((*MockIUserRepository)(nil).ReplacePermissions), arg0, arg1)
+}
+
+// ReplaceRoles mocks base method.
+func (m *MockIUserRepository) ReplaceRoles(arg0 string, arg1 schema.Roles) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRoles", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRoles indicates an expected call of ReplaceRoles.
+func (mr *MockIUserRepositoryMockRecorder) ReplaceRoles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRoles", reflect.TypeOf((*MockIUserRepository)(nil).ReplaceRoles), arg0, arg1)
 }
 
 // Update mocks base method.
@@ -138,3 +374,31 @@ func (mr *MockIUserRepositoryMockRecorder) Update(arg0, arg1 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIUserRepository)(nil).Update), arg0, arg1)
 }
+
+// UpdatePassword mocks base method.
+func (m *MockIUserRepository) UpdatePassword(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockIUserRepositoryMockRecorder) UpdatePassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockIUserRepository)(nil).UpdatePassword), arg0, arg1)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockIUserRepository) VerifyEmail(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockIUserRepositoryMockRecorder) VerifyEmail(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockIUserRepository)(nil).VerifyEmail), arg0)
+}
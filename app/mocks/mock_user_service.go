@@ -65,3 +65,18 @@ func (mr *MockIUserServiceMockRecorder) List(arg0, arg1 interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIUserService)(nil).List), arg0, arg1)
 }
+
+// LoadAttributes mocks base method.
+func (m *MockIUserService) LoadAttributes(arg0 context.Context, arg1 string) (*schema.ResourceAttributes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadAttributes", arg0, arg1)
+	ret0, _ := ret[0].(*schema.ResourceAttributes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadAttributes indicates an expected call of LoadAttributes.
+func (mr *MockIUserServiceMockRecorder) LoadAttributes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadAttributes", reflect.TypeOf((*MockIUserService)(nil).LoadAttributes), arg0, arg1)
+}
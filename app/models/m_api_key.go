@@ -0,0 +1,36 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey api key of service account. The key is only stored as hash and looked up by its prefix,
+// permissions are space separated guard names and a subset of the permissions of the owner
+type APIKey struct {
+	Model       `json:"inline"`
+	UserID      string     `json:"user_id" gorm:"size:36;not null;index"`
+	Name        string     `json:"name" gorm:"size:255;not null"`
+	Prefix      string     `json:"prefix" gorm:"size:16;not null;uniqueIndex"`
+	KeyHash     string     `json:"-" gorm:"size:64;not null"`
+	Permissions string     `json:"permissions" gorm:"type:text"`
+	CreatedBy   string     `json:"created_by" gorm:"size:36"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at" gorm:"index"`
+}
+
+// IsActive the key is neither revoked nor expired
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt))
+}
+
+// PermissionList granted permission guard names
+func (k *APIKey) PermissionList() []string {
+	return strings.Fields(k.Permissions)
+}
+
+// TableName of api key
+func (APIKey) TableName() string {
+	return "api_keys"
+}
@@ -0,0 +1,20 @@
+package models
+
+// Audit events
+const (
+	AuditRefreshTokenReuse = "refresh_token.reuse_detected"
+	AuditLoginUnlocked     = "login.unlocked"
+	AuditImpersonation     = "impersonation.started"
+	AuditImpersonatedCall  = "impersonation.request"
+	AuditRoleExpired       = "role.expired"
+	AuditPermissionExpired = "permission.expired"
+)
+
+// AuditEvent security relevant event
+type AuditEvent struct {
+	Model   `json:"inline"`
+	Event   string `json:"event" gorm:"size:100;not null;index"`
+	UserID  string `json:"user_id" gorm:"size:36;index"`
+	ActorID string `json:"actor_id" gorm:"size:36;index"`
+	Detail  string `json:"detail" gorm:"type:text"`
+}
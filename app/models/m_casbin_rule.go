@@ -0,0 +1,38 @@
+package models
+
+// CasbinRule policy line of casbin, ptype is p for policies and g for role links
+type CasbinRule struct {
+	ID    uint   `gorm:"primaryKey;autoIncrement"`
+	Ptype string `gorm:"size:100;not null;index"`
+	V0    string `gorm:"size:255;not null;default:''"`
+	V1    string `gorm:"size:255;not null;default:''"`
+	V2    string `gorm:"size:255;not null;default:''"`
+	V3    string `gorm:"size:255;not null;default:''"`
+	V4    string `gorm:"size:255;not null;default:''"`
+	V5    string `gorm:"size:255;not null;default:''"`
+}
+
+func (CasbinRule) TableName() string {
+	return "casbin_rule"
+}
+
+// NewCasbinRule casbin rule of the policy type and values
+func NewCasbinRule(ptype string, values []string) CasbinRule {
+	rule := CasbinRule{Ptype: ptype}
+	fields := []*string{&rule.V0, &rule.V1, &rule.V2, &rule.V3, &rule.V4, &rule.V5}
+	for i, value := range values {
+		if i < len(fields) {
+			*fields[i] = value
+		}
+	}
+	return rule
+}
+
+// Line policy type followed by the values, trailing empty values are dropped
+func (r CasbinRule) Line() []string {
+	line := []string{r.Ptype, r.V0, r.V1, r.V2, r.V3, r.V4, r.V5}
+	for len(line) > 1 && line[len(line)-1] == "" {
+		line = line[:len(line)-1]
+	}
+	return line
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// MFA audit events
+const (
+	AuditMFAEnabled = "mfa.enabled"
+	AuditMFAReset   = "mfa.reset"
+)
+
+// UserMFA totp secret of user, enabled once the first code is confirmed
+type UserMFA struct {
+	Model        `json:"inline"`
+	UserID       string     `json:"user_id" gorm:"size:36;not null;uniqueIndex"`
+	Secret       string     `json:"-" gorm:"size:64;not null"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `json:"-" gorm:"not null;default:0"`
+}
+
+// IsEnabled the secret has been confirmed
+func (m *UserMFA) IsEnabled() bool {
+	return m.ConfirmedAt != nil
+}
+
+// MFARecoveryCode one-time recovery code of user, only hash of the code is stored
+type MFARecoveryCode struct {
+	Model    `json:"inline"`
+	UserID   string     `json:"user_id" gorm:"size:36;not null;index"`
+	CodeHash string     `json:"-" gorm:"size:64;not null;index"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+// MFAChallenge pending login waiting for the second factor, only hash of the token is stored
+type MFAChallenge struct {
+	Model     `json:"inline"`
+	UserID    string     `json:"user_id" gorm:"size:36;not null;index"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// IsActive the challenge is neither used nor expired
+func (c *MFAChallenge) IsActive() bool {
+	return c.UsedAt == nil && c.ExpiresAt.After(time.Now())
+}
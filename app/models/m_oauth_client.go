@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Grant types of oauth client
+const (
+	OAuthGrantClientCredentials = "client_credentials"
+	OAuthGrantAuthorizationCode = "authorization_code"
+)
+
+// OAuthClient registered oauth client. Redirect uris, scopes and grant types are space separated.
+// Public clients have no secret and must use authorization code with PKCE
+type OAuthClient struct {
+	Model        `json:"inline"`
+	Name         string     `json:"name" gorm:"size:255;not null"`
+	SecretHash   string     `json:"-" gorm:"size:64"`
+	RedirectURIs string     `json:"redirect_uris" gorm:"type:text"`
+	Scopes       string     `json:"scopes" gorm:"type:text"`
+	GrantTypes   string     `json:"grant_types" gorm:"size:255;not null"`
+	Public       bool       `json:"public" gorm:"not null;default:false"`
+	CreatedBy    string     `json:"created_by" gorm:"size:36"`
+	RevokedAt    *time.Time `json:"revoked_at" gorm:"index"`
+}
+
+// IsRevoked the client has been revoked
+func (c *OAuthClient) IsRevoked() bool {
+	return c.RevokedAt != nil
+}
+
+// RedirectURIList registered redirect uris
+func (c *OAuthClient) RedirectURIList() []string {
+	return strings.Fields(c.RedirectURIs)
+}
+
+// ScopeList allowed scopes
+func (c *OAuthClient) ScopeList() []string {
+	return strings.Fields(c.Scopes)
+}
+
+// GrantTypeList allowed grant types
+func (c *OAuthClient) GrantTypeList() []string {
+	return strings.Fields(c.GrantTypes)
+}
+
+// HasGrantType the client is allowed to use the grant type
+func (c *OAuthClient) HasGrantType(grantType string) bool {
+	for _, g := range c.GrantTypeList() {
+		if g == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRedirectURI the redirect uri is registered, compared by exact match
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	for _, u := range c.RedirectURIList() {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName of oauth client
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// OAuthAuthorizationCode authorization code issued to client on behalf of user, only hash of the code is stored
+type OAuthAuthorizationCode struct {
+	Model               `json:"inline"`
+	CodeHash            string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ClientID            string     `json:"client_id" gorm:"size:36;not null;index"`
+	UserID              string     `json:"user_id" gorm:"size:36;not null;index"`
+	RedirectURI         string     `json:"redirect_uri" gorm:"type:text"`
+	RedirectURIExplicit bool       `json:"redirect_uri_explicit" gorm:"not null;default:false"`
+	Scope               string     `json:"scope" gorm:"type:text"`
+	CodeChallenge       string     `json:"-" gorm:"size:128;not null"`
+	CodeChallengeMethod string     `json:"-" gorm:"size:10;not null"`
+	ExpiresAt           time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt              *time.Time `json:"used_at"`
+}
+
+// IsExpired the code is expired
+func (c *OAuthAuthorizationCode) IsExpired() bool {
+	return c.ExpiresAt.Before(time.Now())
+}
+
+// IsUsed the code has been exchanged
+func (c *OAuthAuthorizationCode) IsUsed() bool {
+	return c.UsedAt != nil
+}
+
+// TableName of authorization code
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
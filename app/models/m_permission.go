@@ -1,8 +1,36 @@
 package models
 
+import (
+	"github.com/shasw94/projX/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	// PermissionUsersRead permission to read users through the api
+	PermissionUsersRead = "users.read"
+	// AnyAction action pattern matching every http method
+	AnyAction = "*"
+)
+
 type Permission struct {
 	Model
 	Name        string `gorm:"size:255;not null" json:"name"`
 	GuardName   string `gorm:"size:255;not null" json:"guard_name"`
 	Description string `gorm:"size:255;not null;index" json:"description"`
+	// Resource and Action are the path and method patterns granted by the permission under casbin
+	Resource string `gorm:"size:255" json:"resource,omitempty"`
+	Action   string `gorm:"size:64" json:"action,omitempty"`
+}
+
+// BeforeCreate handle before create permission
+func (p *Permission) BeforeCreate(db *gorm.DB) error {
+	err := p.Model.BeforeCreate(db)
+	if err != nil {
+		return err
+	}
+
+	if p.GuardName == "" {
+		p.GuardName = utils.Guard(p.Name)
+	}
+	return nil
 }
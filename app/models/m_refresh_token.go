@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// RefreshToken issued refresh token, ID is the jti claim of the token
+type RefreshToken struct {
+	Model      `json:"inline"`
+	UserID     string     `json:"user_id" gorm:"size:36;not null;index"`
+	FamilyID   string     `json:"family_id" gorm:"size:36;not null;index"`
+	TokenHash  string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt     *time.Time `json:"used_at"`
+	ReplacedBy string     `json:"replaced_by" gorm:"size:36"`
+	RevokedAt  *time.Time `json:"revoked_at" gorm:"index"`
+}
+
+// IsUsed the token has been exchanged for a new one
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// IsRevoked the token family has been revoked
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -1,5 +1,13 @@
 package models
 
+import (
+	"github.com/shasw94/projX/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// RoleAdmin guard name of the administrator role
+const RoleAdmin = "admin"
+
 type Role struct {
 	Model       `json:"inline"`
 	Name        string `json:"name" gorm:"unique;not null;index"`
@@ -8,4 +16,19 @@ type Role struct {
 
 	// Many to Many
 	Permissions []Permission `gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"permissions"`
+	// Parents roles whose permissions are inherited by the role
+	Parents []Role `gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"parents,omitempty"`
+}
+
+// BeforeCreate handle before create role
+func (r *Role) BeforeCreate(db *gorm.DB) error {
+	err := r.Model.BeforeCreate(db)
+	if err != nil {
+		return err
+	}
+
+	if r.GuardName == "" {
+		r.GuardName = utils.Guard(r.Name)
+	}
+	return nil
 }
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Session login session of a device, ID is the refresh token family id
+type Session struct {
+	Model       `json:"inline"`
+	UserID      string     `json:"user_id" gorm:"size:36;not null;index"`
+	DeviceLabel string     `json:"device_label" gorm:"size:255"`
+	UserAgent   string     `json:"user_agent" gorm:"size:500"`
+	IP          string     `json:"ip" gorm:"size:45"`
+	LastUsedAt  time.Time  `json:"last_used_at" gorm:"not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt   *time.Time `json:"revoked_at" gorm:"index"`
+}
+
+// IsActive the session is neither revoked nor expired
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
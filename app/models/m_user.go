@@ -3,8 +3,12 @@ package models
 import (
 	"github.com/shasw94/projX/pkg/utils"
 	"gorm.io/gorm"
+	"time"
 )
 
+// ResourceUser resource type of users in authorization rules
+const ResourceUser = "user"
+
 type User struct {
 	Model        `json:"inline"`
 	Username     string `json:"username" gorm:"unique;not null;index"`
@@ -12,10 +16,18 @@ type User struct {
 	Password     string `json:"password" gorm:"not null;index"`
 	Role         Role
 	RoleID       string `json:"role_id" gorm:"not null;index"`
-	RefreshToken string `json:"refresh_token" gorm:"size:500;index"`
 	FullName     string `json:"full_name"`
 	ProfileImage string `json:"profile_image"`
 	Mobile       string `json:"mobile" gorm:"not null;default:0"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// ServiceAccount non-interactive user authenticating by api keys only
+	ServiceAccount bool `json:"service_account" gorm:"not null;default:false"`
+}
+
+// IsEmailVerified the email address has been verified
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // BeforeCreate handle before create user
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Purposes of user token
+const (
+	UserTokenPasswordReset     = "password_reset"
+	UserTokenEmailVerification = "email_verification"
+)
+
+// UserToken single-use token sent to user by email, only hash of the token is stored
+type UserToken struct {
+	Model     `json:"inline"`
+	UserID    string     `json:"user_id" gorm:"size:36;not null;index"`
+	Purpose   string     `json:"purpose" gorm:"size:32;not null;index"`
+	Email     string     `json:"email" gorm:"size:255;not null"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// IsActive the token is neither used nor expired
+func (t *UserToken) IsActive() bool {
+	return t.UsedAt == nil && t.ExpiresAt.After(time.Now())
+}
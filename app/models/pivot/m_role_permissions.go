@@ -1,8 +1,16 @@
 package pivot
 
+import "time"
+
+// UserPermission represents the database model of direct user permissions, the grant is active
+// from StartsAt until ExpiresAt when they are set
 type UserPermission struct {
-	UserID       string `gorm:"primaryKey" json:"user_id"`
-	PermissionID string `gorm:"primaryKey" json:"permission_id"`
+	UserID       string     `gorm:"primaryKey" json:"user_id"`
+	PermissionID string     `gorm:"primaryKey" json:"permission_id"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt    *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	GrantedBy    string     `gorm:"size:36" json:"granted_by,omitempty"`
+	Reason       string     `gorm:"size:255" json:"reason,omitempty"`
 }
 
 func (UserPermission) TableName() string {
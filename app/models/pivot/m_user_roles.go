@@ -1,9 +1,16 @@
 package pivot
 
-// UserRole represents the database model of user roles relationships
+import "time"
+
+// UserRole represents the database model of user roles relationships, the grant is active
+// from StartsAt until ExpiresAt when they are set
 type UserRole struct {
-	UserID string `gorm:"primaryKey" json:"user_id"`
-	RoleID string `gorm:"primaryKey" json:"role_id"`
+	UserID    string     `gorm:"primaryKey" json:"user_id"`
+	RoleID    string     `gorm:"primaryKey" json:"role_id"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	GrantedBy string     `gorm:"size:36" json:"granted_by,omitempty"`
+	Reason    string     `gorm:"size:255" json:"reason,omitempty"`
 }
 
 func (UserRole) TableName() string {
@@ -0,0 +1,9 @@
+package passport
+
+import "go.uber.org/dig"
+
+// Inject passport
+func Inject(container *dig.Container) error {
+	_ = container.Provide(NewPassport)
+	return nil
+}
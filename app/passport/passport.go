@@ -5,47 +5,164 @@ import (
 	"github.com/shasw94/projX/app/models"
 	"github.com/shasw94/projX/app/repositories/scopes"
 	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/logger"
 	"github.com/shasw94/projX/pkg/errors"
 	"github.com/shasw94/projX/pkg/utils"
+	"time"
 )
 
 var errUnsupportedValueType = errors.New("err unsupported value type")
 
 type Passport struct {
-	roleRepo interfaces.IRoleRepository
-	userRepo interfaces.IUserRepository
-	permRepo interfaces.IPermissionRepository
+	roleRepo  interfaces.IRoleRepository
+	userRepo  interfaces.IUserRepository
+	permRepo  interfaces.IPermissionRepository
+	cache     interfaces.IPermissionCache
+	listeners []func()
 }
 
-func NewPassport(roleRepo interfaces.IRoleRepository, userRepo interfaces.IUserRepository) *Passport {
-	return &Passport{roleRepo: roleRepo, userRepo: userRepo}
+// NewPassport return new IPassport interface
+func NewPassport(roleRepo interfaces.IRoleRepository, userRepo interfaces.IUserRepository, permRepo interfaces.IPermissionRepository, cache interfaces.IPermissionCache) interfaces.IPassport {
+	return &Passport{roleRepo: roleRepo, userRepo: userRepo, permRepo: permRepo, cache: cache}
 }
 
+// OnChange register listener called after roles, permissions or their assignments change.
+// Listeners are registered at startup, they run synchronously after the change is stored
+func (p *Passport) OnChange(listener func()) {
+	p.listeners = append(p.listeners, listener)
+}
+
+// changed invalidate cached permissions and notify the listeners when the change succeeded, err is returned as is
+func (p *Passport) changed(err error) error {
+	if err != nil {
+		return err
+	}
+	if err := p.cache.Invalidate(); err != nil {
+		logger.Error("Failed to invalidate permission cache: ", err)
+	}
+	for _, listener := range p.listeners {
+		listener()
+	}
+	return nil
+}
+
+// pager gorm pager of the pagination, nil when the listing is not paginated
+func pager(pagination *utils.Pagination) scopes.GormPager {
+	if pagination == nil {
+		return nil
+	}
+	return &scopes.GormPagination{Pagination: pagination.Get()}
+}
+
+// checkUser make sure the user exists before changing its roles or permissions
+func (p *Passport) checkUser(userID string) error {
+	if _, err := p.userRepo.GetByID(userID); err != nil {
+		return errors.ErrorNotExistUser.New()
+	}
+	return nil
+}
+
+// checkGrant make sure the grant ends after it starts and is not already expired
+func checkGrant(grant *schema.Grant) error {
+	if grant == nil || grant.ExpiresAt == nil {
+		return nil
+	}
+	if !grant.ExpiresAt.After(time.Now()) {
+		return errors.InvalidParams.Newm("expires_at must be in the future")
+	}
+	if grant.StartsAt != nil && !grant.ExpiresAt.After(*grant.StartsAt) {
+		return errors.InvalidParams.Newm("expires_at must be after starts_at")
+	}
+	return nil
+}
+
+// SweepGrants delete the role and permission assignments expired at now.
+// Listeners are notified when assignments expired or started after since, so caches and policies follow the grants
+// @param time.Time
+// @param time.Time
+// @return *schema.ExpiredGrants, error
+func (p *Passport) SweepGrants(since, now time.Time) (*schema.ExpiredGrants, error) {
+	roles, permissions, err := p.userRepo.DeleteExpiredGrants(now)
+	if err != nil {
+		return nil, err
+	}
+	expired := &schema.ExpiredGrants{Roles: roles, Permissions: permissions}
+
+	started, err := p.userRepo.CountStartedGrants(since, now)
+	if err != nil {
+		return expired, err
+	}
+	if expired.Len() > 0 || started > 0 {
+		return expired, p.changed(nil)
+	}
+	return expired, nil
+}
+
+// roleIDsOfUser ids of the roles assigned to the user, including the primary role of the user
+func (p *Passport) roleIDsOfUser(userID string) ([]string, error) {
+	roleIDs, _, err := p.roleRepo.GetRoleIDsOfUser(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if user, err := p.userRepo.GetByID(userID); err == nil && user.RoleID != "" {
+		roleIDs = append(roleIDs, user.RoleID)
+	}
+	return utils.RemoveDuplicateValues(roleIDs), nil
+}
+
+// findRole get role by name, falls back to id
+func (p *Passport) findRole(r string, withPermissions bool) (*models.Role, error) {
+	var role *models.Role
+	var err error
+	if withPermissions {
+		role, err = p.roleRepo.GetRoleByGuardNameWithPermissions(utils.Guard(r))
+	} else {
+		role, err = p.roleRepo.GetRoleByGuardName(utils.Guard(r))
+	}
+	if err == nil {
+		return role, nil
+	}
+
+	if withPermissions {
+		role, err = p.roleRepo.GetRoleByIDWithPermissions(r)
+	} else {
+		role, err = p.roleRepo.GetRoleByID(r)
+	}
+	if err != nil {
+		return nil, errors.ErrorNotExistRole.New()
+	}
+	return role, nil
+}
+
+// GetRole fetch role according to the role name or id.
+// If the first parameter is an array, the first element of the array is used.
+// @param interface{}
+// @param bool
+// @return *models.Role, error
 func (p *Passport) GetRole(r interface{}, withPermissions bool) (*models.Role, error) {
 	if utils.IsArray(r) {
-		//var roles []models.Role
 		roles, err := p.GetRoles(r, withPermissions)
 		if err != nil {
-			return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+			return nil, err
 		}
-		if len(*roles) > 0 {
-			p := *roles
-			role := p[0]
+		if roles.Len() > 0 {
+			role := (*roles)[0]
 			return &role, nil
 		}
-		return nil, errors.ErrorDatabaseGet.Newm("No roles found")
+		return nil, errors.ErrorNotExistRole.New()
 	}
 
 	if utils.IsString(r) {
-		if withPermissions {
-			return p.roleRepo.GetRoleByGuardNameWithPermissions(utils.Guard(r.(string)))
-		}
-		return p.roleRepo.GetRoleByGuardName(utils.Guard(r.(string)))
+		return p.findRole(r.(string), withPermissions)
 	}
 
-	return nil, errors.ErrorExistRole.Newm("unsupported value type")
+	return nil, errUnsupportedValueType
 }
 
+// GetRoles fetch roles according to the role names or ids, all of them must exist.
+// @param interface{}
+// @param bool
+// @return *schema.Roles, error
 func (p *Passport) GetRoles(r interface{}, withPermissions bool) (*schema.Roles, error) {
 	if !utils.IsArray(r) {
 		role, err := p.GetRole(r, withPermissions)
@@ -56,46 +173,119 @@ func (p *Passport) GetRoles(r interface{}, withPermissions bool) (*schema.Roles,
 		return &roles, nil
 	}
 
-	if utils.IsStringArray(r) {
-		if withPermissions {
-			return p.roleRepo.GetRolesWithPermissions(r.([]string))
+	if !utils.IsStringArray(r) {
+		return nil, errUnsupportedValueType
+	}
+
+	values := utils.RemoveDuplicateValues(r.([]string))
+	var byID, byName *schema.Roles
+	var err error
+	if withPermissions {
+		byID, err = p.roleRepo.GetRolesWithPermissions(values)
+	} else {
+		byID, err = p.roleRepo.GetRoles(values)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if byID.Len() == int64(len(values)) {
+		return byID, nil
+	}
+
+	if withPermissions {
+		byName, err = p.roleRepo.GetRolesByGuardNamesWithPermissions(utils.GuardArray(values))
+	} else {
+		byName, err = p.roleRepo.GetRolesByGuardNames(utils.GuardArray(values))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	roles := *byID
+	for _, role := range *byName {
+		if !utils.InArray(role.ID, roles.IDs()) {
+			roles = append(roles, role)
+		}
+	}
+	for _, value := range values {
+		if !utils.InArray(value, roles.IDs()) && !utils.InArray(utils.Guard(value), roles.GuardNames()) {
+			return nil, errors.ErrorNotExistRole.New()
 		}
-		return p.roleRepo.GetRoles(r.([]string))
 	}
-	return nil, errors.ErrorExistRole.Newm("unsupported value type")
+	return &roles, nil
 }
 
-func (p *Passport) GetAllRoles(option schema.RoleOption) (roles *schema.Roles, totalCount int64, err error) {
+// GetAllRoles fetch all the roles. (with pagination option)
+// @param *schema.RoleOption
+// @return *schema.Roles, int64, error
+func (p *Passport) GetAllRoles(option *schema.RoleOption) (roles *schema.Roles, totalCount int64, err error) {
 	var roleIDs []string
-	if option.Pagination == nil {
-		roleIDs, totalCount, err = p.roleRepo.GetRoleIDs(nil)
-	} else {
-		roleIDs, totalCount, err = p.roleRepo.GetRoleIDs(&scopes.GormPagination{Pagination: option.Pagination.Get()})
+	roleIDs, totalCount, err = p.roleRepo.GetRoleIDs(pager(option.Pagination))
+	if err != nil {
+		return nil, 0, err
 	}
 	roles, err = p.GetRoles(roleIDs, option.WithPermissions)
 	return
 }
 
-func (p *Passport) GetRolesOfUser(userID string, option schema.RoleOption) (*schema.Roles, int64, error) {
+// GetRolesOfUser fetch the roles assigned to the user. (with pagination option)
+// @param string
+// @param *schema.RoleOption
+// @return *schema.Roles, int64, error
+func (p *Passport) GetRolesOfUser(userID string, option *schema.RoleOption) (roles *schema.Roles, totalCount int64, err error) {
 	var roleIDs []string
-	var totalCount int64
-	var err error
-	if option.Pagination == nil {
-		roleIDs, totalCount, err = p.roleRepo.GetRoleIDsOfUser(userID, nil)
-	} else {
-		roleIDs, totalCount, err = p.roleRepo.GetRoleIDsOfUser(userID, &scopes.GormPagination{Pagination: option.Pagination.Get()})
+	roleIDs, totalCount, err = p.roleRepo.GetRoleIDsOfUser(userID, pager(option.Pagination))
+	if err != nil {
+		return nil, 0, err
 	}
-
-	roles, err := p.GetRoles(roleIDs, option.WithPermissions)
-	return roles, totalCount, err
+	roles, err = p.GetRoles(roleIDs, option.WithPermissions)
+	return
 }
 
-func (p *Passport) CreateRole(name, description string) (err error) {
-	return p.roleRepo.FirstOrCreate(&models.Role{
+// CreateRole create new role.
+// Name parameter is converted to guard name, if a role with the same guard name exists it is returned
+// @param string
+// @param string
+// @return *models.Role, error
+func (p *Passport) CreateRole(name, description string) (*models.Role, error) {
+	role := &models.Role{
 		Name:        name,
 		GuardName:   utils.Guard(name),
 		Description: description,
-	})
+	}
+	if err := p.roleRepo.FirstOrCreate(role); err != nil {
+		return nil, err
+	}
+	return role, p.changed(nil)
+}
+
+// UpdateRole rename role or change its description.
+// First parameter can be role name or id.
+// @param interface{}
+// @param string
+// @param string
+// @return *models.Role, error
+func (p *Passport) UpdateRole(r interface{}, name, description string) (*models.Role, error) {
+	role, err := p.GetRole(r, false)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"description": description}
+	if name != "" && name != role.Name {
+		guardName := utils.Guard(name)
+		if existing, err := p.roleRepo.GetRoleByGuardName(guardName); err == nil && existing.ID != role.ID {
+			return nil, errors.ErrorExistRole.New()
+		}
+		updates["name"] = name
+		updates["guard_name"] = guardName
+	}
+
+	if err := p.roleRepo.Updates(role, updates); err != nil {
+		return nil, err
+	}
+	p.changed(nil)
+	return p.roleRepo.GetRoleByIDWithPermissions(role.ID)
 }
 
 // DeleteRole delete role.
@@ -108,7 +298,10 @@ func (p *Passport) DeleteRole(r interface{}) (err error) {
 	if err != nil {
 		return err
 	}
-	return p.roleRepo.Delete(role)
+	if role.GuardName == models.RoleAdmin {
+		return errors.ErrorNotAllowDelete.New()
+	}
+	return p.changed(p.roleRepo.Delete(role))
 }
 
 // AddPermissionsToRole add permission to role.
@@ -129,12 +322,17 @@ func (p *Passport) AddPermissionsToRole(r interface{}, per interface{}) (err err
 	}
 
 	if permissions.Len() > 0 {
-		err = p.roleRepo.AddPermissions(role, &permissions)
+		err = p.roleRepo.AddPermissions(role, permissions)
 	}
 
-	return
+	return p.changed(err)
 }
 
+// ReplacePermissionsToRole replace permissions of role, an empty list removes all of them.
+// First parameter can be role name or id, second parameter can be permission name(s) or id(s).
+// @param interface{}
+// @param interface{}
+// @return error
 func (p *Passport) ReplacePermissionsToRole(r interface{}, s interface{}) (err error) {
 	role, err := p.GetRole(r, false)
 	if err != nil {
@@ -145,12 +343,17 @@ func (p *Passport) ReplacePermissionsToRole(r interface{}, s interface{}) (err e
 		return err
 	}
 	if permissions.Len() > 0 {
-		return p.roleRepo.ReplacePermissions(role, &permissions)
+		return p.changed(p.roleRepo.ReplacePermissions(role, permissions))
 	}
 
-	return p.roleRepo.ClearPermissions(role)
+	return p.changed(p.roleRepo.ClearPermissions(role))
 }
 
+// RemovePermissionsFromRole remove permissions from role.
+// First parameter can be role name or id, second parameter can be permission name(s) or id(s).
+// @param interface{}
+// @param interface{}
+// @return error
 func (p *Passport) RemovePermissionsFromRole(r interface{}, s interface{}) (err error) {
 	role, err := p.GetRole(r, false)
 	if err != nil {
@@ -162,66 +365,274 @@ func (p *Passport) RemovePermissionsFromRole(r interface{}, s interface{}) (err
 		return err
 	}
 	if permissions.Len() > 0 {
-		err = p.roleRepo.RemovePermissions(role, &permissions)
+		err = p.roleRepo.RemovePermissions(role, permissions)
 	}
 
-	return
+	return p.changed(err)
+}
+
+// HIERARCHY
+
+// hierarchy the roles followed by every role they inherit from, walked breadth first.
+// Depth of the given roles is 0, each role is visited once so the walk ends on cycles
+func (p *Passport) hierarchy(roleIDs []string) ([]string, map[string]int, error) {
+	depths := map[string]int{}
+	var ordered, frontier []string
+	visit := func(IDs []string, depth int) {
+		frontier = nil
+		for _, ID := range IDs {
+			if _, ok := depths[ID]; !ok {
+				depths[ID] = depth
+				ordered = append(ordered, ID)
+				frontier = append(frontier, ID)
+			}
+		}
+	}
+
+	visit(roleIDs, 0)
+	for depth := 1; len(frontier) > 0; depth++ {
+		parentIDs, err := p.roleRepo.GetParentIDsOfRoles(frontier)
+		if err != nil {
+			return nil, nil, err
+		}
+		visit(parentIDs, depth)
+	}
+	return ordered, depths, nil
+}
+
+// inheritedRoleIDs the roles with every role they inherit from
+func (p *Passport) inheritedRoleIDs(roleIDs []string) ([]string, error) {
+	ordered, _, err := p.hierarchy(roleIDs)
+	return ordered, err
+}
+
+// inheritedRoles the roles with every role they inherit from
+func (p *Passport) inheritedRoles(roleIDs []string) (*schema.Roles, error) {
+	IDs, err := p.inheritedRoleIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	return p.roleRepo.GetRoles(IDs)
+}
+
+// effectivePermissionIDsOfRoles ids of the permissions of the roles including inherited ones
+func (p *Passport) effectivePermissionIDsOfRoles(roleIDs []string) ([]string, error) {
+	IDs, err := p.inheritedRoleIDs(roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	permissionIDs, _, err := p.permRepo.GetPermissionIDsOfRolesByIDs(IDs, nil)
+	return permissionIDs, err
+}
+
+// GetParentsOfRole fetch the direct parents of the role.
+// First parameter can be role name or id.
+// @param interface{}
+// @return *schema.Roles, error
+func (p *Passport) GetParentsOfRole(r interface{}) (*schema.Roles, error) {
+	role, err := p.GetRole(r, false)
+	if err != nil {
+		return nil, err
+	}
+	parentIDs, err := p.roleRepo.GetParentIDsOfRoles([]string{role.ID})
+	if err != nil {
+		return nil, err
+	}
+	return p.roleRepo.GetRoles(parentIDs)
+}
+
+// AddParentsToRole make the role inherit permissions of the parents.
+// A parent that already inherits from the role is rejected since it would close a cycle.
+// First parameter can be role name or id, second parameter can be role name(s) or id(s).
+// @param interface{}
+// @param interface{}
+// @return error
+func (p *Passport) AddParentsToRole(r interface{}, parents interface{}) error {
+	role, err := p.GetRole(r, false)
+	if err != nil {
+		return err
+	}
+	parentRoles, err := p.GetRoles(parents, false)
+	if err != nil {
+		return err
+	}
+	if parentRoles.Len() == 0 {
+		return nil
+	}
+
+	ancestorIDs, err := p.inheritedRoleIDs(parentRoles.IDs())
+	if err != nil {
+		return err
+	}
+	if utils.InArray(role.ID, ancestorIDs) {
+		return errors.ErrorInvalidParent.Newm("role hierarchy cannot contain a cycle")
+	}
+	return p.changed(p.roleRepo.AddParents(role, parentRoles))
+}
+
+// RemoveParentsFromRole stop the role inheriting permissions of the parents.
+// First parameter can be role name or id, second parameter can be role name(s) or id(s).
+// @param interface{}
+// @param interface{}
+// @return error
+func (p *Passport) RemoveParentsFromRole(r interface{}, parents interface{}) error {
+	role, err := p.GetRole(r, false)
+	if err != nil {
+		return err
+	}
+	parentRoles, err := p.GetRoles(parents, false)
+	if err != nil {
+		return err
+	}
+	if parentRoles.Len() == 0 {
+		return nil
+	}
+	return p.changed(p.roleRepo.RemoveParents(role, parentRoles))
+}
+
+// GetEffectivePermissionsOfRole resolve the permissions of the role through the hierarchy,
+// each permission lists the roles granting it ordered by distance from the role.
+// First parameter can be role name or id.
+// @param interface{}
+// @return *schema.EffectivePermissionList, error
+func (p *Passport) GetEffectivePermissionsOfRole(r interface{}) (*schema.EffectivePermissionList, error) {
+	role, err := p.GetRole(r, false)
+	if err != nil {
+		return nil, err
+	}
+	ordered, depths, err := p.hierarchy([]string{role.ID})
+	if err != nil {
+		return nil, err
+	}
+	roles, err := p.roleRepo.GetRolesWithPermissions(ordered)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]models.Role{}
+	for _, rl := range roles.Origin() {
+		byID[rl.ID] = rl
+	}
+
+	list := &schema.EffectivePermissionList{Role: role, Permissions: []schema.EffectivePermission{}}
+	index := map[string]int{}
+	for _, ID := range ordered {
+		source, ok := byID[ID]
+		if !ok {
+			continue
+		}
+		for _, permission := range source.Permissions {
+			i, seen := index[permission.ID]
+			if !seen {
+				i = len(list.Permissions)
+				index[permission.ID] = i
+				list.Permissions = append(list.Permissions, schema.EffectivePermission{Permission: permission, Inherited: true})
+			}
+			if depths[ID] == 0 {
+				list.Permissions[i].Inherited = false
+			}
+			list.Permissions[i].Sources = append(list.Permissions[i].Sources, schema.PermissionSource{
+				RoleID:   source.ID,
+				RoleName: source.Name,
+				Depth:    depths[ID],
+			})
+		}
+	}
+	return list, nil
 }
 
 // PERMISSION
 
-func (p *Passport) GetPermission(s interface{}) (permission models.Permission, err error) {
+// findPermission get permission by name, falls back to id
+func (p *Passport) findPermission(s string) (*models.Permission, error) {
+	permission, err := p.permRepo.GetPermissionByGuardName(utils.Guard(s))
+	if err == nil {
+		return &permission, nil
+	}
+
+	permission, err = p.permRepo.GetPermissionByID(s)
+	if err != nil {
+		return nil, errors.ErrorNotExistPermission.New()
+	}
+	return &permission, nil
+}
+
+// GetPermission fetch permission according to the permission name or id.
+// If the first parameter is an array, the first element of the given array is used.
+// @param interface{}
+// @return *models.Permission, error
+func (p *Passport) GetPermission(s interface{}) (*models.Permission, error) {
 	if utils.IsArray(s) {
-		var permissions []models.Permission
-		permissions, err = p.GetPermissions(p)
+		permissions, err := p.GetPermissions(s)
 		if err != nil {
-			return models.Permission{}, err
+			return nil, err
 		}
-		if len(permissions) > 0 {
-			permission = permissions[0]
+		if permissions.Len() > 0 {
+			permission := (*permissions)[0]
+			return &permission, nil
 		}
-		return
+		return nil, errors.ErrorNotExistPermission.New()
 	}
 
 	if utils.IsString(s) {
-		return p.permRepo.GetPermissionByID(utils.Guard(s.(string)))
+		return p.findPermission(s.(string))
 	}
 
-	return models.Permission{}, errors.ErrorExistRole.Newm("unsupported value type")
+	return nil, errUnsupportedValueType
 }
 
-// GetPermissions fetch permissions according to the permission names or ids.
+// GetPermissions fetch permissions according to the permission names or ids, all of them must exist.
 // First parameter is can be permission name(s) or id(s).
 // @param interface{}
-// @return collections.Permission, error
-func (s *Passport) GetPermissions(p interface{}) (permissions schema.Permission, err error) {
+// @return *schema.Permission, error
+func (s *Passport) GetPermissions(p interface{}) (*schema.Permission, error) {
 	if !utils.IsArray(p) {
-		var permission models.Permission
-		permission, err = s.GetPermission(p)
+		permission, err := s.GetPermission(p)
 		if err != nil {
-			return schema.Permission{}, err
+			return nil, err
 		}
-		permissions = schema.Permission{permission}
-		return
+		permissions := schema.Permission{*permission}
+		return &permissions, nil
+	}
+
+	if !utils.IsStringArray(p) {
+		return nil, errUnsupportedValueType
 	}
 
-	if utils.IsStringArray(p) {
-		return s.permRepo.GetPermissions(p.([]string))
+	values := utils.RemoveDuplicateValues(p.([]string))
+	permissions, err := s.permRepo.GetPermissions(values)
+	if err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	if permissions.Len() == int64(len(values)) {
+		return &permissions, nil
 	}
 
-	return schema.Permission{}, errUnsupportedValueType
+	byName, err := s.permRepo.GetPermissionsByGuardNames(utils.GuardArray(values))
+	if err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	for _, permission := range byName {
+		if !utils.InArray(permission.ID, permissions.IDs()) {
+			permissions = append(permissions, permission)
+		}
+	}
+	for _, value := range values {
+		if !utils.InArray(value, permissions.IDs()) && !utils.InArray(utils.Guard(value), permissions.GuardNames()) {
+			return nil, errors.ErrorNotExistPermission.New()
+		}
+	}
+	return &permissions, nil
 }
 
 // GetAllPermissions fetch all the permissions. (with pagination option).
 // First parameter is permission option.
-// @param options.PermissionOption
-// @return collections.Permission, int64, error
-func (s *Passport) GetAllPermissions(option schema.PermissionOption) (permissions schema.Permission, totalCount int64, err error) {
+// @param *schema.PermissionOption
+// @return *schema.Permission, int64, error
+func (s *Passport) GetAllPermissions(option *schema.PermissionOption) (permissions *schema.Permission, totalCount int64, err error) {
 	var permissionIDs []string
-	if option.Pagination == nil {
-		permissionIDs, totalCount, err = s.permRepo.GetPermissionIDs(nil)
-	} else {
-		permissionIDs, totalCount, err = s.permRepo.GetPermissionIDs(&scopes.GormPagination{option.Pagination.Get()})
+	permissionIDs, totalCount, err = s.permRepo.GetPermissionIDs(pager(option.Pagination))
+	if err != nil {
+		return nil, 0, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
 	permissions, err = s.GetPermissions(permissionIDs)
 	return
@@ -229,15 +640,14 @@ func (s *Passport) GetAllPermissions(option schema.PermissionOption) (permission
 
 // GetDirectPermissionsOfUser fetch all direct permissions of the user. (with pagination option)
 // First parameter is user id, second parameter is permission option.
-// @param uint
-// @param options.PermissionOption
-// @return collections.Permission, int64, error
-func (s *Passport) GetDirectPermissionsOfUser(userID string, option schema.PermissionOption) (permissions schema.Permission, totalCount int64, err error) {
+// @param string
+// @param *schema.PermissionOption
+// @return *schema.Permission, int64, error
+func (s *Passport) GetDirectPermissionsOfUser(userID string, option *schema.PermissionOption) (permissions *schema.Permission, totalCount int64, err error) {
 	var permissionIDs []string
-	if option.Pagination == nil {
-		permissionIDs, totalCount, err = s.permRepo.GetDirectPermissionIDsOfUserByID(userID, nil)
-	} else {
-		permissionIDs, totalCount, err = s.permRepo.GetDirectPermissionIDsOfUserByID(userID, &scopes.GormPagination{option.Pagination.Get()})
+	permissionIDs, totalCount, err = s.permRepo.GetDirectPermissionIDsOfUserByID(userID, pager(option.Pagination))
+	if err != nil {
+		return nil, 0, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
 	permissions, err = s.GetPermissions(permissionIDs)
 	return
@@ -246,45 +656,41 @@ func (s *Passport) GetDirectPermissionsOfUser(userID string, option schema.Permi
 // GetPermissionsOfRoles fetch all permissions of the roles. (with pagination option)
 // First parameter can be role name(s) or id(s), second parameter is permission option.
 // @param interface{}
-// @param options.PermissionOption
-// @return collections.Permission, int64, error
-func (s *Passport) GetPermissionsOfRoles(r interface{}, option schema.PermissionOption) (permissions schema.Permission, totalCount int64, err error) {
+// @param *schema.PermissionOption
+// @return *schema.Permission, int64, error
+func (s *Passport) GetPermissionsOfRoles(r interface{}, option *schema.PermissionOption) (permissions *schema.Permission, totalCount int64, err error) {
 	var roles *schema.Roles
 	roles, err = s.GetRoles(r, false)
 	if err != nil {
-		return schema.Permission{}, 0, err
+		return nil, 0, err
 	}
 
 	var permissionIDs []string
-	if option.Pagination == nil {
-		permissionIDs, totalCount, err = s.permRepo.GetPermissionIDsOfRolesByIDs(roles.IDs(), nil)
-	} else {
-		permissionIDs, totalCount, err = s.permRepo.GetPermissionIDsOfRolesByIDs(roles.IDs(), &scopes.GormPagination{option.Pagination.Get()})
+	permissionIDs, totalCount, err = s.permRepo.GetPermissionIDsOfRolesByIDs(roles.IDs(), pager(option.Pagination))
+	if err != nil {
+		return nil, 0, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
 	permissions, err = s.GetPermissions(permissionIDs)
 	return
 }
 
-// GetAllPermissinosOfUser fetch all permissions of the user that come with direct and roles
+// GetAllPermissionsOfUser fetch all permissions of the user that come with direct and roles
 // @param string
-// @return schema.Permission, error
-func (s *Passport) GetAllPermissionsOfUser(userID string) (permissions schema.Permission, err error) {
-	var userRoleIDs []string
-	userRoleIDs, _, err = s.roleRepo.GetRoleIDsOfUser(userID, nil)
+// @return *schema.Permission, error
+func (s *Passport) GetAllPermissionsOfUser(userID string) (*schema.Permission, error) {
+	userRoleIDs, err := s.roleIDsOfUser(userID)
 	if err != nil {
-		return schema.Permission{}, err
+		return nil, err
 	}
 
-	var rolePermissionIDs []string
-	rolePermissionIDs, _, err = s.permRepo.GetPermissionIDsOfRolesByIDs(userRoleIDs, nil)
+	rolePermissionIDs, err := s.effectivePermissionIDsOfRoles(userRoleIDs)
 	if err != nil {
-		return schema.Permission{}, err
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
 
-	var userDirectPermissionIDs []string
-	userDirectPermissionIDs, _, err = s.permRepo.GetDirectPermissionIDsOfUserByID(userID, nil)
+	userDirectPermissionIDs, _, err := s.permRepo.GetDirectPermissionIDsOfUserByID(userID, nil)
 	if err != nil {
-		return schema.Permission{}, err
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
 	return s.GetPermissions(utils.RemoveDuplicateValues(utils.JoinStringArrays(rolePermissionIDs, userDirectPermissionIDs)))
 }
@@ -294,13 +700,72 @@ func (s *Passport) GetAllPermissionsOfUser(userID string) (permissions schema.Pe
 // If a permission with the same name has been created before, it will not create it again
 // @param string
 // @param string
-// @return error
-func (s *Passport) CreatePermission(name string, description string) (err error) {
-	return s.permRepo.FirstOrCreate(&models.Permission{
+// @return *models.Permission, error
+func (s *Passport) CreatePermission(name string, description string) (*models.Permission, error) {
+	permission := &models.Permission{
 		Name:        name,
 		GuardName:   utils.Guard(name),
 		Description: description,
-	})
+	}
+	if err := s.permRepo.FirstOrCreate(permission); err != nil {
+		return nil, errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return permission, s.changed(nil)
+}
+
+// UpdatePermission rename permission or change its description.
+// First parameter can be permission name or id.
+// @param interface{}
+// @param string
+// @param string
+// @return *models.Permission, error
+func (s *Passport) UpdatePermission(p interface{}, name, description string) (*models.Permission, error) {
+	permission, err := s.GetPermission(p)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"description": description}
+	if name != "" && name != permission.Name {
+		guardName := utils.Guard(name)
+		if existing, err := s.permRepo.GetPermissionByGuardName(guardName); err == nil && existing.ID != permission.ID {
+			return nil, errors.ErrorExistPermission.New()
+		}
+		updates["name"] = name
+		updates["guard_name"] = guardName
+	}
+
+	if err := s.permRepo.Updates(permission, updates); err != nil {
+		return nil, errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	s.changed(nil)
+	return s.findPermission(permission.ID)
+}
+
+// SetPermissionResource bind permission to the http routes it grants when casbin enforcement is enabled.
+// Resource is a path pattern such as /api/v1/users/:id, action is a method pattern such as GET or (GET|POST).
+// Empty resource unbinds the permission.
+// @param interface{}
+// @param string
+// @param string
+// @return *models.Permission, error
+func (s *Passport) SetPermissionResource(p interface{}, resource, action string) (*models.Permission, error) {
+	permission, err := s.GetPermission(p)
+	if err != nil {
+		return nil, err
+	}
+	if resource == "" {
+		action = ""
+	} else if action == "" {
+		action = models.AnyAction
+	}
+
+	updates := map[string]interface{}{"resource": resource, "action": action}
+	if err := s.permRepo.Updates(permission, updates); err != nil {
+		return nil, errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	s.changed(nil)
+	return s.findPermission(permission.ID)
 }
 
 // DeletePermission delete permission
@@ -310,86 +775,143 @@ func (s *Passport) CreatePermission(name string, description string) (err error)
 // @param interface{}
 // @return error
 func (s *Passport) DeletePermission(p interface{}) (err error) {
-	var permission models.Permission
-	permission, err = s.GetPermission(p)
+	permission, err := s.GetPermission(p)
 	if err != nil {
 		return err
 	}
-	return s.permRepo.Delete(&permission)
+	return s.changed(s.permRepo.Delete(permission))
 }
 
 // ------------------ USER -------------------
 
-// AddPermissionToUser add direct permission or permissions to user according to the permission names or ids.
-// FIrst parameter is the user id, secodn parameter can be permission name(s) or id(s)
-// @param uint
+// AddPermissionsToUser add direct permission or permissions to user according to the permission names or ids.
+// First parameter is the user id, second parameter can be permission name(s) or id(s),
+// third parameter is the terms of the grant, nil grants the permissions permanently
+// @param string
 // @param interface{}
+// @param *schema.Grant
 // @return error
-func (s *Passport) AddPermissionToUser(userID string, p interface{}) (err error) {
-	var permissions schema.Permission
-	permissions, err = s.GetPermissions(p)
+func (s *Passport) AddPermissionsToUser(userID string, p interface{}, grant *schema.Grant) (err error) {
+	if err = checkGrant(grant); err != nil {
+		return err
+	}
+	if err = s.checkUser(userID); err != nil {
+		return err
+	}
+
+	permissions, err := s.GetPermissions(p)
 	if err != nil {
 		return err
 	}
 	if permissions.Len() > 0 {
-		err = s.userRepo.AddPermissions(userID, permissions)
+		err = s.userRepo.AddPermissions(userID, *permissions, grant)
 	}
 
-	return
+	return s.changed(err)
 }
 
+// ReplacePermissionsToUser replace direct permissions of user, an empty list removes all of them.
+// First parameter is the user id, second parameter can be permission name(s) or id(s)
+// @param string
+// @param interface{}
+// @return error
 func (p *Passport) ReplacePermissionsToUser(userID string, s interface{}) (err error) {
+	if err = p.checkUser(userID); err != nil {
+		return err
+	}
+
 	permissions, err := p.GetPermissions(s)
 	if err != nil {
 		return err
 	}
 
 	if permissions.Len() > 0 {
-		return p.userRepo.ReplacePermissions(userID, permissions)
+		return p.changed(p.userRepo.ReplacePermissions(userID, *permissions))
 	}
-	return p.userRepo.ClearPermissions(userID)
+	return p.changed(p.userRepo.ClearPermissions(userID))
 }
 
-func (p *Passport) RemovePermissionsFromUser(userId string, s interface{}) (err error) {
+// RemovePermissionsFromUser remove direct permissions from user.
+// First parameter is the user id, second parameter can be permission name(s) or id(s)
+// @param string
+// @param interface{}
+// @return error
+func (p *Passport) RemovePermissionsFromUser(userID string, s interface{}) (err error) {
+	if err = p.checkUser(userID); err != nil {
+		return err
+	}
+
 	permissions, err := p.GetPermissions(s)
 	if err != nil {
 		return err
 	}
 
 	if permissions.Len() > 0 {
-		err = p.userRepo.RemovePermissions(userId, permissions)
+		err = p.userRepo.RemovePermissions(userID, *permissions)
 	}
 
-	return
+	return p.changed(err)
 }
 
-func (p *Passport) AddRolesToUser(userID string, r interface{}) (err error) {
+// AddRolesToUser assign roles to user.
+// First parameter is the user id, second parameter can be role name(s) or id(s),
+// third parameter is the terms of the grant, nil assigns the roles permanently
+// @param string
+// @param interface{}
+// @param *schema.Grant
+// @return error
+func (p *Passport) AddRolesToUser(userID string, r interface{}, grant *schema.Grant) (err error) {
+	if err = checkGrant(grant); err != nil {
+		return err
+	}
+	if err = p.checkUser(userID); err != nil {
+		return err
+	}
+
 	roles, err := p.GetRoles(r, false)
 	if err != nil {
 		return err
 	}
 
 	if roles.Len() > 0 {
-		err = p.userRepo.AddRoles(userID, *roles)
+		err = p.userRepo.AddRoles(userID, *roles, grant)
 	}
 
-	return
+	return p.changed(err)
 }
 
+// ReplaceRolesToUser replace roles of user, an empty list removes all of them.
+// First parameter is the user id, second parameter can be role name(s) or id(s)
+// @param string
+// @param interface{}
+// @return error
 func (p *Passport) ReplaceRolesToUser(userID string, r interface{}) (err error) {
+	if err = p.checkUser(userID); err != nil {
+		return err
+	}
+
 	roles, err := p.GetRoles(r, false)
 	if err != nil {
 		return err
 	}
 
 	if roles.Len() > 0 {
-		return p.userRepo.ReplaceRoles(userID, *roles)
+		return p.changed(p.userRepo.ReplaceRoles(userID, *roles))
 	}
 
-	return p.userRepo.ClearRoles(userID)
+	return p.changed(p.userRepo.ClearRoles(userID))
 }
 
+// RemoveRolesFromUser remove roles from user.
+// First parameter is the user id, second parameter can be role name(s) or id(s)
+// @param string
+// @param interface{}
+// @return error
 func (p *Passport) RemoveRolesFromUser(userID string, r interface{}) (err error) {
+	if err = p.checkUser(userID); err != nil {
+		return err
+	}
+
 	roles, err := p.GetRoles(r, false)
 	if err != nil {
 		return err
@@ -399,9 +921,14 @@ func (p *Passport) RemoveRolesFromUser(userID string, r interface{}) (err error)
 		err = p.userRepo.RemoveRoles(userID, *roles)
 	}
 
-	return
+	return p.changed(err)
 }
 
+// RoleHasPermission does the role or any of the roles have the given permission? (including inherited permissions and wildcard grants)
+// First parameter is can be role name(s) or id(s), second parameter is can be permission name or id.
+// @param interface{}
+// @param interface{}
+// @return bool, error
 func (p *Passport) RoleHasPermission(r interface{}, s interface{}) (b bool, err error) {
 	roles, err := p.GetRoles(r, false)
 	if err != nil {
@@ -413,12 +940,16 @@ func (p *Passport) RoleHasPermission(r interface{}, s interface{}) (b bool, err
 		return false, err
 	}
 
-	return p.roleRepo.HasPermission(roles, &permission)
+	inherited, err := p.inheritedRoles(roles.IDs())
+	if err != nil {
+		return false, err
+	}
+	return p.roleRepo.HasPermission(inherited, permission)
 }
 
 //---------
 
-// RoleHasAllPermissions does the role or roles have all the given permissions?
+// RoleHasAllPermissions does each of the roles have all the given permissions? (including inherited permissions and wildcard grants)
 // First parameter is can be role name(s) or id(s), second parameter is can be permission name(s) or id(s).
 // @param interface{}
 // @param interface{}
@@ -429,15 +960,24 @@ func (p *Passport) RoleHasAllPermissions(r interface{}, s interface{}) (b bool,
 		return false, err
 	}
 
-	permissions, err := p.GetPermissions(p)
+	permissions, err := p.GetPermissions(s)
 	if err != nil {
 		return false, err
 	}
 
-	return p.roleRepo.HasAllPermissions(roles, &permissions)
+	for _, roleID := range roles.IDs() {
+		inherited, err := p.inheritedRoles([]string{roleID})
+		if err != nil {
+			return false, err
+		}
+		if ok, err := p.roleRepo.HasAllPermissions(inherited, permissions); !ok || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
-// RoleHasAnyPermissions does the role or roles have any of the given permissions?
+// RoleHasAnyPermissions does the role or roles have any of the given permissions? (including inherited permissions and wildcard grants)
 // First parameter is can be role name(s) or id(s), second parameter is can be permission name(s) or id(s).
 // @param interface{}
 // @param interface{}
@@ -453,7 +993,11 @@ func (p *Passport) RoleHasAnyPermissions(r interface{}, s interface{}) (b bool,
 		return false, err
 	}
 
-	return p.roleRepo.HasAnyPermissions(roles, &permissions)
+	inherited, err := p.inheritedRoles(roles.IDs())
+	if err != nil {
+		return false, err
+	}
+	return p.roleRepo.HasAnyPermissions(inherited, permissions)
 }
 
 // USER
@@ -461,7 +1005,7 @@ func (p *Passport) RoleHasAnyPermissions(r interface{}, s interface{}) (b bool,
 // UserHasRole does the user have the given role?
 // First parameter is the user id, second parameter is can be role name or id.
 // If the second parameter is an array, the first element of the given array is used.
-// @param uint
+// @param string
 // @param interface{}
 // @return bool, error
 func (p *Passport) UserHasRole(userID string, r interface{}) (b bool, err error) {
@@ -469,12 +1013,16 @@ func (p *Passport) UserHasRole(userID string, r interface{}) (b bool, err error)
 	if err != nil {
 		return false, err
 	}
-	return p.userRepo.HasRole(userID, *role)
+	roleIDs, err := p.roleIDsOfUser(userID)
+	if err != nil {
+		return false, err
+	}
+	return utils.InArray(role.ID, roleIDs), nil
 }
 
 // UserHasAllRoles does the user have all the given roles?
 // First parameter is the user id, second parameter is can be role name(s) or id(s).
-// @param uint
+// @param string
 // @param interface{}
 // @return bool, error
 func (p *Passport) UserHasAllRoles(userID string, r interface{}) (b bool, err error) {
@@ -482,12 +1030,21 @@ func (p *Passport) UserHasAllRoles(userID string, r interface{}) (b bool, err er
 	if err != nil {
 		return false, err
 	}
-	return p.userRepo.HasAllRoles(userID, *roles)
+	roleIDs, err := p.roleIDsOfUser(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, roleID := range roles.IDs() {
+		if !utils.InArray(roleID, roleIDs) {
+			return false, nil
+		}
+	}
+	return true, nil
 }
 
 // UserHasAnyRoles does the user have any of the given roles?
 // First parameter is the user id, second parameter is can be role name(s) or id(s).
-// @param uint
+// @param string
 // @param interface{}
 // @return bool, error
 func (p *Passport) UserHasAnyRoles(userID string, r interface{}) (b bool, err error) {
@@ -495,13 +1052,22 @@ func (p *Passport) UserHasAnyRoles(userID string, r interface{}) (b bool, err er
 	if err != nil {
 		return false, err
 	}
-	return p.userRepo.HasAnyRoles(userID, *roles)
+	roleIDs, err := p.roleIDsOfUser(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, roleID := range roles.IDs() {
+		if utils.InArray(roleID, roleIDs) {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // UserHasDirectPermission does the user have the given permission? (not including the permissions of the roles)
 // First parameter is the user id, second parameter is can be permission name or id.
 // If the second parameter is an array, the first element of the given array is used.
-// @param uint
+// @param string
 // @param interface{}
 // @return bool, error
 func (p *Passport) UserHasDirectPermission(userID string, s interface{}) (b bool, err error) {
@@ -509,12 +1075,12 @@ func (p *Passport) UserHasDirectPermission(userID string, s interface{}) (b bool
 	if err != nil {
 		return false, err
 	}
-	return p.userRepo.HasDirectPermission(userID, permission)
+	return p.userRepo.HasDirectPermission(userID, *permission)
 }
 
 // UserHasAllDirectPermissions does the user have all the given permissions? (not including the permissions of the roles)
 // First parameter is the user id, second parameter is can be permission name(s) or id(s).
-// @param uint
+// @param string
 // @param interface{}
 // @return bool, error
 func (p *Passport) UserHasAllDirectPermissions(userID string, s interface{}) (b bool, err error) {
@@ -522,12 +1088,12 @@ func (p *Passport) UserHasAllDirectPermissions(userID string, s interface{}) (b
 	if err != nil {
 		return false, err
 	}
-	return p.userRepo.HasAllDirectPermissions(userID, permissions)
+	return p.userRepo.HasAllDirectPermissions(userID, *permissions)
 }
 
 // UserHasAnyDirectPermissions does the user have any of the given permissions? (not including the permissions of the roles)
 // First parameter is the user id, second parameter is can be permission name(s) or id(s).
-// @param uint
+// @param string
 // @param interface{}
 // @return bool, error
 func (p *Passport) UserHasAnyDirectPermissions(userID string, s interface{}) (b bool, err error) {
@@ -535,130 +1101,240 @@ func (p *Passport) UserHasAnyDirectPermissions(userID string, s interface{}) (b
 	if err != nil {
 		return false, err
 	}
-	return p.userRepo.HasAnyDirectPermissions(userID, permissions)
+	return p.userRepo.HasAnyDirectPermissions(userID, *permissions)
 }
 
-// UserHasPermission does the user have the given permission? (including the permissions of the roles)
-// First parameter is the user id, second parameter is can be permission name or id.
-// If the second parameter is an array, the first element of the given array is used.
-// @param uint
-// @param interface{}
-// @return bool, error
-func (p *Passport) UserHasPermission(userID string, s interface{}) (b bool, err error) {
-	permission, err := p.GetPermission(s)
-	if err != nil {
-		return false, err
+// permissionSet effective permissions of the user, served from the cache when it holds the current version
+func (p *Passport) permissionSet(userID string) (*schema.PermissionSet, error) {
+	set, version, cacheErr := p.cache.Get(userID)
+	if cacheErr != nil {
+		logger.Warn("Failed to read permission cache: ", cacheErr)
+	}
+	if set != nil {
+		return set, nil
 	}
 
-	var directPermissionIDs []string
-	directPermissionIDs, _, err = p.permRepo.GetDirectPermissionIDsOfUserByID(userID, nil)
+	permissions, err := p.GetAllPermissionsOfUser(userID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
+	set = schema.NewPermissionSet(*permissions)
+	if cacheErr == nil {
+		if err := p.cache.Set(userID, version, set); err != nil {
+			logger.Warn("Failed to write permission cache: ", err)
+		}
+	}
+	return set, nil
+}
 
-	if utils.InArray(permission.ID, directPermissionIDs) {
-		return true, err
+// permissionValues names or ids of the permission argument
+func permissionValues(s interface{}) ([]string, error) {
+	switch values := s.(type) {
+	case string:
+		return []string{values}, nil
+	case []string:
+		return values, nil
 	}
+	return nil, errUnsupportedValueType
+}
 
-	var roleIDs []string
-	roleIDs, _, err = p.roleRepo.GetRoleIDsOfUser(userID, nil)
-	if err != nil {
+// UserHasPermission does the user have the given permission? (including the permissions of the roles and the roles they inherit from)
+// First parameter is the user id, second parameter is can be permission name or id.
+// If the second parameter is an array, the first element of the given array is used.
+// Wildcard grants such as users.* cover the permissions under them, unknown permissions are not held by anyone.
+// @param string
+// @param interface{}
+// @return bool, error
+func (p *Passport) UserHasPermission(userID string, s interface{}) (b bool, err error) {
+	values, err := permissionValues(s)
+	if err != nil || len(values) == 0 {
 		return false, err
 	}
 
-	var permissionIDs []string
-	permissionIDs, _, err = p.permRepo.GetPermissionIDsOfRolesByIDs(roleIDs, nil)
+	set, err := p.permissionSet(userID)
 	if err != nil {
 		return false, err
 	}
-
-	if utils.InArray(permission.ID, permissionIDs) {
-		return true, err
-	}
-
-	return false, err
+	return set.Has(values[0]), nil
 }
 
-// UserHasAllPermissions does the user have all the given permissions? (including the permissions of the roles).
+// UserHasAllPermissions does the user have all the given permissions? (including the permissions of the roles and the roles they inherit from).
 // First parameter is the user id, second parameter is can be permission name(s) or id(s).
-// @param uint
+// Wildcard grants such as users.* cover the permissions under them, unknown permissions are not held by anyone.
+// @param string
 // @param interface{}
 // @return bool, error
 func (p *Passport) UserHasAllPermissions(userID string, s interface{}) (b bool, err error) {
-	permissions, err := p.GetPermissions(s)
+	values, err := permissionValues(s)
 	if err != nil {
 		return false, err
 	}
 
-	var userPermissionIDs []string
-	userPermissionIDs, _, err = p.permRepo.GetDirectPermissionIDsOfUserByID(userID, nil)
+	set, err := p.permissionSet(userID)
 	if err != nil {
 		return false, err
 	}
+	for _, value := range values {
+		if !set.Has(value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 
-	var roleIDs []string
-	roleIDs, _, err = p.roleRepo.GetRoleIDsOfUser(userID, nil)
+// UserHasAnyPermissions does the user have any of the given permissions? (including the permissions of the roles and the roles they inherit from).
+// First parameter is the user id, second parameter is can be permission name(s) or id(s).
+// Wildcard grants such as users.* cover the permissions under them, unknown permissions are not held by anyone.
+// @param string
+// @param interface{}
+// @return bool, error
+func (p *Passport) UserHasAnyPermissions(userID string, s interface{}) (b bool, err error) {
+	values, err := permissionValues(s)
 	if err != nil {
 		return false, err
 	}
 
-	var rolePermissionIDs []string
-	rolePermissionIDs, _, err = p.permRepo.GetPermissionIDsOfRolesByIDs(roleIDs, nil)
+	set, err := p.permissionSet(userID)
 	if err != nil {
 		return false, err
 	}
+	for _, value := range values {
+		if set.Has(value) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
-	allPermissionIDsOfUser := utils.RemoveDuplicateValues(utils.JoinStringArrays(userPermissionIDs, rolePermissionIDs))
+// ExplainUserPermission does the user have the given permission, and through which grants?
+// Granted is resolved by UserHasPermission and the paths by the same role hierarchy and wildcard matching.
+// Assignments not started yet or expired which would give the permission are reported as near misses
+// @param string
+// @param string
+// @return *schema.AuthzExplanation, error
+func (p *Passport) ExplainUserPermission(userID string, permission string) (*schema.AuthzExplanation, error) {
+	if err := p.checkUser(userID); err != nil {
+		return nil, err
+	}
 
-	for _, permissionID := range permissions.IDs() {
-		if !utils.InArray(permissionID, allPermissionIDsOfUser) {
-			return false, err
-		}
+	explanation := &schema.AuthzExplanation{
+		UserID:     userID,
+		Permission: permission,
+		Paths:      []schema.AuthzPath{},
+		NearMisses: []schema.AuthzNearMiss{},
+	}
+	target := utils.Guard(permission)
+	if found, err := p.GetPermission(permission); err == nil {
+		explanation.Exists = true
+		target = found.GuardName
 	}
 
-	return true, err
-}
+	granted, err := p.UserHasPermission(userID, permission)
+	if err != nil {
+		return nil, err
+	}
+	explanation.Granted = granted
 
-// UserHasAnyPermissions does the user have any of the given permissions? (including the permissions of the roles).
-// First parameter is the user id, second parameter is can be permission name(s) or id(s).
-// @param uint
-// @param interface{}
-// @return bool, error
-func (p *Passport) UserHasAnyPermissions(userID string, s interface{}) (b bool, err error) {
-	permissions, err := p.GetPermissions(s)
+	directIDs, _, err := p.permRepo.GetDirectPermissionIDsOfUserByID(userID, nil)
 	if err != nil {
-		return false, err
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	direct, err := p.permRepo.GetPermissions(directIDs)
+	if err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	for _, covering := range direct.Covering(permission) {
+		explanation.Paths = append(explanation.Paths, schema.AuthzPath{
+			Source:   schema.AuthzSourceDirect,
+			Granted:  covering,
+			Wildcard: covering.GuardName != target,
+		})
 	}
 
-	var directPermissionIDs []string
-	directPermissionIDs, _, err = p.permRepo.GetDirectPermissionIDsOfUserByID(userID, nil)
+	roleIDs, err := p.roleIDsOfUser(userID)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	paths, err := p.rolePaths(roleIDs, permission, target)
+	if err != nil {
+		return nil, err
 	}
+	explanation.Paths = append(explanation.Paths, paths...)
 
-	for _, permissionID := range permissions.IDs() {
-		if utils.InArray(permissionID, directPermissionIDs) {
-			return true, err
+	now := time.Now()
+	inactiveRoles, inactivePermissions, err := p.userRepo.GetInactiveGrants(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range inactivePermissions {
+		permissions, err := p.permRepo.GetPermissions([]string{grant.PermissionID})
+		if err != nil {
+			return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+		}
+		for _, covering := range permissions.Covering(permission) {
+			explanation.NearMisses = append(explanation.NearMisses, nearMiss(schema.AuthzPath{
+				Source:   schema.AuthzSourceDirect,
+				Granted:  covering,
+				Wildcard: covering.GuardName != target,
+			}, grant.StartsAt, grant.ExpiresAt, now))
+		}
+	}
+	for _, grant := range inactiveRoles {
+		paths, err := p.rolePaths([]string{grant.RoleID}, permission, target)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			explanation.NearMisses = append(explanation.NearMisses, nearMiss(path, grant.StartsAt, grant.ExpiresAt, now))
 		}
 	}
+	return explanation, nil
+}
 
-	var roleIDs []string
-	roleIDs, _, err = p.roleRepo.GetRoleIDsOfUser(userID, nil)
+// rolePaths grants of the permission by the roles and the roles they inherit from, closest roles first
+func (p *Passport) rolePaths(roleIDs []string, permission string, target string) ([]schema.AuthzPath, error) {
+	ordered, depths, err := p.hierarchy(roleIDs)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-
-	var permissionIDs []string
-	permissionIDs, _, err = p.permRepo.GetPermissionIDsOfRolesByIDs(roleIDs, nil)
+	roles, err := p.roleRepo.GetRolesWithPermissions(ordered)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	byID := map[string]models.Role{}
+	for _, role := range roles.Origin() {
+		byID[role.ID] = role
 	}
 
-	for _, permissionID := range permissions.IDs() {
-		if utils.InArray(permissionID, permissionIDs) {
-			return true, err
+	var paths []schema.AuthzPath
+	for _, ID := range ordered {
+		role, ok := byID[ID]
+		if !ok {
+			continue
+		}
+		source := schema.AuthzSourceRole
+		if depths[ID] > 0 {
+			source = schema.AuthzSourceInheritedRole
+		}
+		for _, covering := range schema.Permission(role.Permissions).Covering(permission) {
+			paths = append(paths, schema.AuthzPath{
+				Source:   source,
+				RoleID:   role.ID,
+				RoleName: role.Name,
+				Depth:    depths[ID],
+				Granted:  covering,
+				Wildcard: covering.GuardName != target,
+			})
 		}
 	}
+	return paths, nil
+}
 
-	return false, err
+// nearMiss near miss of the path granted by an inactive assignment
+func nearMiss(path schema.AuthzPath, startsAt, expiresAt *time.Time, now time.Time) schema.AuthzNearMiss {
+	reason := schema.AuthzExpired
+	if startsAt != nil && startsAt.After(now) {
+		reason = schema.AuthzNotStarted
+	}
+	return schema.AuthzNearMiss{AuthzPath: path, Reason: reason, StartsAt: startsAt, ExpiresAt: expiresAt}
 }
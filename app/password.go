@@ -0,0 +1,25 @@
+package app
+
+import (
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/pkg/hasher"
+)
+
+// InitPasswordHasher return password hasher of config, hashes of every
+// supported algorithm are verified, new ones use the configured algorithm
+func InitPasswordHasher() *hasher.Hasher {
+	conf := config.Config.PasswordHash
+	argon2id := hasher.NewArgon2id(hasher.Argon2idParams{
+		Memory:      uint32(conf.Argon2id.Memory),
+		Iterations:  uint32(conf.Argon2id.Iterations),
+		Parallelism: uint8(conf.Argon2id.Parallelism),
+		SaltLength:  uint32(conf.Argon2id.SaltLength),
+		KeyLength:   uint32(conf.Argon2id.KeyLength),
+	})
+	bcrypt := hasher.NewBcrypt(conf.BcryptCost)
+
+	if conf.Algorithm == hasher.Bcrypt {
+		return hasher.New(bcrypt, argon2id)
+	}
+	return hasher.New(argon2id, bcrypt)
+}
@@ -0,0 +1,9 @@
+package permcache
+
+import "go.uber.org/dig"
+
+// Inject permission cache
+func Inject(container *dig.Container) error {
+	_ = container.Provide(New)
+	return nil
+}
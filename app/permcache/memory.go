@@ -0,0 +1,51 @@
+package permcache
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+type memoryBackend struct {
+	mu      sync.RWMutex
+	version int64
+	entries map[string]memoryEntry
+}
+
+// NewMemoryStore return permission cache kept in memory of current process
+func NewMemoryStore(ttl time.Duration) *Store {
+	return &Store{backend: &memoryBackend{entries: map[string]memoryEntry{}}, ttl: ttl}
+}
+
+func (m *memoryBackend) load(key string) (int64, []byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		return m.version, nil, nil
+	}
+	return m.version, e.value, nil
+}
+
+func (m *memoryBackend) store(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryEntry{value: value, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (m *memoryBackend) bump() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// entries of older versions are never served again
+	m.version++
+	m.entries = map[string]memoryEntry{}
+	return nil
+}
@@ -0,0 +1,100 @@
+package permcache
+
+import (
+	"encoding/json"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/middleware/cache"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"time"
+)
+
+const (
+	keyPrefix  = "perm:"
+	versionKey = keyPrefix + "version"
+
+	// DefaultTTL how long permission sets are cached when the ttl is not configured
+	DefaultTTL = 5 * time.Minute
+)
+
+// backend keeps the version and the cached entries
+type backend interface {
+	// load return the current version and the entry of key, nil entry when missing
+	load(key string) (int64, []byte, error)
+	store(key string, value []byte, ttl time.Duration) error
+	bump() error
+}
+
+// entry permission set with the version it was computed at
+type entry struct {
+	Version int64                 `json:"v"`
+	Set     *schema.PermissionSet `json:"s"`
+}
+
+// Store versioned cache of effective permissions.
+// Any change of roles, permissions or assignments bumps the version instead of deleting entries,
+// so a set computed before the change is never served after it. A lookup costs one cache read
+type Store struct {
+	backend backend
+	ttl     time.Duration
+}
+
+// New return redis permission cache if redis is available, otherwise in-memory cache.
+// The in-memory cache is only invalidated by changes made through the current process
+func New() interfaces.IPermissionCache {
+	conf := config.Config.PermissionCache
+	if !conf.Enable {
+		return &Store{}
+	}
+
+	ttl := time.Duration(conf.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if r := cache.Redis(); r != nil {
+		return NewRedisStore(r, ttl)
+	}
+	return NewMemoryStore(ttl)
+}
+
+func userKey(userID string) string {
+	return keyPrefix + "user:" + userID
+}
+
+// Get cached permission set of the user and the current version, the set is nil on miss
+func (s *Store) Get(userID string) (*schema.PermissionSet, int64, error) {
+	if s.backend == nil {
+		return nil, 0, nil
+	}
+
+	version, raw, err := s.backend.load(userKey(userID))
+	if err != nil || raw == nil {
+		return nil, version, err
+	}
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil || e.Version != version {
+		return nil, version, nil
+	}
+	return e.Set, version, nil
+}
+
+// Set store the permission set of the user computed at the version
+func (s *Store) Set(userID string, version int64, set *schema.PermissionSet) error {
+	if s.backend == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(entry{Version: version, Set: set})
+	if err != nil {
+		return err
+	}
+	return s.backend.store(userKey(userID), raw, s.ttl)
+}
+
+// Invalidate bump the version, every cached permission set becomes stale
+func (s *Store) Invalidate() error {
+	if s.backend == nil {
+		return nil
+	}
+	return s.backend.bump()
+}
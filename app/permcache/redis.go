@@ -0,0 +1,44 @@
+package permcache
+
+import (
+	"context"
+	"github.com/go-redis/redis/v9"
+	"github.com/shasw94/projX/app/middleware/cache"
+	"strconv"
+	"time"
+)
+
+var ctx = context.Background()
+
+type redisBackend struct {
+	client *redis.Client
+}
+
+// NewRedisStore return permission cache sharing the redis connection of cache
+func NewRedisStore(r *cache.GRedis, ttl time.Duration) *Store {
+	return &Store{backend: &redisBackend{client: r.Client()}, ttl: ttl}
+}
+
+func (r *redisBackend) load(key string) (int64, []byte, error) {
+	values, err := r.client.MGet(ctx, versionKey, key).Result()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var version int64
+	if s, ok := values[0].(string); ok {
+		version, _ = strconv.ParseInt(s, 10, 64)
+	}
+	if s, ok := values[1].(string); ok {
+		return version, []byte(s), nil
+	}
+	return version, nil, nil
+}
+
+func (r *redisBackend) store(key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *redisBackend) bump() error {
+	return r.client.Incr(ctx, versionKey).Err()
+}
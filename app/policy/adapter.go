@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"gorm.io/gorm"
+)
+
+// createBatchSize rows inserted per statement when the whole policy is saved
+const createBatchSize = 100
+
+// Adapter casbin adapter storing policies in the casbin_rule table
+type Adapter struct {
+	db interfaces.IDatabase
+}
+
+// NewAdapter return new casbin adapter, the casbin_rule table is created when missing
+func NewAdapter(db interfaces.IDatabase) (persist.BatchAdapter, error) {
+	if err := db.GetInstance().AutoMigrate(&models.CasbinRule{}); err != nil {
+		return nil, err
+	}
+	return &Adapter{db: db}, nil
+}
+
+// LoadPolicy load all policy rules from the storage
+func (a *Adapter) LoadPolicy(m model.Model) error {
+	var rules []models.CasbinRule
+	if err := a.db.GetInstance().Order("id").Find(&rules).Error; err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		persist.LoadPolicyArray(rule.Line(), m)
+	}
+	return nil
+}
+
+// SavePolicy replace the stored policy rules by the rules of the model
+func (a *Adapter) SavePolicy(m model.Model) error {
+	var rules []models.CasbinRule
+	for _, sec := range []string{"p", "g"} {
+		for ptype, ast := range m[sec] {
+			for _, values := range ast.Policy {
+				rules = append(rules, models.NewCasbinRule(ptype, values))
+			}
+		}
+	}
+
+	return a.db.GetInstance().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.CasbinRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rules, createBatchSize).Error
+	})
+}
+
+// AddPolicy add policy rule to the storage
+func (a *Adapter) AddPolicy(sec string, ptype string, rule []string) error {
+	row := models.NewCasbinRule(ptype, rule)
+	return a.db.GetInstance().Create(&row).Error
+}
+
+// AddPolicies add policy rules to the storage
+func (a *Adapter) AddPolicies(sec string, ptype string, rules [][]string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	rows := make([]models.CasbinRule, 0, len(rules))
+	for _, rule := range rules {
+		rows = append(rows, models.NewCasbinRule(ptype, rule))
+	}
+	return a.db.GetInstance().CreateInBatches(rows, createBatchSize).Error
+}
+
+// RemovePolicy remove policy rule from the storage
+func (a *Adapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return a.deleteRule(a.db.GetInstance(), models.NewCasbinRule(ptype, rule))
+}
+
+// RemovePolicies remove policy rules from the storage
+func (a *Adapter) RemovePolicies(sec string, ptype string, rules [][]string) error {
+	return a.db.GetInstance().Transaction(func(tx *gorm.DB) error {
+		for _, rule := range rules {
+			if err := a.deleteRule(tx, models.NewCasbinRule(ptype, rule)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RemoveFilteredPolicy remove policy rules matching the values from the field index, empty values match anything
+func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	query := a.db.GetInstance().Where("ptype = ?", ptype)
+	columns := []string{"v0", "v1", "v2", "v3", "v4", "v5"}
+	for i, value := range fieldValues {
+		if index := fieldIndex + i; value != "" && index < len(columns) {
+			query = query.Where(columns[index]+" = ?", value)
+		}
+	}
+	return query.Delete(&models.CasbinRule{}).Error
+}
+
+// deleteRule delete rows of the rule, every value must match
+func (a *Adapter) deleteRule(db *gorm.DB, rule models.CasbinRule) error {
+	return db.Where("ptype = ? AND v0 = ? AND v1 = ? AND v2 = ? AND v3 = ? AND v4 = ? AND v5 = ?",
+		rule.Ptype, rule.V0, rule.V1, rule.V2, rule.V3, rule.V4, rule.V5).Delete(&models.CasbinRule{}).Error
+}
@@ -0,0 +1,9 @@
+package policy
+
+import "go.uber.org/dig"
+
+// Inject casbin policy syncer
+func Inject(container *dig.Container) error {
+	_ = container.Provide(NewSyncer)
+	return nil
+}
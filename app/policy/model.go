@@ -0,0 +1,21 @@
+package policy
+
+import (
+	_ "embed"
+	"github.com/casbin/casbin/v2/model"
+)
+
+// DefaultModel rbac with paths model bundled with the application.
+// Subjects are user ids and role guard names, objects are request paths matched by keyMatch2
+// and actions are http methods matched by regex, * allows every method
+//
+//go:embed rbac_with_paths.conf
+var DefaultModel string
+
+// LoadModel load casbin model from the file, the bundled model is used when path is empty
+func LoadModel(path string) (model.Model, error) {
+	if path == "" {
+		return model.NewModelFromString(DefaultModel)
+	}
+	return model.NewModelFromFile(path)
+}
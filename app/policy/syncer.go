@@ -0,0 +1,175 @@
+package policy
+
+import (
+	"github.com/casbin/casbin/v2"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/repositories/scopes"
+	"strings"
+	"sync"
+	"time"
+)
+
+// adminRule policy of the admin role, every path and method is allowed
+var adminRule = []string{models.RoleAdmin, "/*", models.AnyAction}
+
+// Syncer rebuild casbin policies from the roles, permissions and assignments managed by Passport.
+//   - p, <role guard name | user id>, <permission resource>, <permission action> for permissions bound to resources
+//   - g, <user id>, <role guard name> for active role assignments and the primary role of users
+//   - g, <role guard name>, <parent role guard name> for the role hierarchy
+type Syncer struct {
+	db       interfaces.IDatabase
+	enforcer *casbin.SyncedEnforcer
+	mu       sync.Mutex
+}
+
+// NewSyncer return new IPolicySyncer interface
+func NewSyncer(db interfaces.IDatabase, enforcer *casbin.SyncedEnforcer) interfaces.IPolicySyncer {
+	return &Syncer{db: db, enforcer: enforcer}
+}
+
+// Sync bring the casbin policies in line with Passport, only the difference is applied so
+// requests enforced meanwhile never see an empty policy
+func (s *Syncer) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	policies, groupings, err := s.rules()
+	if err != nil {
+		return err
+	}
+
+	stalePolicies, newPolicies := diff(s.enforcer.GetPolicy(), policies)
+	staleGroupings, newGroupings := diff(s.enforcer.GetGroupingPolicy(), groupings)
+	if len(stalePolicies) > 0 {
+		if _, err := s.enforcer.RemovePolicies(stalePolicies); err != nil {
+			return err
+		}
+	}
+	if len(newPolicies) > 0 {
+		if _, err := s.enforcer.AddPolicies(newPolicies); err != nil {
+			return err
+		}
+	}
+	if len(staleGroupings) > 0 {
+		if _, err := s.enforcer.RemoveGroupingPolicies(staleGroupings); err != nil {
+			return err
+		}
+	}
+	if len(newGroupings) > 0 {
+		if _, err := s.enforcer.AddGroupingPolicies(newGroupings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rules policies and role links expected from the current state of Passport
+func (s *Syncer) rules() (policies [][]string, groupings [][]string, err error) {
+	db := s.db.GetInstance()
+
+	var roles []models.Role
+	if err = db.Preload("Permissions").Find(&roles).Error; err != nil {
+		return nil, nil, err
+	}
+	guards := map[string]string{}
+	for _, role := range roles {
+		guards[role.ID] = role.GuardName
+	}
+
+	policies = append(policies, adminRule)
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if permission.Resource != "" {
+				policies = append(policies, []string{role.GuardName, permission.Resource, permission.Action})
+			}
+		}
+	}
+
+	var direct []struct {
+		UserID   string
+		Resource string
+		Action   string
+	}
+	now := time.Now()
+	err = db.Table("user_permissions").Scopes(scopes.ActiveGrant("user_permissions", now)).
+		Select("user_permissions.user_id, permissions.resource, permissions.action").
+		Joins("JOIN permissions ON permissions.id = user_permissions.permission_id").
+		Where("permissions.resource <> '' AND permissions.deleted_at IS NULL").
+		Scan(&direct).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range direct {
+		policies = append(policies, []string{d.UserID, d.Resource, d.Action})
+	}
+
+	var links []struct {
+		UserID string
+		RoleID string
+	}
+	err = db.Table("user_roles").Scopes(scopes.ActiveGrant("user_roles", now)).
+		Select("user_roles.user_id, user_roles.role_id").Scan(&links).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	var primary []struct {
+		UserID string
+		RoleID string
+	}
+	err = db.Table("users").Select("users.id AS user_id, users.role_id").
+		Where("users.role_id <> '' AND users.deleted_at IS NULL").Scan(&primary).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, link := range append(links, primary...) {
+		if guard, ok := guards[link.RoleID]; ok {
+			groupings = append(groupings, []string{link.UserID, guard})
+		}
+	}
+
+	var parents []struct {
+		RoleID   string
+		ParentID string
+	}
+	err = db.Table("role_parents").Select("role_parents.role_id, role_parents.parent_id").Scan(&parents).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, parent := range parents {
+		child, ok := guards[parent.RoleID]
+		guard, found := guards[parent.ParentID]
+		if ok && found {
+			groupings = append(groupings, []string{child, guard})
+		}
+	}
+	return policies, groupings, nil
+}
+
+// diff rules of current missing from expected and rules of expected missing from current, duplicates are dropped
+func diff(current, expected [][]string) (stale, missing [][]string) {
+	key := func(rule []string) string {
+		return strings.Join(rule, "\x00")
+	}
+
+	want := map[string]bool{}
+	for _, rule := range expected {
+		want[key(rule)] = true
+	}
+	have := map[string]bool{}
+	for _, rule := range current {
+		k := key(rule)
+		if !want[k] && !have[k] {
+			stale = append(stale, rule)
+		}
+		have[k] = true
+	}
+	for _, rule := range expected {
+		k := key(rule)
+		if !have[k] {
+			missing = append(missing, rule)
+			have[k] = true
+		}
+	}
+	return stale, missing
+}
@@ -0,0 +1,9 @@
+package ratelimit
+
+import "go.uber.org/dig"
+
+// Inject rate limiter
+func Inject(container *dig.Container) error {
+	_ = container.Provide(New)
+	return nil
+}
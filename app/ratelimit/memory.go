@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"github.com/shasw94/projX/app/schema"
+	"sync"
+	"time"
+)
+
+// pruneInterval how often idle keys are removed from memory
+const pruneInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+type window struct {
+	requests  []time.Time
+	expiresAt time.Time
+}
+
+type memoryBackend struct {
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	windows  map[string]*window
+	prunedAt time.Time
+}
+
+// NewMemoryLimiter return limiter kept in memory of current process
+func NewMemoryLimiter() *Limiter {
+	return &Limiter{backend: &memoryBackend{
+		buckets:  map[string]*bucket{},
+		windows:  map[string]*window{},
+		prunedAt: time.Now(),
+	}}
+}
+
+// prune remove idle keys, caller holds the lock
+func (m *memoryBackend) prune(now time.Time) {
+	if now.Sub(m.prunedAt) < pruneInterval {
+		return
+	}
+	for k, b := range m.buckets {
+		if now.After(b.expiresAt) {
+			delete(m.buckets, k)
+		}
+	}
+	for k, w := range m.windows {
+		if now.After(w.expiresAt) {
+			delete(m.windows, k)
+		}
+	}
+	m.prunedAt = now
+}
+
+func (m *memoryBackend) tokenBucket(key string, limit int64, period time.Duration) (*schema.RateLimitResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.prune(now)
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit), updatedAt: now}
+		m.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updatedAt)
+	b.tokens += float64(elapsed) * float64(limit) / float64(period)
+	if b.tokens > float64(limit) {
+		b.tokens = float64(limit)
+	}
+	b.updatedAt = now
+	b.expiresAt = now.Add(period)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return bucketResult(allowed, b.tokens, limit, period), nil
+}
+
+func (m *memoryBackend) slidingWindow(key string, limit int64, period time.Duration) (*schema.RateLimitResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.prune(now)
+	w, ok := m.windows[key]
+	if !ok {
+		w = &window{}
+		m.windows[key] = w
+	}
+
+	start := now.Add(-period)
+	i := 0
+	for i < len(w.requests) && !w.requests[i].After(start) {
+		i++
+	}
+	w.requests = w.requests[i:]
+	w.expiresAt = now.Add(period)
+
+	allowed := int64(len(w.requests)) < limit
+	if allowed {
+		w.requests = append(w.requests, now)
+	}
+
+	oldest := now
+	if len(w.requests) > 0 {
+		oldest = w.requests[0]
+	}
+	return windowResult(allowed, int64(len(w.requests)), limit, oldest, now, period), nil
+}
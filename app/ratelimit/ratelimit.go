@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/middleware/cache"
+	"github.com/shasw94/projX/app/schema"
+	"time"
+)
+
+const (
+	keyPrefix = "ratelimit:"
+
+	// AlgorithmTokenBucket refill Limit tokens evenly over Period, bursts up to Limit requests
+	AlgorithmTokenBucket = "token_bucket"
+	// AlgorithmSlidingWindow allow Limit requests within any Period
+	AlgorithmSlidingWindow = "sliding_window"
+)
+
+// backend keeps state of rate limited keys
+type backend interface {
+	tokenBucket(key string, limit int64, period time.Duration) (*schema.RateLimitResult, error)
+	slidingWindow(key string, limit int64, period time.Duration) (*schema.RateLimitResult, error)
+}
+
+// Limiter rate limiter of requests
+type Limiter struct {
+	backend backend
+}
+
+// New return redis limiter if redis is available, otherwise in-memory limiter
+func New() interfaces.IRateLimiter {
+	if r := cache.Redis(); r != nil {
+		return NewRedisLimiter(r)
+	}
+	return NewMemoryLimiter()
+}
+
+// Allow count request of key against policy, unknown algorithms fall back to token bucket
+func (l *Limiter) Allow(key string, policy schema.RateLimitPolicy) (*schema.RateLimitResult, error) {
+	if policy.Algorithm == AlgorithmSlidingWindow {
+		return l.backend.slidingWindow(keyPrefix+"sw:"+key, policy.Limit, policy.Period)
+	}
+	return l.backend.tokenBucket(keyPrefix+"tb:"+key, policy.Limit, policy.Period)
+}
+
+// bucketResult result of token bucket holding tokens after the request
+func bucketResult(allowed bool, tokens float64, limit int64, period time.Duration) *schema.RateLimitResult {
+	interval := float64(period) / float64(limit)
+	result := &schema.RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int64(tokens),
+		Reset:     time.Duration((float64(limit) - tokens) * interval),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * interval)
+	}
+	return result
+}
+
+// windowResult result of sliding window holding count requests, the oldest of them at oldest
+func windowResult(allowed bool, count, limit int64, oldest, now time.Time, period time.Duration) *schema.RateLimitResult {
+	result := &schema.RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: limit - count,
+		Reset:     oldest.Add(period).Sub(now),
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !allowed {
+		result.RetryAfter = result.Reset
+	}
+	return result
+}
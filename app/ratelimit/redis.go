@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v9"
+	"github.com/shasw94/projX/app/middleware/cache"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/pkg/utils"
+	"strconv"
+	"time"
+)
+
+var ctx = context.Background()
+
+// tokenBucketScript refill and take a token atomically, time in milliseconds.
+// Return whether the request is allowed and the tokens left
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = limit
+	ts = now
+end
+tokens = math.min(limit, tokens + math.max(0, now - ts) * limit / period)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], period)
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript keep timestamps of requests within the window in a sorted set, time in milliseconds.
+// Return whether the request is allowed, requests in the window and the oldest of them
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - period)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], period)
+local oldest = now
+local first = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if first[2] then
+	oldest = tonumber(first[2])
+end
+return {allowed, count, oldest}
+`)
+
+type redisBackend struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter return limiter sharing the redis connection of cache
+func NewRedisLimiter(r *cache.GRedis) *Limiter {
+	return &Limiter{backend: &redisBackend{client: r.Client()}}
+}
+
+func (r *redisBackend) tokenBucket(key string, limit int64, period time.Duration) (*schema.RateLimitResult, error) {
+	res, err := tokenBucketScript.Run(ctx, r.client, []string{key}, limit, period.Milliseconds(), time.Now().UnixMilli()).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 2 {
+		return nil, fmt.Errorf("unexpected token bucket result %v", res)
+	}
+
+	allowed, _ := res[0].(int64)
+	tokens, err := strconv.ParseFloat(fmt.Sprint(res[1]), 64)
+	if err != nil {
+		return nil, err
+	}
+	return bucketResult(allowed == 1, tokens, limit, period), nil
+}
+
+func (r *redisBackend) slidingWindow(key string, limit int64, period time.Duration) (*schema.RateLimitResult, error) {
+	member, err := utils.RandomToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res, err := slidingWindowScript.Run(ctx, r.client, []string{key}, limit, period.Milliseconds(), now.UnixMilli(), member).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 3 {
+		return nil, fmt.Errorf("unexpected sliding window result %v", res)
+	}
+
+	allowed, _ := res[0].(int64)
+	count, _ := res[1].(int64)
+	oldest, _ := res[2].(int64)
+	return windowResult(allowed == 1, count, limit, time.UnixMilli(oldest), now, period), nil
+}
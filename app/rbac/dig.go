@@ -0,0 +1,9 @@
+package rbac
+
+import "go.uber.org/dig"
+
+// Inject rbac seed syncer
+func Inject(container *dig.Container) error {
+	_ = container.Provide(NewSyncer)
+	return nil
+}
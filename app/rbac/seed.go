@@ -0,0 +1,115 @@
+package rbac
+
+import (
+	"fmt"
+	"github.com/shasw94/projX/pkg/utils"
+	"github.com/spf13/viper"
+)
+
+// Seed roles, permissions and bootstrap users declared in a yaml or json file
+type Seed struct {
+	Permissions []PermissionSeed `mapstructure:"permissions"`
+	Roles       []RoleSeed       `mapstructure:"roles"`
+	Users       []UserSeed       `mapstructure:"users"`
+}
+
+// PermissionSeed declared permission, resource and action bind it to a path and method under casbin
+type PermissionSeed struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+	Resource    string `mapstructure:"resource"`
+	Action      string `mapstructure:"action"`
+}
+
+// RoleSeed declared role with the names of its parents and permissions
+type RoleSeed struct {
+	Name        string   `mapstructure:"name"`
+	Description string   `mapstructure:"description"`
+	Parents     []string `mapstructure:"parents"`
+	Permissions []string `mapstructure:"permissions"`
+}
+
+// UserSeed declared bootstrap user, the first role is the primary role of the user.
+// Users are matched by email, the password is only used when the user is created and may be hashed already
+type UserSeed struct {
+	Username string   `mapstructure:"username"`
+	Email    string   `mapstructure:"email"`
+	Password string   `mapstructure:"password"`
+	FullName string   `mapstructure:"full_name"`
+	Roles    []string `mapstructure:"roles"`
+}
+
+// Load read and validate the seed file, the format follows the file extension
+func Load(path string) (*Seed, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	var seed Seed
+	if err := v.Unmarshal(&seed); err != nil {
+		return nil, err
+	}
+	if err := seed.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &seed, nil
+}
+
+// Validate names are set and unique, and roles and users only reference declared roles and permissions
+func (s *Seed) Validate() error {
+	permissions := map[string]bool{}
+	for _, permission := range s.Permissions {
+		guard := utils.Guard(permission.Name)
+		if guard == "" {
+			return fmt.Errorf("permission name is required")
+		}
+		if permissions[guard] {
+			return fmt.Errorf("permission %q is declared twice", permission.Name)
+		}
+		permissions[guard] = true
+	}
+
+	roles := map[string]bool{}
+	for _, role := range s.Roles {
+		guard := utils.Guard(role.Name)
+		if guard == "" {
+			return fmt.Errorf("role name is required")
+		}
+		if roles[guard] {
+			return fmt.Errorf("role %q is declared twice", role.Name)
+		}
+		roles[guard] = true
+	}
+
+	for _, role := range s.Roles {
+		for _, permission := range role.Permissions {
+			if !permissions[utils.Guard(permission)] {
+				return fmt.Errorf("role %q references undeclared permission %q", role.Name, permission)
+			}
+		}
+		for _, parent := range role.Parents {
+			if !roles[utils.Guard(parent)] {
+				return fmt.Errorf("role %q references undeclared parent %q", role.Name, parent)
+			}
+		}
+	}
+
+	emails := map[string]bool{}
+	for _, user := range s.Users {
+		if user.Email == "" || user.Username == "" {
+			return fmt.Errorf("username and email of users are required")
+		}
+		if emails[user.Email] {
+			return fmt.Errorf("user %q is declared twice", user.Email)
+		}
+		emails[user.Email] = true
+		for _, role := range user.Roles {
+			if !roles[utils.Guard(role)] {
+				return fmt.Errorf("user %q references undeclared role %q", user.Email, role)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,399 @@
+package rbac
+
+import (
+	"fmt"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/pkg/utils"
+	"sort"
+	"strings"
+)
+
+// Change actions
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Change kinds
+const (
+	KindPermission = "permission"
+	KindRole       = "role"
+	KindUser       = "user"
+)
+
+// Change planned change of a record, applied through Passport
+type Change struct {
+	Action string
+	Kind   string
+	Name   string
+	Detail string
+	apply  func() error
+}
+
+func (c Change) String() string {
+	line := fmt.Sprintf("%s %s %q", c.Action, c.Kind, c.Name)
+	if c.Detail != "" {
+		line += ": " + c.Detail
+	}
+	return line
+}
+
+// Plan changes bringing the database in line with a seed, in the order they are applied
+type Plan struct {
+	Changes []Change
+}
+
+// Empty the database is in line with the seed already
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "no changes"
+	}
+	lines := make([]string, 0, len(p.Changes))
+	for _, change := range p.Changes {
+		lines = append(lines, change.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Apply run the changes in order, stops at the first failing change
+func (p *Plan) Apply() error {
+	for _, change := range p.Changes {
+		if err := change.apply(); err != nil {
+			return fmt.Errorf("%s: %w", change, err)
+		}
+	}
+	return nil
+}
+
+func (p *Plan) add(action, kind, name, detail string, apply func() error) {
+	p.Changes = append(p.Changes, Change{Action: action, Kind: kind, Name: name, Detail: detail, apply: apply})
+}
+
+// Syncer diff seeds against the roles, permissions and users managed by Passport
+type Syncer struct {
+	passport interfaces.IPassport
+	userRepo interfaces.IUserRepository
+}
+
+// NewSyncer return new Syncer pointer
+func NewSyncer(passport interfaces.IPassport, userRepo interfaces.IUserRepository) *Syncer {
+	return &Syncer{passport: passport, userRepo: userRepo}
+}
+
+// Sync plan the changes of the seed and apply them unless dryRun is set
+func (s *Syncer) Sync(seed *Seed, prune, dryRun bool) (*Plan, error) {
+	plan, err := s.Plan(seed, prune)
+	if err != nil || dryRun {
+		return plan, err
+	}
+	return plan, plan.Apply()
+}
+
+// Plan changes bringing the database in line with the seed.
+// Nothing is removed unless prune is set: roles and permissions missing from the seed are deleted,
+// permissions and parents of declared roles and roles of bootstrap users missing from the seed
+// are detached. Other users are never touched
+func (s *Syncer) Plan(seed *Seed, prune bool) (*Plan, error) {
+	plan := &Plan{}
+
+	permissions, _, err := s.passport.GetAllPermissions(&schema.PermissionOption{})
+	if err != nil {
+		return nil, err
+	}
+	roles, _, err := s.passport.GetAllRoles(&schema.RoleOption{WithPermissions: true})
+	if err != nil {
+		return nil, err
+	}
+
+	s.planPermissions(plan, seed, permissions.Origin())
+	if err := s.planRoles(plan, seed, roles.Origin(), prune); err != nil {
+		return nil, err
+	}
+	if err := s.planUsers(plan, seed, prune); err != nil {
+		return nil, err
+	}
+	if prune {
+		s.planPrune(plan, seed, permissions.Origin(), roles.Origin())
+	}
+	return plan, nil
+}
+
+// planPermissions create declared permissions and update their description and resource
+func (s *Syncer) planPermissions(plan *Plan, seed *Seed, permissions []models.Permission) {
+	existing := map[string]models.Permission{}
+	for _, permission := range permissions {
+		existing[permission.GuardName] = permission
+	}
+
+	for _, declared := range seed.Permissions {
+		declared := declared
+		resource, action := declared.Resource, declared.Action
+		if resource == "" {
+			action = ""
+		} else if action == "" {
+			action = models.AnyAction
+		}
+
+		permission, ok := existing[utils.Guard(declared.Name)]
+		if !ok {
+			plan.add(ActionCreate, KindPermission, declared.Name, describeResource(resource, action), func() error {
+				if _, err := s.passport.CreatePermission(declared.Name, declared.Description); err != nil {
+					return err
+				}
+				if resource == "" {
+					return nil
+				}
+				_, err := s.passport.SetPermissionResource(declared.Name, resource, action)
+				return err
+			})
+			continue
+		}
+
+		if permission.Name != declared.Name || permission.Description != declared.Description {
+			plan.add(ActionUpdate, KindPermission, declared.Name, "name and description", func() error {
+				_, err := s.passport.UpdatePermission(permission.ID, declared.Name, declared.Description)
+				return err
+			})
+		}
+		if permission.Resource != resource || permission.Action != action {
+			plan.add(ActionUpdate, KindPermission, declared.Name, describeResource(resource, action), func() error {
+				_, err := s.passport.SetPermissionResource(permission.ID, resource, action)
+				return err
+			})
+		}
+	}
+}
+
+// planRoles create declared roles, then align their permissions and parents once every role exists.
+// Undeclared permissions and parents are only detached when prune is set
+func (s *Syncer) planRoles(plan *Plan, seed *Seed, roles []models.Role, prune bool) error {
+	existing := map[string]models.Role{}
+	for _, role := range roles {
+		existing[role.GuardName] = role
+	}
+
+	for _, declared := range seed.Roles {
+		declared := declared
+		role, ok := existing[utils.Guard(declared.Name)]
+		if !ok {
+			plan.add(ActionCreate, KindRole, declared.Name, "", func() error {
+				_, err := s.passport.CreateRole(declared.Name, declared.Description)
+				return err
+			})
+		} else if role.Name != declared.Name || role.Description != declared.Description {
+			plan.add(ActionUpdate, KindRole, declared.Name, "name and description", func() error {
+				_, err := s.passport.UpdateRole(role.ID, declared.Name, declared.Description)
+				return err
+			})
+		}
+	}
+
+	for _, declared := range seed.Roles {
+		declared := declared
+		role, ok := existing[utils.Guard(declared.Name)]
+
+		var current []string
+		for _, permission := range role.Permissions {
+			current = append(current, permission.GuardName)
+		}
+		added, removed := diffGuards(current, declared.Permissions)
+		if !prune {
+			removed = nil
+		}
+		if len(removed) > 0 {
+			plan.add(ActionUpdate, KindRole, declared.Name, describeDiff("permissions", added, removed), func() error {
+				return s.passport.ReplacePermissionsToRole(declared.Name, append([]string{}, declared.Permissions...))
+			})
+		} else if len(added) > 0 {
+			plan.add(ActionUpdate, KindRole, declared.Name, describeDiff("permissions", added, nil), func() error {
+				return s.passport.AddPermissionsToRole(declared.Name, added)
+			})
+		}
+
+		current = nil
+		if ok {
+			parents, err := s.passport.GetParentsOfRole(role.ID)
+			if err != nil {
+				return err
+			}
+			current = parents.GuardNames()
+		}
+		added, removed = diffGuards(current, declared.Parents)
+		if !prune {
+			removed = nil
+		}
+		if len(added) > 0 || len(removed) > 0 {
+			plan.add(ActionUpdate, KindRole, declared.Name, describeDiff("parents", added, removed), func() error {
+				if len(added) > 0 {
+					if err := s.passport.AddParentsToRole(declared.Name, added); err != nil {
+						return err
+					}
+				}
+				if len(removed) > 0 {
+					return s.passport.RemoveParentsFromRole(declared.Name, removed)
+				}
+				return nil
+			})
+		}
+	}
+	return nil
+}
+
+// planUsers create missing bootstrap users and assign their declared roles
+func (s *Syncer) planUsers(plan *Plan, seed *Seed, prune bool) error {
+	for _, declared := range seed.Users {
+		declared := declared
+		user, err := s.userRepo.GetByEmail(declared.Email)
+		if err != nil {
+			plan.add(ActionCreate, KindUser, declared.Email, describeDiff("roles", utils.GuardArray(declared.Roles), nil), func() error {
+				return s.createUser(declared)
+			})
+			continue
+		}
+
+		assigned, _, err := s.passport.GetRolesOfUser(user.ID, &schema.RoleOption{})
+		if err != nil {
+			return err
+		}
+		current := assigned.GuardNames()
+		var primary string
+		if user.RoleID != "" {
+			if role, err := s.passport.GetRole(user.RoleID, false); err == nil {
+				primary = role.GuardName
+			}
+		}
+
+		if primary != "" {
+			current = append(current, primary)
+		}
+		added, removed := diffGuards(current, declared.Roles)
+		if prune {
+			// the primary role is kept on the user record
+			removed = removeGuard(removed, primary)
+		} else {
+			removed = nil
+		}
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		userID := user.ID
+		plan.add(ActionUpdate, KindUser, declared.Email, describeDiff("roles", added, removed), func() error {
+			if len(added) > 0 {
+				if err := s.passport.AddRolesToUser(userID, added, nil); err != nil {
+					return err
+				}
+			}
+			if len(removed) > 0 {
+				return s.passport.RemoveRolesFromUser(userID, removed)
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// createUser create the bootstrap user with its first role as primary role
+func (s *Syncer) createUser(declared UserSeed) error {
+	user := &models.User{
+		Username: declared.Username,
+		Email:    declared.Email,
+		Password: declared.Password,
+		FullName: declared.FullName,
+	}
+	if len(declared.Roles) > 0 {
+		role, err := s.passport.GetRole(declared.Roles[0], false)
+		if err != nil {
+			return err
+		}
+		user.RoleID = role.ID
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return err
+	}
+	if len(declared.Roles) == 0 {
+		return nil
+	}
+	return s.passport.AddRolesToUser(user.ID, append([]string{}, declared.Roles...), nil)
+}
+
+// planPrune delete roles and permissions missing from the seed
+func (s *Syncer) planPrune(plan *Plan, seed *Seed, permissions []models.Permission, roles []models.Role) {
+	declared := map[string]bool{}
+	for _, role := range seed.Roles {
+		declared[utils.Guard(role.Name)] = true
+	}
+	for _, role := range roles {
+		role := role
+		if !declared[role.GuardName] {
+			plan.add(ActionDelete, KindRole, role.Name, "", func() error {
+				return s.passport.DeleteRole(role.ID)
+			})
+		}
+	}
+
+	declared = map[string]bool{}
+	for _, permission := range seed.Permissions {
+		declared[utils.Guard(permission.Name)] = true
+	}
+	for _, permission := range permissions {
+		permission := permission
+		if !declared[permission.GuardName] {
+			plan.add(ActionDelete, KindPermission, permission.Name, "", func() error {
+				return s.passport.DeletePermission(permission.ID)
+			})
+		}
+	}
+}
+
+// diffGuards guard names of the declared names missing from current and of current missing from declared
+func diffGuards(current []string, declared []string) (added, removed []string) {
+	guards := utils.GuardArray(declared)
+	for _, guard := range utils.RemoveDuplicateValues(guards) {
+		if !utils.InArray(guard, current) {
+			added = append(added, guard)
+		}
+	}
+	for _, guard := range utils.RemoveDuplicateValues(current) {
+		if !utils.InArray(guard, guards) {
+			removed = append(removed, guard)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// removeGuard guards without the given guard
+func removeGuard(guards []string, guard string) (result []string) {
+	for _, g := range guards {
+		if g != guard {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+func describeResource(resource, action string) string {
+	if resource == "" {
+		return ""
+	}
+	return fmt.Sprintf("resource %s %s", action, resource)
+}
+
+func describeDiff(field string, added, removed []string) string {
+	var parts []string
+	for _, guard := range added {
+		parts = append(parts, "+"+guard)
+	}
+	for _, guard := range removed {
+		parts = append(parts, "-"+guard)
+	}
+	return field + " " + strings.Join(parts, " ")
+}
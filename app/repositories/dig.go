@@ -6,5 +6,14 @@ import "go.uber.org/dig"
 func Inject(container *dig.Container) error {
 	_ = container.Provide(NewUserRepository)
 	_ = container.Provide(NewRoleRepository)
+	_ = container.Provide(NewPermissionRepo)
+	_ = container.Provide(NewRefreshTokenRepository)
+	_ = container.Provide(NewSessionRepository)
+	_ = container.Provide(NewAuditRepository)
+	_ = container.Provide(NewOAuthClientRepository)
+	_ = container.Provide(NewOAuthCodeRepository)
+	_ = container.Provide(NewMFARepository)
+	_ = container.Provide(NewUserTokenRepository)
+	_ = container.Provide(NewAPIKeyRepository)
 	return nil
 }
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/errors"
+	"time"
+)
+
+// APIKeyRepo api key repository struct
+type APIKeyRepo struct {
+	db interfaces.IDatabase
+}
+
+// NewAPIKeyRepository return new IAPIKeyRepository interface
+func NewAPIKeyRepository(db interfaces.IDatabase) interfaces.IAPIKeyRepository {
+	return &APIKeyRepo{db: db}
+}
+
+// Create new api key
+func (r *APIKeyRepo) Create(key *models.APIKey) error {
+	if err := r.db.GetInstance().Create(key).Error; err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// GetByPrefix get api key by its lookup prefix
+func (r *APIKeyRepo) GetByPrefix(prefix string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.GetInstance().Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &key, nil
+}
+
+// ListByUser list api keys of user, newest first
+func (r *APIKeyRepo) ListByUser(userID string) (*[]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.GetInstance().Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	if err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &keys, nil
+}
+
+// Revoke revoke api key of user.
+// Return false if the key does not exist or has already been revoked
+func (r *APIKeyRepo) Revoke(userID string, id string) (bool, error) {
+	result := r.db.GetInstance().Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, errors.ErrorDatabaseUpdate.Newm(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Touch record last use of api key
+func (r *APIKeyRepo) Touch(id string, usedAt time.Time) error {
+	err := r.db.GetInstance().Model(&models.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
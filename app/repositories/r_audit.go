@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/errors"
+)
+
+// AuditRepo audit event repository struct
+type AuditRepo struct {
+	db interfaces.IDatabase
+}
+
+// NewAuditRepository return new IAuditRepository interface
+func NewAuditRepository(db interfaces.IDatabase) interfaces.IAuditRepository {
+	return &AuditRepo{db: db}
+}
+
+// Create new audit event
+func (r *AuditRepo) Create(event *models.AuditEvent) error {
+	if err := r.db.GetInstance().Create(event).Error; err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// MFARepo mfa repository struct
+type MFARepo struct {
+	db interfaces.IDatabase
+}
+
+// NewMFARepository return new IMFARepository interface
+func NewMFARepository(db interfaces.IDatabase) interfaces.IMFARepository {
+	return &MFARepo{db: db}
+}
+
+// GetByUserID get totp secret of user
+func (r *MFARepo) GetByUserID(userID string) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	if err := r.db.GetInstance().Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &mfa, nil
+}
+
+// IsEnabled the user has a confirmed totp secret
+func (r *MFARepo) IsEnabled(userID string) (bool, error) {
+	var count int64
+	err := r.db.GetInstance().Model(&models.UserMFA{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return false, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return count > 0, nil
+}
+
+// Save replace totp secret of user
+func (r *MFARepo) Save(mfa *models.UserMFA) error {
+	err := r.db.GetInstance().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", mfa.UserID).Delete(&models.UserMFA{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(mfa).Error
+	})
+	if err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// Confirm enable totp of user
+func (r *MFARepo) Confirm(userID string) error {
+	err := r.db.GetInstance().Model(&models.UserMFA{}).
+		Where("user_id = ?", userID).
+		Update("confirmed_at", time.Now()).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
+
+// UseStep record the time step of an accepted code.
+// Return false if a code of the same or a later step has already been used
+func (r *MFARepo) UseStep(userID string, step int64) (bool, error) {
+	result := r.db.GetInstance().Model(&models.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, errors.ErrorDatabaseUpdate.Newm(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Delete remove totp secret, recovery codes and pending challenges of user
+func (r *MFARepo) Delete(userID string) error {
+	err := r.db.GetInstance().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserMFA{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.MFAChallenge{}).Error
+	})
+	if err != nil {
+		return errors.ErrorDatabaseDelete.Newm(err.Error())
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes replace recovery codes of user
+func (r *MFARepo) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	err := r.db.GetInstance().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]models.MFARecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.MFARecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// UseRecoveryCode mark recovery code as used.
+// Return false if the code does not exist or has already been used
+func (r *MFARepo) UseRecoveryCode(userID string, codeHash string) (bool, error) {
+	result := r.db.GetInstance().Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Limit(1).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, errors.ErrorDatabaseUpdate.Newm(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateChallenge create pending login challenge
+func (r *MFARepo) CreateChallenge(challenge *models.MFAChallenge) error {
+	if err := r.db.GetInstance().Create(challenge).Error; err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// GetChallengeByHash get pending login challenge by hash of its token
+func (r *MFARepo) GetChallengeByHash(tokenHash string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	if err := r.db.GetInstance().Where("token_hash = ?", tokenHash).First(&challenge).Error; err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &challenge, nil
+}
+
+// IncrementChallengeAttempts count an attempt of the challenge while fewer than max attempts were made.
+// Return false when no attempt is left, concurrent attempts cannot exceed max
+func (r *MFARepo) IncrementChallengeAttempts(id string, max int) (bool, error) {
+	result := r.db.GetInstance().Model(&models.MFAChallenge{}).
+		Where("id = ? AND attempts < ?", id, max).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return false, errors.ErrorDatabaseUpdate.Newm(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UseChallenge mark challenge as completed.
+// Return false if the challenge has already been used
+func (r *MFARepo) UseChallenge(id string) (bool, error) {
+	result := r.db.GetInstance().Model(&models.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, errors.ErrorDatabaseUpdate.Newm(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
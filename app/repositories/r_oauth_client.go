@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/pkg/errors"
+	"time"
+)
+
+// OAuthClientRepo oauth client repository struct
+type OAuthClientRepo struct {
+	db interfaces.IDatabase
+}
+
+// NewOAuthClientRepository return new IOAuthClientRepository interface
+func NewOAuthClientRepository(db interfaces.IDatabase) interfaces.IOAuthClientRepository {
+	return &OAuthClientRepo{db: db}
+}
+
+// Create new oauth client
+func (r *OAuthClientRepo) Create(client *models.OAuthClient) error {
+	if err := r.db.GetInstance().Create(client).Error; err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// GetByID get oauth client by id
+func (r *OAuthClientRepo) GetByID(id string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := r.db.GetInstance().Where("id = ?", id).First(&client).Error; err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &client, nil
+}
+
+// List oauth clients
+func (r *OAuthClientRepo) List(param *schema.OAuthClientQueryParam) (*[]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	query := r.db.GetInstance().Order("created_at DESC").Offset(param.Offset)
+	if param.Limit > 0 {
+		query = query.Limit(param.Limit)
+	}
+	if err := query.Find(&clients).Error; err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &clients, nil
+}
+
+// Revoke revoke oauth client
+func (r *OAuthClientRepo) Revoke(id string) error {
+	err := r.db.GetInstance().Model(&models.OAuthClient{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
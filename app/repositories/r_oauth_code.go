@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/errors"
+	"time"
+)
+
+// OAuthCodeRepo authorization code repository struct
+type OAuthCodeRepo struct {
+	db interfaces.IDatabase
+}
+
+// NewOAuthCodeRepository return new IOAuthCodeRepository interface
+func NewOAuthCodeRepository(db interfaces.IDatabase) interfaces.IOAuthCodeRepository {
+	return &OAuthCodeRepo{db: db}
+}
+
+// Create new authorization code
+func (r *OAuthCodeRepo) Create(code *models.OAuthAuthorizationCode) error {
+	if err := r.db.GetInstance().Create(code).Error; err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// GetByHash get authorization code by hash of the code
+func (r *OAuthCodeRepo) GetByHash(codeHash string) (*models.OAuthAuthorizationCode, error) {
+	var code models.OAuthAuthorizationCode
+	if err := r.db.GetInstance().Where("code_hash = ?", codeHash).First(&code).Error; err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &code, nil
+}
+
+// MarkUsed mark authorization code as exchanged.
+// Return false if the code has already been used
+func (r *OAuthCodeRepo) MarkUsed(id string) (bool, error) {
+	result := r.db.GetInstance().Model(&models.OAuthAuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, errors.ErrorDatabaseUpdate.Newm(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/shasw94/projX/app/repositories/scopes"
 	"github.com/shasw94/projX/app/schema"
 	"gorm.io/gorm"
+	"time"
 )
 
 type PermissionRepo struct {
@@ -64,16 +65,16 @@ func (repository *PermissionRepo) GetPermissionsByGuardNames(guardNames []string
 // @param repositories_scopes.GormPager
 // @return []string, int64, error
 func (repository *PermissionRepo) GetPermissionIDs(pagination scopes.GormPager) (permissionIDs []string, totalCount int64, err error) {
-	err = repository.db.GetInstance().Model(&models.Permission{}).Count(&totalCount).Scopes(repository.paginate(pagination)).Pluck("permissions.id", &permissionIDs).Error
+	err = repository.db.GetInstance().Model(&models.Permission{}).Count(&totalCount).Order("permissions.created_at, permissions.id").Scopes(repository.paginate(pagination)).Pluck("permissions.id", &permissionIDs).Error
 	return
 }
 
-// GetDirectPermissionIDsOfUserByID get direct permission ids of user. (with pagination)
+// GetDirectPermissionIDsOfUserByID get ids of the permissions actively granted to user directly. (with pagination)
 // @param string
 // @param repositories_scopes.GormPager
 // @return []string, int64, error
 func (repository *PermissionRepo) GetDirectPermissionIDsOfUserByID(userID string, pagination scopes.GormPager) (permissionIDs []string, totalCount int64, err error) {
-	err = repository.db.GetInstance().Table("user_permissions").Where("user_permissions.user_id = ?", userID).Count(&totalCount).Scopes(repository.paginate(pagination)).Pluck("user_permissions.permission_id", &permissionIDs).Error
+	err = repository.db.GetInstance().Table("user_permissions").Scopes(scopes.ActiveGrant("user_permissions", time.Now())).Where("user_permissions.user_id = ?", userID).Count(&totalCount).Scopes(repository.paginate(pagination)).Pluck("user_permissions.permission_id", &permissionIDs).Error
 	return
 }
 
@@ -92,7 +93,7 @@ func (repository *PermissionRepo) GetPermissionIDsOfRolesByIDs(roleIDs []string,
 // @param *models.Permission
 // @return error
 func (repository *PermissionRepo) FirstOrCreate(permission *models.Permission) error {
-	return repository.db.GetInstance().Where(models.Role{GuardName: permission.GuardName}).FirstOrCreate(permission).Error
+	return repository.db.GetInstance().Where(models.Permission{GuardName: permission.GuardName}).FirstOrCreate(permission).Error
 }
 
 // Updates update permission.
@@ -112,6 +113,10 @@ func (repository *PermissionRepo) Delete(permission *models.Permission) (err err
 			tx.Rollback()
 			return err
 		}
+		if err := tx.Exec("DELETE FROM role_permissions WHERE permission_id = ?", permission.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
 		if err := tx.Delete(permission).Error; err != nil {
 			tx.Rollback()
 			return err
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/errors"
+	"time"
+)
+
+// RefreshTokenRepo refresh token repository struct
+type RefreshTokenRepo struct {
+	db interfaces.IDatabase
+}
+
+// NewRefreshTokenRepository return new IRefreshTokenRepository interface
+func NewRefreshTokenRepository(db interfaces.IDatabase) interfaces.IRefreshTokenRepository {
+	return &RefreshTokenRepo{db: db}
+}
+
+// Create new refresh token
+func (r *RefreshTokenRepo) Create(token *models.RefreshToken) error {
+	if err := r.db.GetInstance().Create(token).Error; err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// GetByID get refresh token by jti
+func (r *RefreshTokenRepo) GetByID(id string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.GetInstance().Where("id = ?", id).First(&token).Error; err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &token, nil
+}
+
+// MarkUsed mark refresh token as used and replaced by another one.
+// Return false if the token has already been used
+func (r *RefreshTokenRepo) MarkUsed(id string, replacedBy string) (bool, error) {
+	result := r.db.GetInstance().Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Updates(map[string]interface{}{"used_at": time.Now(), "replaced_by": replacedBy})
+	if result.Error != nil {
+		return false, errors.ErrorDatabaseUpdate.Newm(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RevokeFamily revoke all refresh tokens of the family
+func (r *RefreshTokenRepo) RevokeFamily(familyID string) error {
+	err := r.db.GetInstance().Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
+
+// RevokeByUser revoke all refresh tokens of the user
+func (r *RefreshTokenRepo) RevokeByUser(userID string) error {
+	err := r.db.GetInstance().Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/shasw94/projX/app/schema"
 	"github.com/shasw94/projX/pkg/errors"
 	"gorm.io/gorm"
+	"time"
 )
 
 type RoleRepo struct {
@@ -143,19 +144,19 @@ func (r *RoleRepo) GetRolesByGuardNamesWithPermissions(guardNames []string) (*sc
 // @param repositories_scopes.GormPager
 // @return []uint, int64, error
 func (r *RoleRepo) GetRoleIDs(pagination scopes.GormPager) (roleIDs []string, totalCount int64, err error) {
-	err = r.db.GetInstance().Model(&models.Role{}).Count(&totalCount).Scopes(r.paginate(pagination)).Pluck("roles.id", &roleIDs).Error
+	err = r.db.GetInstance().Model(&models.Role{}).Count(&totalCount).Order("roles.created_at, roles.id").Scopes(r.paginate(pagination)).Pluck("roles.id", &roleIDs).Error
 	if err != nil {
 		return nil, 0, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
 	return
 }
 
-// GetRoleIDsOfUser get role ids of user. (with pagination)
+// GetRoleIDsOfUser get ids of the roles actively assigned to user. (with pagination)
 // @param uint
 // @param repositories_scopes.GormPager
 // @return []uint, int64, error
 func (r *RoleRepo) GetRoleIDsOfUser(userID string, pagination scopes.GormPager) (roleIDs []string, totalCount int64, err error) {
-	err = r.db.GetInstance().Table("user_roles").Where("user_roles.user_id = ?", userID).Count(&totalCount).Scopes(r.paginate(pagination)).Pluck("user_roles.role_id", &roleIDs).Error
+	err = r.db.GetInstance().Table("user_roles").Scopes(scopes.ActiveGrant("user_roles", time.Now())).Where("user_roles.user_id = ?", userID).Count(&totalCount).Scopes(r.paginate(pagination)).Pluck("user_roles.role_id", &roleIDs).Error
 	if err != nil {
 		return nil, 0, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
@@ -174,6 +175,17 @@ func (r *RoleRepo) GetRoleIDsOfPermission(permissionID string, pagination scopes
 	return
 }
 
+// GetParentIDsOfRoles get ids of the direct parents of roles.
+// @param []string
+// @return []string, error
+func (r *RoleRepo) GetParentIDsOfRoles(roleIDs []string) (parentIDs []string, err error) {
+	err = r.db.GetInstance().Table("role_parents").Distinct("role_parents.parent_id").Where("role_parents.role_id IN (?)", roleIDs).Pluck("role_parents.parent_id", &parentIDs).Error
+	if err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return
+}
+
 // FirstOrCreate & Updates & Delete
 
 // FirstOrCreate create new role if name not exist.
@@ -208,6 +220,14 @@ func (r *RoleRepo) Delete(role *models.Role) (err error) {
 			tx.Rollback()
 			return err
 		}
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Exec("DELETE FROM role_parents WHERE role_id = ? OR parent_id = ?", role.ID, role.ID).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
 		if err := tx.Delete(role).Error; err != nil {
 			tx.Rollback()
 			return err
@@ -249,35 +269,71 @@ func (r *RoleRepo) ClearPermissions(role *models.Role) (err error) {
 	return r.db.GetInstance().Model(role).Association("Permissions").Clear()
 }
 
+// AddParents add parents to role.
+// @param *models.Role
+// @param *schema.Roles
+// @return error
+func (r *RoleRepo) AddParents(role *models.Role, parents *schema.Roles) error {
+	return r.db.GetInstance().Model(role).Association("Parents").Append(parents.Origin())
+}
+
+// RemoveParents remove parents of role.
+// @param *models.Role
+// @param *schema.Roles
+// @return error
+func (r *RoleRepo) RemoveParents(role *models.Role, parents *schema.Roles) error {
+	return r.db.GetInstance().Model(role).Association("Parents").Delete(parents.Origin())
+}
+
 // Controls
 
-// HasPermission does the role or any of the roles have given permission?
+// guardPatternQuery join of the permissions granted to the roles with the target permissions they cover.
+// A grant covers a target by id or when the guard name of the target matches the grant, every * segment
+// of the grant is turned into a regexp matching one or more segments.
+const guardPatternQuery = `FROM role_permissions
+JOIN permissions granted ON granted.id = role_permissions.permission_id
+JOIN permissions target ON target.id IN (?) AND (target.id = granted.id OR
+	target.guard_name REGEXP CONCAT('^', REPLACE(REPLACE(granted.guard_name, '.', ?), '*', ?), '$'))
+WHERE role_permissions.role_id IN (?)`
+
+// guardPatternArgs arguments of guardPatternQuery
+func guardPatternArgs(roles *schema.Roles, permissionIDs []string) []interface{} {
+	return []interface{}{permissionIDs, `\.`, `[^.]+(\.[^.]+)*`, roles.IDs()}
+}
+
+// countCoveredPermissions count distinct target permissions granted by any of the roles, wildcard grants included
+func (r *RoleRepo) countCoveredPermissions(roles *schema.Roles, permissionIDs []string) (count int64, err error) {
+	if roles.Len() == 0 || len(permissionIDs) == 0 {
+		return 0, nil
+	}
+	err = r.db.GetInstance().Raw("SELECT COUNT(DISTINCT target.id) "+guardPatternQuery, guardPatternArgs(roles, permissionIDs)...).Scan(&count).Error
+	return
+}
+
+// HasPermission does the role or any of the roles have given permission? Wildcard grants cover the permissions under them.
 // @param *schema.Roles
 // @param models.Permission
 // @return bool, error
 func (r *RoleRepo) HasPermission(roles *schema.Roles, permission *models.Permission) (b bool, err error) {
-	var count int64
-	err = r.db.GetInstance().Table("role_permissions").Where("role_permissions.role_id IN (?)", roles.IDs()).Where("role_permissions.permission_id = ?", permission.ID).Count(&count).Error
+	count, err := r.countCoveredPermissions(roles, []string{permission.ID})
 	return count > 0, err
 }
 
-// HasAllPermissions does the role or roles have all the given permissions?
+// HasAllPermissions do the roles together have all the given permissions? Wildcard grants cover the permissions under them.
 // @param *schema.Roles
 // @param *schema.Permission
 // @return bool, error
 func (r *RoleRepo) HasAllPermissions(roles *schema.Roles, permissions *schema.Permission) (b bool, err error) {
-	var count int64
-	err = r.db.GetInstance().Table("role_permissions").Where("role_permissions.role_id IN (?)", roles.IDs()).Where("role_permissions.permission_id IN (?)", permissions.IDs()).Count(&count).Error
-	return roles.Len()*permissions.Len() == count, err
+	count, err := r.countCoveredPermissions(roles, permissions.IDs())
+	return count == permissions.Len(), err
 }
 
-// HasAnyPermissions does the role or roles have any of the given permissions?
+// HasAnyPermissions does the role or roles have any of the given permissions? Wildcard grants cover the permissions under them.
 // @param *schema.Roles
 // @param *schema.Permission
 // @return bool, error
 func (r *RoleRepo) HasAnyPermissions(roles *schema.Roles, permissions *schema.Permission) (b bool, err error) {
-	var count int64
-	err = r.db.GetInstance().Table("role_permissions").Where("role_permissions.role_id IN (?)", roles.IDs()).Where("role_permissions.permission_id IN (?)", permissions.IDs()).Count(&count).Error
+	count, err := r.countCoveredPermissions(roles, permissions.IDs())
 	return count > 0, err
 }
 
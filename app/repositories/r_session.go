@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/errors"
+	"time"
+)
+
+// SessionRepo session repository struct
+type SessionRepo struct {
+	db interfaces.IDatabase
+}
+
+// NewSessionRepository return new ISessionRepository interface
+func NewSessionRepository(db interfaces.IDatabase) interfaces.ISessionRepository {
+	return &SessionRepo{db: db}
+}
+
+// Create new session
+func (r *SessionRepo) Create(session *models.Session) error {
+	if err := r.db.GetInstance().Create(session).Error; err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// GetByID get session by id
+func (r *SessionRepo) GetByID(id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.GetInstance().Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &session, nil
+}
+
+// ListActiveByUser list sessions of user which are not revoked or expired
+func (r *SessionRepo) ListActiveByUser(userID string) (*[]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.GetInstance().
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &sessions, nil
+}
+
+// Touch save last used info and new expiry of session after its refresh token is rotated
+func (r *SessionRepo) Touch(session *models.Session) error {
+	err := r.db.GetInstance().Model(&models.Session{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+		"ip":           session.IP,
+		"last_used_at": session.LastUsedAt,
+		"expires_at":   session.ExpiresAt,
+	}).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
+
+// Revoke revoke session
+func (r *SessionRepo) Revoke(id string) error {
+	err := r.db.GetInstance().Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
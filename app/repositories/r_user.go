@@ -5,12 +5,16 @@ import (
 	"github.com/shasw94/projX/app/interfaces"
 	"github.com/shasw94/projX/app/models"
 	"github.com/shasw94/projX/app/models/pivot"
+	"github.com/shasw94/projX/app/repositories/scopes"
 	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/logger"
 	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/hasher"
 	"github.com/shasw94/projX/pkg/utils"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"sync"
+	"time"
 )
 
 // UserRepo user repository struct
@@ -47,9 +51,10 @@ func (u *UserRepo) GetByID(id string) (*models.User, error) {
 	return &user, nil
 }
 
-func (u *UserRepo) GetUserByToken(token string) (*models.User, error) {
-	var user models.User
-	if err := u.db.GetInstance().Model(&models.User{}).Where("refresh_token = ? ", token).First(&user).Error; err != nil {
+// GetByEmail get user by email address
+func (u *UserRepo) GetByEmail(email string) (*models.User, error) {
+	user := models.User{}
+	if err := u.db.GetInstance().Model(&models.User{}).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
 	return &user, nil
@@ -69,27 +74,72 @@ func (u *UserRepo) List(param *schema.UserQueryParam) (*[]models.User, error) {
 	return &user, nil
 }
 
+// dummy hash of the default hasher, compared when the user does not exist
+// so unknown usernames take as long as wrong passwords
+var dummy struct {
+	sync.Mutex
+	hasher *hasher.Hasher
+	hash   string
+}
+
+// dummyPasswordHash return hash of a dummy password by the default hasher
+func dummyPasswordHash() string {
+	dummy.Lock()
+	defer dummy.Unlock()
+
+	h := hasher.Default()
+	if dummy.hasher != h {
+		hash, err := h.Hash("projX-dummy-password")
+		if err != nil {
+			return ""
+		}
+		dummy.hasher, dummy.hash = h, hash
+	}
+	return dummy.hash
+}
+
+// Login check username and password, unknown username and wrong password both return ErrorLoginFailed.
+// Passwords hashed by another algorithm or other parameters than the preferred ones are rehashed
 func (u *UserRepo) Login(item *schema.LoginBodyParams) (*models.User, error) {
 	user := &models.User{}
-	if err := u.db.GetInstance().Model(&models.User{}).Where("username = ?", item.Username).First(&user).Error; err != nil {
+	err := u.db.GetInstance().Model(&models.User{}).Where("username = ?", item.Username).First(&user).Error
+	if err == gorm.ErrRecordNotFound {
+		_, _ = hasher.Default().Verify(dummyPasswordHash(), item.Password)
+		return nil, errors.ErrorLoginFailed.New()
+	}
+	if err != nil {
 		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
 	}
 
-	passErr := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(item.Password))
-	if passErr == bcrypt.ErrMismatchedHashAndPassword && passErr != nil {
-		return nil, errors.ErrorInvalidPassword.Newm("invalid password")
+	if ok, err := hasher.Default().Verify(user.Password, item.Password); err != nil || !ok {
+		return nil, errors.ErrorLoginFailed.New()
+	}
+
+	if hasher.Default().NeedsRehash(user.Password) {
+		if err := u.rehashPassword(user, item.Password); err != nil {
+			logger.Error("Failed to rehash password: ", err)
+		}
 	}
 
 	return user, nil
 }
 
-func (u *UserRepo) RemoveToken(userID string) (*models.User, error) {
-	var body = map[string]interface{}{"refresh_token": ""}
-	var change models.User
-	if err := u.db.GetInstance().Model(&change).Where("id = ?", userID).Updates(body).Error; err != nil {
-		return nil, errors.ErrorDatabaseUpdate.Newm(err.Error())
+// rehashPassword replace password hash of user by one of the preferred algorithm,
+// skipped if the password has been changed concurrently
+func (u *UserRepo) rehashPassword(user *models.User, password string) error {
+	hashed, err := hasher.Default().Hash(password)
+	if err != nil {
+		return err
 	}
-	return &change, nil
+
+	err = u.db.GetInstance().Model(&models.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashed).Error
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	return nil
 }
 
 func (u *UserRepo) Update(userID string, bodyParam *schema.UserUpdateBodyParam) (*models.User, error) {
@@ -107,19 +157,50 @@ func (u *UserRepo) Update(userID string, bodyParam *schema.UserUpdateBodyParam)
 	return &change, nil
 }
 
-// AddPermissions and direct permission to user
+// UpdatePassword store password hash of user, the password has to be hashed by the caller
+func (u *UserRepo) UpdatePassword(userID string, hashed string) error {
+	err := u.db.GetInstance().Model(&models.User{}).Where("id = ?", userID).Update("password", hashed).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
+
+// VerifyEmail mark email address of user as verified
+func (u *UserRepo) VerifyEmail(userID string) error {
+	err := u.db.GetInstance().Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", time.Now()).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
+
+// grantColumns terms updated when an assignment is granted again
+var grantColumns = []string{"starts_at", "expires_at", "granted_by", "reason"}
+
+// AddPermissions and direct permission to user, granting an assigned permission again replaces the terms of the grant
 // @param string
 // @param schema.Permission
+// @param *schema.Grant
 // @return error
-func (u *UserRepo) AddPermissions(userID string, permissions schema.Permission) error {
+func (u *UserRepo) AddPermissions(userID string, permissions schema.Permission, grant *schema.Grant) error {
 	var userPermissions []pivot.UserPermission
 	for _, permission := range permissions.Origin() {
-		userPermissions = append(userPermissions, pivot.UserPermission{
+		userPermission := pivot.UserPermission{
 			UserID:       userID,
 			PermissionID: permission.ID,
-		})
+		}
+		if grant != nil {
+			userPermission.StartsAt = grant.StartsAt
+			userPermission.ExpiresAt = grant.ExpiresAt
+			userPermission.GrantedBy = grant.GrantedBy
+			userPermission.Reason = grant.Reason
+		}
+		userPermissions = append(userPermissions, userPermission)
 	}
-	return u.db.GetInstance().Clauses(clause.OnConflict{DoNothing: true}).Create(&userPermissions).Error
+	return u.db.GetInstance().Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns(grantColumns)}).Create(&userPermissions).Error
 }
 
 func (u *UserRepo) ReplacePermissions(userID string, permissions schema.Permission) error {
@@ -161,15 +242,27 @@ func (u *UserRepo) ClearPermissions(userID string) (err error) {
 	return u.db.GetInstance().Where("user_permissions.user_id = ?", userID).Delete(&pivot.UserPermission{}).Error
 }
 
-func (u *UserRepo) AddRoles(userId string, roles schema.Roles) error {
+// AddRoles assign roles to user, assigning a role again replaces the terms of the grant
+// @param string
+// @param schema.Roles
+// @param *schema.Grant
+// @return error
+func (u *UserRepo) AddRoles(userId string, roles schema.Roles, grant *schema.Grant) error {
 	var userRoles []pivot.UserRole
 	for _, role := range roles.Origin() {
-		userRoles = append(userRoles, pivot.UserRole{
+		userRole := pivot.UserRole{
 			UserID: userId,
 			RoleID: role.ID,
-		})
+		}
+		if grant != nil {
+			userRole.StartsAt = grant.StartsAt
+			userRole.ExpiresAt = grant.ExpiresAt
+			userRole.GrantedBy = grant.GrantedBy
+			userRole.Reason = grant.Reason
+		}
+		userRoles = append(userRoles, userRole)
 	}
-	return u.db.GetInstance().Clauses(clause.OnConflict{DoNothing: true}).Create(&userRoles).Error
+	return u.db.GetInstance().Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns(grantColumns)}).Create(&userRoles).Error
 }
 
 func (u *UserRepo) ReplaceRoles(userId string, roles schema.Roles) error {
@@ -210,37 +303,97 @@ func (u *UserRepo) ClearRoles(userId string) (err error) {
 
 func (u *UserRepo) HasRole(userId string, role models.Role) (b bool, err error) {
 	var count int64
-	err = u.db.GetInstance().Table("user_roles").Where("user_roles.user_id = ?", userId).Where("user_roles.role_id = ?", role.ID).Count(&count).Error
+	err = u.db.GetInstance().Table("user_roles").Scopes(scopes.ActiveGrant("user_roles", time.Now())).Where("user_roles.user_id = ?", userId).Where("user_roles.role_id = ?", role.ID).Count(&count).Error
 	return count > 0, err
 }
 
 func (u *UserRepo) HasAllRoles(userId string, roles schema.Roles) (b bool, err error) {
 	var count int64
-	err = u.db.GetInstance().Table("user_roles").Where("user_roles.user_id = ?", userId).Where("user_roles.role_id IN (?)", roles.IDs()).Count(&count).Error
+	err = u.db.GetInstance().Table("user_roles").Scopes(scopes.ActiveGrant("user_roles", time.Now())).Where("user_roles.user_id = ?", userId).Where("user_roles.role_id IN (?)", roles.IDs()).Count(&count).Error
 	return roles.Len() == count, err
 }
 
 func (u *UserRepo) HasAnyRoles(userID string, roles schema.Roles) (b bool, err error) {
 	var count int64
-	err = u.db.GetInstance().Table("user_roles").Where("user_roles.user_id = ?", userID).Where("user_roles.role_id IN (?)", roles.IDs()).Count(&count).Error
+	err = u.db.GetInstance().Table("user_roles").Scopes(scopes.ActiveGrant("user_roles", time.Now())).Where("user_roles.user_id = ?", userID).Where("user_roles.role_id IN (?)", roles.IDs()).Count(&count).Error
 	return count > 0, err
 }
 
 // HasDirectPermission does the user have the given permission? (not including the permissios of the roles)
 func (u *UserRepo) HasDirectPermission(userID string, permission models.Permission) (b bool, err error) {
 	var count int64
-	err = u.db.GetInstance().Table("user_permissions").Where("user_permissions.user_id = ?", userID).Where("user_permissions.permission_id = ?", permission.ID).Count(&count).Error
+	err = u.db.GetInstance().Table("user_permissions").Scopes(scopes.ActiveGrant("user_permissions", time.Now())).Where("user_permissions.user_id = ?", userID).Where("user_permissions.permission_id = ?", permission.ID).Count(&count).Error
 	return count > 0, err
 }
 
 func (u *UserRepo) HasAllDirectPermissions(userID string, permissions schema.Permission) (b bool, err error) {
 	var count int64
-	err = u.db.GetInstance().Table("user_permissions").Where("user_permissions.user_id = ?", userID).Where("user_permissions.permission_id IN (?)", permissions.IDs()).Count(&count).Error
+	err = u.db.GetInstance().Table("user_permissions").Scopes(scopes.ActiveGrant("user_permissions", time.Now())).Where("user_permissions.user_id = ?", userID).Where("user_permissions.permission_id IN (?)", permissions.IDs()).Count(&count).Error
 	return permissions.Len() == count, err
 }
 
 func (u *UserRepo) HasAnyDirectPermissions(userID string, permissions schema.Permission) (b bool, err error) {
 	var count int64
-	err = u.db.GetInstance().Table("user_permissions").Where("user_permissions.user_id = ?", userID).Where("user_permissions.permission_id IN (?)", permissions.IDs()).Count(&count).Error
+	err = u.db.GetInstance().Table("user_permissions").Scopes(scopes.ActiveGrant("user_permissions", time.Now())).Where("user_permissions.user_id = ?", userID).Where("user_permissions.permission_id IN (?)", permissions.IDs()).Count(&count).Error
 	return count > 0, err
 }
+
+// DeleteExpiredGrants delete role and permission assignments expired at now and return them
+// @param time.Time
+// @return []pivot.UserRole, []pivot.UserPermission, error
+func (u *UserRepo) DeleteExpiredGrants(now time.Time) (roles []pivot.UserRole, permissions []pivot.UserPermission, err error) {
+	err = u.db.GetInstance().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", now).Find(&roles).Error; err != nil {
+			return err
+		}
+		if len(roles) > 0 {
+			if err := tx.Delete(&roles).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("expires_at <= ?", now).Find(&permissions).Error; err != nil {
+			return err
+		}
+		if len(permissions) > 0 {
+			if err := tx.Delete(&permissions).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, errors.ErrorDatabaseDelete.Newm(err.Error())
+	}
+	return roles, permissions, nil
+}
+
+// CountStartedGrants number of role and permission assignments started after since and until now
+// @param time.Time
+// @param time.Time
+// @return int64, error
+func (u *UserRepo) CountStartedGrants(since, now time.Time) (int64, error) {
+	var roles, permissions int64
+	db := u.db.GetInstance()
+	if err := db.Model(&pivot.UserRole{}).Where("starts_at > ? AND starts_at <= ?", since, now).Count(&roles).Error; err != nil {
+		return 0, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	if err := db.Model(&pivot.UserPermission{}).Where("starts_at > ? AND starts_at <= ?", since, now).Count(&permissions).Error; err != nil {
+		return 0, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return roles + permissions, nil
+}
+
+// GetInactiveGrants role and permission assignments of user not started yet or expired at now
+// @param string
+// @param time.Time
+// @return []pivot.UserRole, []pivot.UserPermission, error
+func (u *UserRepo) GetInactiveGrants(userID string, now time.Time) (roles []pivot.UserRole, permissions []pivot.UserPermission, err error) {
+	db := u.db.GetInstance()
+	if err = db.Where("user_id = ? AND (starts_at > ? OR expires_at <= ?)", userID, now, now).Find(&roles).Error; err != nil {
+		return nil, nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	if err = db.Where("user_id = ? AND (starts_at > ? OR expires_at <= ?)", userID, now, now).Find(&permissions).Error; err != nil {
+		return nil, nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return roles, permissions, nil
+}
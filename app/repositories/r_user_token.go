@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/errors"
+	"time"
+)
+
+// UserTokenRepo user token repository struct
+type UserTokenRepo struct {
+	db interfaces.IDatabase
+}
+
+// NewUserTokenRepository return new IUserTokenRepository interface
+func NewUserTokenRepository(db interfaces.IDatabase) interfaces.IUserTokenRepository {
+	return &UserTokenRepo{db: db}
+}
+
+// Create new user token
+func (r *UserTokenRepo) Create(token *models.UserToken) error {
+	if err := r.db.GetInstance().Create(token).Error; err != nil {
+		return errors.ErrorDatabaseCreate.Newm(err.Error())
+	}
+	return nil
+}
+
+// GetByHash get user token of the purpose by hash of the token
+func (r *UserTokenRepo) GetByHash(purpose string, tokenHash string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := r.db.GetInstance().Where("purpose = ? AND token_hash = ?", purpose, tokenHash).First(&token).Error
+	if err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+	return &token, nil
+}
+
+// Use mark user token as used.
+// Return false if the token has already been used
+func (r *UserTokenRepo) Use(id string) (bool, error) {
+	result := r.db.GetInstance().Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, errors.ErrorDatabaseUpdate.Newm(result.Error.Error())
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// InvalidateByUser mark all unused tokens of the user and purpose as used
+func (r *UserTokenRepo) InvalidateByUser(userID string, purpose string) error {
+	err := r.db.GetInstance().Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return errors.ErrorDatabaseUpdate.Newm(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package scopes
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// ActiveGrant keep the assignments of the table that have started and not expired at now
+func ActiveGrant(table string, now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("("+table+".starts_at IS NULL OR "+table+".starts_at <= ?)", now).
+			Where("("+table+".expires_at IS NULL OR "+table+".expires_at > ?)", now)
+	}
+}
@@ -0,0 +1,9 @@
+package revocation
+
+import "go.uber.org/dig"
+
+// Inject revocation store
+func Inject(container *dig.Container) error {
+	_ = container.Provide(New)
+	return nil
+}
@@ -0,0 +1,53 @@
+package revocation
+
+import (
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	revokedAt time.Time
+	expiresAt time.Time
+}
+
+type memoryBackend struct {
+	mu      sync.RWMutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryStore return revocation store kept in memory of current process
+func NewMemoryStore(ttl time.Duration) *Store {
+	return &Store{backend: &memoryBackend{entries: map[string]memoryEntry{}}, ttl: ttl}
+}
+
+func (m *memoryBackend) set(key string, revokedAt time.Time, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			delete(m.entries, k)
+		}
+	}
+	m.entries[key] = memoryEntry{revokedAt: revokedAt, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (m *memoryBackend) latest(keys ...string) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var latest time.Time
+	now := time.Now()
+	for _, key := range keys {
+		entry, ok := m.entries[key]
+		if !ok || now.After(entry.expiresAt) {
+			continue
+		}
+		if entry.revokedAt.After(latest) {
+			latest = entry.revokedAt
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,47 @@
+package revocation
+
+import (
+	"context"
+	"github.com/go-redis/redis/v9"
+	"github.com/shasw94/projX/app/middleware/cache"
+	"strconv"
+	"time"
+)
+
+var ctx = context.Background()
+
+type redisBackend struct {
+	client *redis.Client
+}
+
+// NewRedisStore return revocation store sharing the redis connection of cache
+func NewRedisStore(r *cache.GRedis, ttl time.Duration) *Store {
+	return &Store{backend: &redisBackend{client: r.Client()}, ttl: ttl}
+}
+
+func (r *redisBackend) set(key string, revokedAt time.Time, ttl time.Duration) error {
+	return r.client.Set(ctx, key, revokedAt.Unix(), ttl).Err()
+}
+
+func (r *redisBackend) latest(keys ...string) (time.Time, error) {
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		sec, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		if t := time.Unix(sec, 0); t.After(latest) {
+			latest = t
+		}
+	}
+	return latest, nil
+}
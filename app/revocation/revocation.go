@@ -0,0 +1,139 @@
+package revocation
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/middleware/cache"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/pkg/jwt"
+	"time"
+)
+
+const (
+	keyPrefix = "revoked:"
+
+	// DefaultTTL how long session and user revocations are kept when
+	// the access token lifetime is not configured
+	DefaultTTL = 2 * time.Hour
+)
+
+// backend keeps revocation time of keys
+type backend interface {
+	set(key string, revokedAt time.Time, ttl time.Duration) error
+	// latest return the latest revocation time of the keys, zero time if none of them is revoked
+	latest(keys ...string) (time.Time, error)
+}
+
+// Store revocation store of access tokens.
+// Tokens can be revoked one by one (jti), per session (sid), per user (sub) or per oauth client (cid).
+// Revoked tokens and sessions never become valid again, a user or client revocation applies to
+// tokens issued before it. Revocation times are kept in seconds like the iat claim
+type Store struct {
+	backend backend
+	ttl     time.Duration
+}
+
+// TTL how long session and user revocations are kept, the lifetime of access tokens.
+// Access tokens must not outlive it, otherwise they become valid again once the revocation is dropped
+func TTL() time.Duration {
+	if ttl := time.Duration(config.Config.JWTAuth.Expired) * time.Second; ttl > 0 {
+		return ttl
+	}
+	return DefaultTTL
+}
+
+// New return redis revocation store if redis is available, otherwise in-memory store
+func New() interfaces.IRevocationStore {
+	ttl := TTL()
+	if r := cache.Redis(); r != nil {
+		return NewRedisStore(r, ttl)
+	}
+	return NewMemoryStore(ttl)
+}
+
+func tokenKey(jti string) string {
+	return keyPrefix + "jti:" + jti
+}
+
+func sessionKey(sessionID string) string {
+	return keyPrefix + "sid:" + sessionID
+}
+
+func userKey(userID string) string {
+	return keyPrefix + "sub:" + userID
+}
+
+func clientKey(clientID string) string {
+	return keyPrefix + "cid:" + clientID
+}
+
+// RevokeToken revoke single access token until it expires
+func (s *Store) RevokeToken(jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.backend.set(tokenKey(jti), now(), ttl)
+}
+
+// RevokeSession revoke all access tokens issued for the session so far
+func (s *Store) RevokeSession(sessionID string) error {
+	return s.backend.set(sessionKey(sessionID), now(), s.ttl)
+}
+
+// RevokeUser revoke all access tokens issued for the user so far
+func (s *Store) RevokeUser(userID string) error {
+	return s.backend.set(userKey(userID), now(), s.ttl)
+}
+
+// RevokeClient revoke all access tokens issued to the oauth client so far,
+// both its own and those acting for users
+func (s *Store) RevokeClient(clientID string) error {
+	return s.backend.set(clientKey(clientID), now(), s.ttl)
+}
+
+// IsRevoked check the access token against all revocations
+func (s *Store) IsRevoked(claims *jwt.AccessClaims) (bool, error) {
+	var keys []string
+	if claims.ID != "" {
+		keys = append(keys, tokenKey(claims.ID))
+	}
+	if claims.SessionID != "" {
+		keys = append(keys, sessionKey(claims.SessionID))
+	}
+	if len(keys) > 0 {
+		revokedAt, err := s.backend.latest(keys...)
+		if err != nil {
+			return false, err
+		}
+		if !revokedAt.IsZero() {
+			return true, nil
+		}
+	}
+
+	keys = []string{userKey(claims.Subject)}
+	// signing out the impersonating admin also revokes impersonation tokens
+	if actorID := claims.ActorID(); actorID != "" {
+		keys = append(keys, userKey(actorID))
+	}
+	if claims.ClientID != "" {
+		keys = append(keys, clientKey(claims.ClientID))
+	}
+
+	revokedAt, err := s.backend.latest(keys...)
+	if err != nil {
+		return false, err
+	}
+	if revokedAt.IsZero() {
+		return false, nil
+	}
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	// tokens issued within the second of the revocation are kept, the user may sign in again right away
+	return claims.IssuedAt.Time.Before(revokedAt), nil
+}
+
+// now revocation time truncated to the precision of the iat claim
+func now() time.Time {
+	return time.Now().Truncate(time.Second)
+}
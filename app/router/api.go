@@ -1,9 +1,12 @@
 package router
 
 import (
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/shasw94/projX/app/api"
+	"github.com/shasw94/projX/app/interfaces"
 	"github.com/shasw94/projX/app/middleware"
+	"github.com/shasw94/projX/app/models"
 	"github.com/shasw94/projX/logger"
 	"github.com/shasw94/projX/pkg/http/wrapper"
 	"github.com/shasw94/projX/pkg/jwt"
@@ -14,31 +17,124 @@ import (
 func RegisterAPI(r *gin.Engine, container *dig.Container) error {
 	err := container.Invoke(func(
 		jwt jwt.IJWTAuth,
+		revocation interfaces.IRevocationStore,
+		audit interfaces.IAuditRepository,
+		limiter interfaces.IRateLimiter,
 		authAPI *api.AuthAPI,
 		userAPI *api.UserAPI,
-		roleAPI *api.RoleAPI,
+		passportAPI *api.PassportAPI,
+		sessionAPI *api.SessionAPI,
+		wellKnownAPI *api.WellKnownAPI,
+		oauthAPI *api.OAuthAPI,
+		mfaAPI *api.MFAAPI,
+		apiKeyAPI *api.APIKeyAPI,
+		apiKeys interfaces.IAPIKeyService,
+		users interfaces.IUserService,
+		passport interfaces.IPassport,
+		enforcer *casbin.SyncedEnforcer,
 	) error {
-		jwtMiddle := middleware.UserAuthMiddleware(jwt)
+		jwtMiddle := middleware.UserAuthMiddleware(jwt, revocation, audit)
+		apiKeyMiddle := middleware.UserOrAPIKeyAuthMiddleware(jwtMiddle, middleware.APIKeyAuthMiddleware(apiKeys))
+		authLimit := middleware.RateLimitMiddleware(limiter, "auth")
+		oauthLimit := middleware.RateLimitMiddleware(limiter, "oauth")
+		adminLimit := middleware.RateLimitMiddleware(limiter, "admin")
+		apiLimit := middleware.RateLimitMiddleware(limiter, "api")
+		authz := middleware.NewAuthorizer(passport)
+		authz.RegisterLoader(models.ResourceUser, users)
+		directMiddle := authz.RequireDirect()
+		casbinMiddle := middleware.CasbinMiddleware(enforcer)
 		//corsMiddle := middleware.CORSMiddleware()
-		//casbinMiddle := middleware.CasbinMiddleware(casbinEnforcer)
 
+		r.Use(middleware.CSRFMiddleware())
+		r.GET("/.well-known/jwks.json", wellKnownAPI.JWKS)
+
+		authPath := r.Group("/", authLimit)
+		{
+			authPath.POST("/register", wrapper.Wrap(authAPI.Register))
+			authPath.POST("/login", wrapper.Wrap(authAPI.Login))
+			authPath.POST("/login/mfa", wrapper.Wrap(authAPI.LoginMFA))
+			authPath.POST("/refresh", wrapper.Wrap(authAPI.Refresh))
+			authPath.POST("/logout", jwtMiddle, wrapper.Wrap(authAPI.Logout))
+			authPath.POST("/password/change", jwtMiddle, directMiddle, wrapper.Wrap(authAPI.ChangePassword))
+			authPath.POST("/password/forgot", wrapper.Wrap(authAPI.ForgotPassword))
+			authPath.POST("/password/reset", wrapper.Wrap(authAPI.ResetPassword))
+			authPath.POST("/email/verify", wrapper.Wrap(authAPI.VerifyEmail))
+			authPath.POST("/email/verify/resend", jwtMiddle, directMiddle, wrapper.Wrap(authAPI.SendEmailVerification))
+		}
+
+		oauthPath := r.Group("/oauth", oauthLimit)
 		{
-			r.POST("/register", wrapper.Wrap(authAPI.Register))
-			r.POST("/login", wrapper.Wrap(authAPI.Login))
-			r.POST("/refresh", wrapper.Wrap(authAPI.Refresh))
-			r.POST("/logout", jwtMiddle, wrapper.Wrap(authAPI.Logout))
+			oauthPath.POST("/token", oauthAPI.Token)
+			oauthPath.POST("/authorize", jwtMiddle, directMiddle, wrapper.Wrap(oauthAPI.Authorize))
 		}
 
-		adminPath := r.Group("/admin", jwtMiddle)
+		adminPath := r.Group("/admin", jwtMiddle, adminLimit, authz.RequireAnyRole(models.RoleAdmin), casbinMiddle)
 		{
-			adminPath.POST("/roles", wrapper.Wrap(roleAPI.CreateRole))
+			adminPath.GET("/roles", wrapper.Wrap(passportAPI.ListRoles))
+			adminPath.POST("/roles", wrapper.Wrap(passportAPI.CreateRole))
+			adminPath.GET("/roles/:id", wrapper.Wrap(passportAPI.GetRole))
+			adminPath.PUT("/roles/:id", wrapper.Wrap(passportAPI.UpdateRole))
+			adminPath.DELETE("/roles/:id", wrapper.Wrap(passportAPI.DeleteRole))
+			adminPath.GET("/roles/:id/permissions", wrapper.Wrap(passportAPI.ListRolePermissions))
+			adminPath.POST("/roles/:id/permissions", wrapper.Wrap(passportAPI.AttachRolePermissions))
+			adminPath.PUT("/roles/:id/permissions", wrapper.Wrap(passportAPI.ReplaceRolePermissions))
+			adminPath.DELETE("/roles/:id/permissions/:permission_id", wrapper.Wrap(passportAPI.DetachRolePermission))
+			adminPath.GET("/roles/:id/effective-permissions", wrapper.Wrap(passportAPI.GetRoleEffectivePermissions))
+			adminPath.GET("/roles/:id/parents", wrapper.Wrap(passportAPI.ListRoleParents))
+			adminPath.POST("/roles/:id/parents", wrapper.Wrap(passportAPI.AttachRoleParents))
+			adminPath.DELETE("/roles/:id/parents/:parent_id", wrapper.Wrap(passportAPI.DetachRoleParent))
+
+			adminPath.GET("/permissions", wrapper.Wrap(passportAPI.ListPermissions))
+			adminPath.POST("/permissions", wrapper.Wrap(passportAPI.CreatePermission))
+			adminPath.GET("/permissions/:id", wrapper.Wrap(passportAPI.GetPermission))
+			adminPath.PUT("/permissions/:id", wrapper.Wrap(passportAPI.UpdatePermission))
+			adminPath.DELETE("/permissions/:id", wrapper.Wrap(passportAPI.DeletePermission))
+
+			adminPath.GET("/users/:id/roles", wrapper.Wrap(passportAPI.ListUserRoles))
+			adminPath.POST("/users/:id/roles", wrapper.Wrap(passportAPI.AssignUserRoles))
+			adminPath.PUT("/users/:id/roles", wrapper.Wrap(passportAPI.ReplaceUserRoles))
+			adminPath.DELETE("/users/:id/roles/:role_id", wrapper.Wrap(passportAPI.RemoveUserRole))
+			adminPath.GET("/users/:id/permissions", wrapper.Wrap(passportAPI.ListUserPermissions))
+			adminPath.POST("/users/:id/permissions", wrapper.Wrap(passportAPI.GrantUserPermissions))
+			adminPath.PUT("/users/:id/permissions", wrapper.Wrap(passportAPI.ReplaceUserPermissions))
+			adminPath.DELETE("/users/:id/permissions/:permission_id", wrapper.Wrap(passportAPI.RevokeUserPermission))
+			adminPath.GET("/authz/explain", wrapper.Wrap(passportAPI.ExplainAuthz))
+			adminPath.POST("/users/:id/signout", wrapper.Wrap(authAPI.SignOutUser))
+			adminPath.POST("/users/:id/unlock", wrapper.Wrap(authAPI.UnlockUser))
+			adminPath.POST("/users/:id/impersonate", wrapper.Wrap(authAPI.Impersonate))
+			adminPath.DELETE("/users/:id/mfa", wrapper.Wrap(mfaAPI.Reset))
+
+			adminPath.POST("/oauth/clients", wrapper.Wrap(oauthAPI.CreateClient))
+			adminPath.GET("/oauth/clients", wrapper.Wrap(oauthAPI.ListClients))
+			adminPath.GET("/oauth/clients/:id", wrapper.Wrap(oauthAPI.GetClient))
+			adminPath.DELETE("/oauth/clients/:id", wrapper.Wrap(oauthAPI.RevokeClient))
+
+			adminPath.POST("/service-accounts", wrapper.Wrap(apiKeyAPI.CreateServiceAccount))
+			adminPath.POST("/service-accounts/:id/api-keys", wrapper.Wrap(apiKeyAPI.CreateKey))
+			adminPath.GET("/service-accounts/:id/api-keys", wrapper.Wrap(apiKeyAPI.ListKeys))
+			adminPath.DELETE("/service-accounts/:id/api-keys/:key_id", wrapper.Wrap(apiKeyAPI.RevokeKey))
 		}
 
 		//-------------------------API---------------------------
-		apiPath := r.Group("/api/v1", jwtMiddle)
+		apiPath := r.Group("/api/v1", apiKeyMiddle, apiLimit)
+		{
+			// users read their own record, other records need the users.read permission.
+			// Casbin policies know nothing about owners, routes guarded by rules skip them
+			apiPath.GET("/users/:id", authz.Require(models.ResourceUser, "id",
+				middleware.AnyOf(middleware.IsOwner(), middleware.HasPermission(models.PermissionUsersRead))), userAPI.GetByID)
+			apiPath.GET("/users", casbinMiddle, authz.RequirePermission(models.PermissionUsersRead), wrapper.Wrap(userAPI.List))
+		}
+
+		// account self service is open to every user authenticated directly, delegated tokens are refused
+		mePath := apiPath.Group("/me", directMiddle)
 		{
-			apiPath.GET("/users/:id", userAPI.GetByID)
-			apiPath.GET("/users", wrapper.Wrap(userAPI.List))
+			mePath.GET("/sessions", wrapper.Wrap(sessionAPI.List))
+			mePath.DELETE("/sessions", wrapper.Wrap(sessionAPI.RevokeOthers))
+			mePath.DELETE("/sessions/:id", wrapper.Wrap(sessionAPI.Revoke))
+
+			mePath.POST("/mfa/totp", wrapper.Wrap(mfaAPI.Enroll))
+			mePath.POST("/mfa/totp/confirm", wrapper.Wrap(mfaAPI.Confirm))
+			mePath.POST("/mfa/recovery-codes", wrapper.Wrap(mfaAPI.RegenerateRecoveryCodes))
 		}
 		return nil
 	})
@@ -0,0 +1,44 @@
+package schema
+
+import "time"
+
+// ServiceAccountBodyParams schema
+type ServiceAccountBodyParams struct {
+	Username string `json:"username" validate:"required"`
+	FullName string `json:"full_name"`
+	RoleID   string `json:"role_id"`
+}
+
+// ServiceAccount schema
+type ServiceAccount struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	RoleID    string    `json:"role_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// APIKeyBodyParams schema, permissions must be held by the service account
+type APIKeyBodyParams struct {
+	Name        string     `json:"name" validate:"required"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// APIKey schema
+type APIKey struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// APIKeyCredentials schema, the key is only returned on creation
+type APIKeyCredentials struct {
+	APIKey
+	Key string `json:"key"`
+}
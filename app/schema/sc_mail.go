@@ -0,0 +1,9 @@
+package schema
+
+// MailMessage plain text email
+type MailMessage struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
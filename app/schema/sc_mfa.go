@@ -0,0 +1,17 @@
+package schema
+
+// MFAEnrollment schema, secret to be confirmed by a first code
+type MFAEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFACodeBodyParams schema
+type MFACodeBodyParams struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// MFARecoveryCodes schema, plain recovery codes are only returned once
+type MFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,79 @@
+package schema
+
+import "time"
+
+// OAuthClient schema
+type OAuthClient struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	RedirectURIs []string   `json:"redirect_uris"`
+	Scopes       []string   `json:"scopes"`
+	GrantTypes   []string   `json:"grant_types"`
+	Public       bool       `json:"public"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+}
+
+// OAuthClientCredentials schema, the secret is only returned on creation
+type OAuthClientCredentials struct {
+	OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// OAuthClientBodyParams schema
+type OAuthClientBodyParams struct {
+	Name         string   `json:"name" validate:"required"`
+	RedirectURIs []string `json:"redirect_uris" validate:"dive,url"`
+	Scopes       []string `json:"scopes"`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=client_credentials authorization_code"`
+	Public       bool     `json:"public"`
+}
+
+// OAuthClientQueryParam schema
+type OAuthClientQueryParam struct {
+	Offset int `json:"-" form:"offset,omitempty"`
+	Limit  int `json:"-" form:"limit,omitempty"`
+}
+
+// OAuthAuthorizeBodyParams schema, approval of the user for the client
+type OAuthAuthorizeBodyParams struct {
+	ResponseType        string `json:"response_type" validate:"required,eq=code"`
+	ClientID            string `json:"client_id" validate:"required"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge" validate:"required,min=43,max=128"`
+	CodeChallengeMethod string `json:"code_challenge_method" validate:"required,eq=S256"`
+}
+
+// OAuthAuthorizeResult schema
+type OAuthAuthorizeResult struct {
+	Code        string `json:"code"`
+	State       string `json:"state,omitempty"`
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// OAuthTokenParams schema, form encoded body of token endpoint
+type OAuthTokenParams struct {
+	GrantType    string `form:"grant_type"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	Scope        string `form:"scope"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+}
+
+// OAuthTokenResponse schema
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// OAuthErrorResponse schema, error response of token endpoint as in RFC 6749
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
@@ -1,6 +1,11 @@
 package schema
 
-import "github.com/shasw94/projX/pkg/utils"
+import (
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/models/pivot"
+	"github.com/shasw94/projX/pkg/utils"
+	"time"
+)
 
 type RoleOption struct {
 	WithPermissions bool
@@ -10,3 +15,180 @@ type RoleOption struct {
 type PermissionOption struct {
 	Pagination *utils.Pagination
 }
+
+// PassportQueryParam schema, paging of role and permission listings
+type PassportQueryParam struct {
+	Page            int  `json:"page,omitempty" form:"page,omitempty"`
+	Limit           int  `json:"limit,omitempty" form:"limit,omitempty"`
+	WithPermissions bool `json:"with_permissions,omitempty" form:"with_permissions,omitempty"`
+}
+
+// PageInfo schema, position of a page in a listing
+type PageInfo struct {
+	Page      int   `json:"page"`
+	Limit     int   `json:"limit"`
+	Total     int64 `json:"total"`
+	TotalPage int   `json:"total_page"`
+}
+
+// NewPageInfo return page info of the pagination
+func NewPageInfo(pagination *utils.Pagination, total int64) PageInfo {
+	return PageInfo{
+		Page:      pagination.GetPage(),
+		Limit:     pagination.GetLimit(),
+		Total:     total,
+		TotalPage: utils.TotalPage(total, pagination.GetLimit()),
+	}
+}
+
+// RoleList schema, page of roles
+type RoleList struct {
+	Roles    []models.Role `json:"roles"`
+	PageInfo PageInfo      `json:"page_info"`
+}
+
+// PermissionList schema, page of permissions
+type PermissionList struct {
+	Permissions []models.Permission `json:"permissions"`
+	PageInfo    PageInfo            `json:"page_info"`
+}
+
+// PermissionBodyParams schema
+type PermissionBodyParams struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+	// Resource and Action path and method patterns granted under casbin, e.g. /api/v1/users/:id and GET
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+}
+
+// PermissionUpdateBodyParams schema, the name is kept when empty
+type PermissionUpdateBodyParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Resource is kept when omitted, empty string unbinds the permission
+	Resource *string `json:"resource"`
+	Action   string  `json:"action"`
+}
+
+// PermissionsBodyParams schema, names or ids of permissions
+type PermissionsBodyParams struct {
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+// RolesBodyParams schema, names or ids of roles
+type RolesBodyParams struct {
+	Roles []string `json:"roles" validate:"dive,required"`
+}
+
+// Grant terms of a role or permission assignment, nil times leave the assignment open ended
+type Grant struct {
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	GrantedBy string     `json:"granted_by"`
+	Reason    string     `json:"reason" validate:"max=255"`
+}
+
+// UserRolesBodyParams schema, names or ids of roles assigned with the terms of the grant
+type UserRolesBodyParams struct {
+	RolesBodyParams
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Reason    string     `json:"reason" validate:"max=255"`
+}
+
+// Grant terms of the assignment granted by the user
+func (b *UserRolesBodyParams) Grant(grantedBy string) *Grant {
+	return &Grant{StartsAt: b.StartsAt, ExpiresAt: b.ExpiresAt, GrantedBy: grantedBy, Reason: b.Reason}
+}
+
+// UserPermissionsBodyParams schema, names or ids of permissions granted with the terms of the grant
+type UserPermissionsBodyParams struct {
+	PermissionsBodyParams
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Reason    string     `json:"reason" validate:"max=255"`
+}
+
+// Grant terms of the assignment granted by the user
+func (b *UserPermissionsBodyParams) Grant(grantedBy string) *Grant {
+	return &Grant{StartsAt: b.StartsAt, ExpiresAt: b.ExpiresAt, GrantedBy: grantedBy, Reason: b.Reason}
+}
+
+// ExpiredGrants assignments removed by the grant sweeper
+type ExpiredGrants struct {
+	Roles       []pivot.UserRole
+	Permissions []pivot.UserPermission
+}
+
+// Len number of removed assignments
+func (g *ExpiredGrants) Len() int {
+	return len(g.Roles) + len(g.Permissions)
+}
+
+// PermissionSource role granting a permission, depth 0 is the role itself and parents are one level up
+type PermissionSource struct {
+	RoleID   string `json:"role_id"`
+	RoleName string `json:"role_name"`
+	Depth    int    `json:"depth"`
+}
+
+// EffectivePermission permission resolved through the role hierarchy with the roles granting it
+type EffectivePermission struct {
+	models.Permission
+	Inherited bool               `json:"inherited"`
+	Sources   []PermissionSource `json:"sources"`
+}
+
+// EffectivePermissionList schema, permissions of the role including inherited ones
+type EffectivePermissionList struct {
+	Role        *models.Role          `json:"role"`
+	Permissions []EffectivePermission `json:"permissions"`
+}
+
+// Sources of authorization paths
+const (
+	AuthzSourceDirect        = "direct"
+	AuthzSourceRole          = "role"
+	AuthzSourceInheritedRole = "inherited_role"
+)
+
+// Reasons of authorization near misses
+const (
+	AuthzNotStarted = "not_started"
+	AuthzExpired    = "expired"
+)
+
+// AuthzExplainQueryParam schema, user id and permission name or id to explain
+type AuthzExplainQueryParam struct {
+	User       string `json:"user" form:"user" validate:"required"`
+	Permission string `json:"permission" form:"permission" validate:"required"`
+}
+
+// AuthzPath grant giving the permission to the user, depth counts the parents between an assigned role and the role
+type AuthzPath struct {
+	Source   string            `json:"source"`
+	RoleID   string            `json:"role_id,omitempty"`
+	RoleName string            `json:"role_name,omitempty"`
+	Depth    int               `json:"depth,omitempty"`
+	Granted  models.Permission `json:"granted"`
+	Wildcard bool              `json:"wildcard"`
+}
+
+// AuthzNearMiss assignment which would give the permission if it was active
+type AuthzNearMiss struct {
+	AuthzPath
+	Reason    string     `json:"reason"`
+	StartsAt  *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// AuthzExplanation schema, whether the user holds the permission and through which grants
+type AuthzExplanation struct {
+	UserID     string          `json:"user_id"`
+	Permission string          `json:"permission"`
+	Exists     bool            `json:"exists"`
+	Granted    bool            `json:"granted"`
+	Paths      []AuthzPath     `json:"paths"`
+	NearMisses []AuthzNearMiss `json:"near_misses"`
+}
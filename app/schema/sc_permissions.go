@@ -1,6 +1,9 @@
 package schema
 
-import "github.com/shasw94/projX/app/models"
+import (
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/utils"
+)
 
 type Permission []models.Permission
 
@@ -40,3 +43,44 @@ func (u Permission) GuardNames() (guards []string) {
 	}
 	return guards
 }
+
+// PermissionSet effective permissions of a user by id and guard name
+type PermissionSet struct {
+	IDs        []string `json:"ids"`
+	GuardNames []string `json:"guard_names"`
+}
+
+// NewPermissionSet permission set of the permissions
+// @param Permission
+// @return *PermissionSet
+func NewPermissionSet(permissions Permission) *PermissionSet {
+	return &PermissionSet{IDs: permissions.IDs(), GuardNames: permissions.GuardNames()}
+}
+
+// Has the set holds the permission given by id or name, wildcard grants cover the names under them
+// @param string
+// @return bool
+func (s *PermissionSet) Has(permission string) bool {
+	if utils.InArray(permission, s.IDs) {
+		return true
+	}
+	guard := utils.Guard(permission)
+	for _, granted := range s.GuardNames {
+		if utils.MatchGuard(granted, guard) {
+			return true
+		}
+	}
+	return false
+}
+
+// Covering permissions of the list granting the permission given by id or name, wildcards included
+// @param string
+// @return Permission
+func (u Permission) Covering(permission string) (covering Permission) {
+	for _, p := range u {
+		if NewPermissionSet(Permission{p}).Has(permission) {
+			covering = append(covering, p)
+		}
+	}
+	return covering
+}
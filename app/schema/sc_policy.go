@@ -0,0 +1,9 @@
+package schema
+
+// ResourceAttributes attributes of a resource evaluated by authorization rules
+type ResourceAttributes struct {
+	ID      string
+	OwnerID string
+	// Extra attributes specific to the resource type
+	Extra map[string]interface{}
+}
@@ -0,0 +1,23 @@
+package schema
+
+import "time"
+
+// RateLimitPolicy rate limit of a route group
+type RateLimitPolicy struct {
+	// Algorithm token_bucket or sliding_window
+	Algorithm string
+	// Limit requests allowed per Period, also the burst size of token bucket
+	Limit  int64
+	Period time.Duration
+}
+
+// RateLimitResult result of a rate limited request
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// RetryAfter wait time until the next request is allowed, zero when allowed
+	RetryAfter time.Duration
+	// Reset wait time until the limit is fully available again
+	Reset time.Duration
+}
@@ -12,3 +12,9 @@ type RoleBodyParams struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description"`
 }
+
+// RoleUpdateBodyParams schema, the name is kept when empty
+type RoleUpdateBodyParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
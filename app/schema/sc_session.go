@@ -0,0 +1,14 @@
+package schema
+
+import "time"
+
+// Session schema
+type Session struct {
+	ID          string    `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	UserAgent   string    `json:"user_agent"`
+	IP          string    `json:"ip"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	Current     bool      `json:"current"`
+}
@@ -8,23 +8,63 @@ type User struct {
 	Extra    interface{} `json:"extra,omitempty"`
 }
 
+// ClientInfo schema, describes the device which starts a session
+type ClientInfo struct {
+	DeviceLabel string `json:"device_label,omitempty"`
+	UserAgent   string `json:"-"`
+	IP          string `json:"-"`
+}
+
 // RegisterBodyParams schema
 type RegisterBodyParams struct {
 	Username string `json:"username" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,password"`
-	RoleID   string `json:"role_id"`
+	ClientInfo
 }
 
-// LoginBodyParams schema
+// LoginBodyParams schema, the password policy is not applied so users
+// with passwords predating the policy can still sign in
 type LoginBodyParams struct {
 	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required,password"`
+	Password string `json:"password" validate:"required"`
+	ClientInfo
+}
+
+// LoginMFABodyParams schema, second step of login with totp code or recovery code
+type LoginMFABodyParams struct {
+	MFAToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+	ClientInfo
 }
 
 // RefreshBodyParams schema
 type RefreshBodyParams struct {
 	RefreshToken string `json:"refresh_token,omitempty" validate:"required"`
+	ClientInfo
+}
+
+// ChangePasswordBodyParams schema
+type ChangePasswordBodyParams struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,password"`
+}
+
+// ForgotPasswordBodyParams schema
+type ForgotPasswordBodyParams struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordBodyParams schema
+type ResetPasswordBodyParams struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"omitempty,password"`
+}
+
+// VerifyEmailBodyParams schema
+type VerifyEmailBodyParams struct {
+	Token string `json:"token" validate:"required"`
 }
 
 // UserQueryParam schema
@@ -40,12 +80,24 @@ type UserTokenInfo struct {
 	AccessToken  string   `json:"access_token"`
 	RefreshToken string   `json:"refresh_token"`
 	TokenType    string   `json:"token_type"`
+	SessionID    string   `json:"session_id"`
 	Roles        []string `json:"roles"`
+	MFARequired  bool     `json:"mfa_required,omitempty"`
+	MFAToken     string   `json:"mfa_token,omitempty"`
+	// EmailVerificationRequired no tokens are issued until the email address is verified
+	EmailVerificationRequired bool `json:"email_verification_required,omitempty"`
+}
+
+// ImpersonationTokenInfo short-lived access token of an admin acting as another user, it cannot be refreshed
+type ImpersonationTokenInfo struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	UserID      string `json:"user_id"`
+	ActorID     string `json:"actor_id"`
 }
 
 // UserUpdateBodyParam schema
 type UserUpdateBodyParam struct {
-	Password     string `json:"password,omitempty"`
-	RoleID       string `json:"role_id,omitempty"`
-	RefreshToken string `json:"refresh_token,omitempty"`
+	RoleID string `json:"role_id,omitempty"`
 }
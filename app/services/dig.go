@@ -7,5 +7,9 @@ func Inject(container *dig.Container) error {
 	_ = container.Provide(NewAuthService)
 	_ = container.Provide(NewUserService)
 	_ = container.Provide(NewRoleService)
+	_ = container.Provide(NewSessionService)
+	_ = container.Provide(NewOAuthService)
+	_ = container.Provide(NewMFAService)
+	_ = container.Provide(NewAPIKeyService)
 	return nil
 }
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/shasw94/projX/pkg/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyScheme leading part of every api key, followed by prefix and secret separated by underscores
+	apiKeyScheme = "pjx_"
+	// apiKeyPrefixSize random bytes of the lookup prefix
+	apiKeyPrefixSize = 6
+	// apiKeyTouchInterval minimum time between two updates of last used time
+	apiKeyTouchInterval = time.Minute
+	// serviceAccountEmailDomain reserved domain of service account email addresses, they never receive mails
+	serviceAccountEmailDomain = "service-account.invalid"
+)
+
+// APIKeyService service account and api key service
+type APIKeyService struct {
+	userRepo   interfaces.IUserRepository
+	roleRepo   interfaces.IRoleRepository
+	passport   interfaces.IPassport
+	apiKeyRepo interfaces.IAPIKeyRepository
+}
+
+// NewAPIKeyService return new IAPIKeyService interface
+func NewAPIKeyService(
+	user interfaces.IUserRepository,
+	role interfaces.IRoleRepository,
+	passport interfaces.IPassport,
+	apiKey interfaces.IAPIKeyRepository,
+) interfaces.IAPIKeyService {
+	return &APIKeyService{
+		userRepo:   user,
+		roleRepo:   role,
+		passport:   passport,
+		apiKeyRepo: apiKey,
+	}
+}
+
+// CreateServiceAccount create user which can not sign in by password
+func (s *APIKeyService) CreateServiceAccount(ctx context.Context, param *schema.ServiceAccountBodyParams) (*models.User, error) {
+	if param.RoleID == "" {
+		role, err := s.roleRepo.GetByName("user")
+		if err != nil {
+			return nil, err
+		}
+		param.RoleID = role.ID
+	}
+
+	password, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:       param.Username,
+		Email:          strings.ToLower(param.Username) + "@" + serviceAccountEmailDomain,
+		Password:       password,
+		RoleID:         param.RoleID,
+		FullName:       param.FullName,
+		ServiceAccount: true,
+	}
+	err = s.userRepo.Create(user)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// getServiceAccount get user which has to be a service account
+func (s *APIKeyService) getServiceAccount(userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.ErrorNotExistUser.New()
+	}
+	if !user.ServiceAccount {
+		return nil, errors.InvalidParams.Newm("user is not a service account")
+	}
+	return user, nil
+}
+
+// CreateKey mint new api key for service account, return the key and its plain value.
+// The key can only be granted permissions the service account holds
+func (s *APIKeyService) CreateKey(ctx context.Context, userID string, param *schema.APIKeyBodyParams) (*models.APIKey, string, error) {
+	user, err := s.getServiceAccount(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if param.ExpiresAt != nil && !param.ExpiresAt.After(time.Now()) {
+		return nil, "", errors.InvalidParams.Newm("expires_at must be in the future")
+	}
+
+	_, held, err := userAuthorization(s.passport, user)
+	if err != nil {
+		return nil, "", err
+	}
+	permissions := utils.RemoveDuplicateValues(utils.GuardArray(param.Permissions))
+	for _, permission := range permissions {
+		if !utils.MatchAnyGuard(held, permission) {
+			return nil, "", errors.InvalidParams.Newm("permission " + permission + " is not held by the service account")
+		}
+	}
+
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		UserID:      user.ID,
+		Name:        param.Name,
+		Prefix:      prefix,
+		KeyHash:     utils.HashToken(secret),
+		Permissions: strings.Join(permissions, " "),
+		CreatedBy:   app.GetUserID(ctx),
+		ExpiresAt:   param.ExpiresAt,
+	}
+	err = s.apiKeyRepo.Create(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, apiKeyScheme + prefix + "_" + secret, nil
+}
+
+// ListKeys list api keys of service account
+func (s *APIKeyService) ListKeys(ctx context.Context, userID string) (*[]models.APIKey, error) {
+	user, err := s.getServiceAccount(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.apiKeyRepo.ListByUser(user.ID)
+}
+
+// RevokeKey revoke api key of service account
+func (s *APIKeyService) RevokeKey(ctx context.Context, userID string, id string) error {
+	revoked, err := s.apiKeyRepo.Revoke(userID, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.ErrorNotFound.New()
+	}
+	return nil
+}
+
+// Authenticate check api key, return claims of its service account.
+// Permissions are the key permissions still held by the service account
+func (s *APIKeyService) Authenticate(value string) (*jwt.AccessClaims, error) {
+	prefix, secret, ok := parseAPIKey(value)
+	if !ok {
+		return nil, errors.ErrorAPIKeyInvalid.New()
+	}
+
+	key, err := s.apiKeyRepo.GetByPrefix(prefix)
+	if err != nil {
+		return nil, errors.ErrorAPIKeyInvalid.New()
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(utils.HashToken(secret))) != 1 || !key.IsActive() {
+		return nil, errors.ErrorAPIKeyInvalid.New()
+	}
+
+	user, err := s.userRepo.GetByID(key.UserID)
+	if err != nil || !user.ServiceAccount {
+		return nil, errors.ErrorAPIKeyInvalid.New()
+	}
+
+	_, held, err := userAuthorization(s.passport, user)
+	if err != nil {
+		return nil, err
+	}
+	var permissions []string
+	for _, permission := range key.PermissionList() {
+		if utils.MatchAnyGuard(held, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.apiKeyRepo.Touch(key.ID, now); err != nil {
+			logger.Error("Failed to record api key use: ", err)
+		}
+	}
+
+	claims := &jwt.AccessClaims{
+		CustomClaims: jwt.CustomClaims{
+			Permissions: permissions,
+			Extra:       map[string]interface{}{"username": user.Username, "api_key": key.ID},
+		},
+	}
+	claims.Subject = user.ID
+	claims.ID = key.ID
+	return claims, nil
+}
+
+// generateAPIKey return random lookup prefix and secret of new api key
+func generateAPIKey() (string, string, error) {
+	b := make([]byte, apiKeyPrefixSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(b), secret, nil
+}
+
+// parseAPIKey split api key into lookup prefix and secret
+func parseAPIKey(value string) (string, string, bool) {
+	if !strings.HasPrefix(value, apiKeyScheme) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(value, apiKeyScheme), "_", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -2,45 +2,169 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"github.com/jinzhu/copier"
 	"github.com/shasw94/projX/app/interfaces"
 	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/revocation"
 	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/logger"
 	"github.com/shasw94/projX/pkg/app"
 	"github.com/shasw94/projX/pkg/errors"
 	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/shasw94/projX/pkg/utils"
+	"github.com/shasw94/projX/validation"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultPasswordResetExpired lifetime of password reset token in seconds when not configured
+	defaultPasswordResetExpired = 3600
+	// defaultEmailVerifyExpired lifetime of email verification token in seconds when not configured
+	defaultEmailVerifyExpired = 86400
+	// defaultImpersonationExpired lifetime of impersonation access token in seconds when not configured
+	defaultImpersonationExpired = 900
 )
 
 // AuthService authentication service
 type AuthService struct {
-	jwt      jwt.IJWTAuth
-	userRepo interfaces.IUserRepository
-	roleRepo interfaces.IRoleRepository
+	jwt         jwt.IJWTAuth
+	userRepo    interfaces.IUserRepository
+	roleRepo    interfaces.IRoleRepository
+	passport    interfaces.IPassport
+	tokenRepo   interfaces.IRefreshTokenRepository
+	sessionRepo interfaces.ISessionRepository
+	auditRepo   interfaces.IAuditRepository
+	mfaRepo     interfaces.IMFARepository
+	userToken   interfaces.IUserTokenRepository
+	revocation  interfaces.IRevocationStore
+	lockout     interfaces.ILoginLockout
+	mailer      interfaces.IMailer
 }
 
 // NewAuthService return new IAuthService interface
-func NewAuthService(jwt jwt.IJWTAuth, user interfaces.IUserRepository, role interfaces.IRoleRepository) interfaces.IAuthService {
+func NewAuthService(
+	jwt jwt.IJWTAuth,
+	user interfaces.IUserRepository,
+	role interfaces.IRoleRepository,
+	passport interfaces.IPassport,
+	token interfaces.IRefreshTokenRepository,
+	session interfaces.ISessionRepository,
+	audit interfaces.IAuditRepository,
+	mfa interfaces.IMFARepository,
+	userToken interfaces.IUserTokenRepository,
+	revocation interfaces.IRevocationStore,
+	lockout interfaces.ILoginLockout,
+	mailer interfaces.IMailer,
+) interfaces.IAuthService {
 	return &AuthService{
-		jwt:      jwt,
-		userRepo: user,
-		roleRepo: role,
+		jwt:         jwt,
+		userRepo:    user,
+		roleRepo:    role,
+		passport:    passport,
+		tokenRepo:   token,
+		sessionRepo: session,
+		auditRepo:   audit,
+		mfaRepo:     mfa,
+		userToken:   userToken,
+		revocation:  revocation,
+		lockout:     lockout,
+		mailer:      mailer,
 	}
 }
 
-// Login handle user login
-func (a *AuthService) Login(ctx context.Context, bodyParam *schema.LoginBodyParams) (*schema.UserTokenInfo, error) {
-	user, err := a.userRepo.Login(bodyParam)
+// storeRefreshToken keep track of issued refresh token
+func (a *AuthService) storeRefreshToken(token jwt.TokenInfo) error {
+	claims := token.GetRefreshClaims()
+	return a.tokenRepo.Create(&models.RefreshToken{
+		Model:     models.Model{ID: claims.ID},
+		UserID:    claims.Subject,
+		FamilyID:  claims.FamilyID,
+		TokenHash: utils.HashToken(token.GetRefreshToken()),
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+}
+
+// authorizationClaims collect role and permission guard names of user for access token
+func (a *AuthService) authorizationClaims(user *models.User) (jwt.CustomClaims, error) {
+	roleNames, permissions, err := userAuthorization(a.passport, user)
+	if err != nil {
+		return jwt.CustomClaims{}, err
+	}
+
+	return jwt.CustomClaims{
+		Roles:             roleNames,
+		Permissions:       permissions,
+		PermissionVersion: permissionVersion(roleNames, permissions),
+		Extra:             map[string]interface{}{"username": user.Username},
+	}, nil
+}
+
+// userAuthorization collect sorted role and permission guard names of user.
+// Permissions are resolved by Passport, they are granted directly or by roles and the roles they inherit from
+func userAuthorization(passport interfaces.IPassport, user *models.User) ([]string, []string, error) {
+	roles, _, err := passport.GetRolesOfUser(user.ID, &schema.RoleOption{})
+	if err != nil {
+		return nil, nil, err
+	}
+	roleNames := roles.GuardNames()
+	if user.RoleID != "" {
+		primary, err := passport.GetRole(user.RoleID, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		roleNames = append(roleNames, primary.GuardName)
+	}
+
+	permissions, err := passport.GetAllPermissionsOfUser(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roleNames = utils.RemoveDuplicateValues(roleNames)
+	guardNames := permissions.GuardNames()
+	sort.Strings(roleNames)
+	sort.Strings(guardNames)
+	return roleNames, guardNames, nil
+}
+
+// permissionVersion fingerprint of role and permission set, changes whenever one of them changes
+func permissionVersion(roles, permissions []string) string {
+	return utils.HashToken(strings.Join(roles, ",") + "|" + strings.Join(permissions, ","))[:16]
+}
+
+// startSession issue tokens for user and open a new session for the client
+func (a *AuthService) startSession(user *models.User, client schema.ClientInfo) (*schema.UserTokenInfo, error) {
+	custom, err := a.authorizationClaims(user)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.jwt.GenerateTokenWithClaims(user.ID, custom)
 	if err != nil {
 		return nil, err
 	}
 
-	token, err := a.jwt.GenerateToken(user.ID)
+	err = a.storeRefreshToken(token)
 	if err != nil {
 		return nil, err
 	}
 
-	values := schema.UserUpdateBodyParam{RefreshToken: token.GetRefreshToken()}
-	_, err = a.userRepo.Update(user.ID, &values)
+	claims := token.GetRefreshClaims()
+	session := &models.Session{
+		Model:       models.Model{ID: claims.FamilyID},
+		UserID:      user.ID,
+		DeviceLabel: client.DeviceLabel,
+		UserAgent:   client.UserAgent,
+		IP:          client.IP,
+		LastUsedAt:  time.Now(),
+		ExpiresAt:   claims.ExpiresAt.Time,
+	}
+	err = a.sessionRepo.Create(session)
 	if err != nil {
 		return nil, err
 	}
@@ -49,78 +173,615 @@ func (a *AuthService) Login(ctx context.Context, bodyParam *schema.LoginBodyPara
 		AccessToken:  token.GetAccessToken(),
 		RefreshToken: token.GetRefreshToken(),
 		TokenType:    token.GetTokenType(),
+		SessionID:    session.ID,
 		Roles:        []string{user.RoleID},
 	}
 
 	return &tokenInfo, nil
 }
 
+// revokeUser revoke all sessions, refresh tokens and access tokens of user
+func (a *AuthService) revokeUser(userID string) error {
+	sessions, err := a.sessionRepo.ListActiveByUser(userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range *sessions {
+		if err := a.sessionRepo.Revoke(session.ID); err != nil {
+			return err
+		}
+	}
+	if err := a.tokenRepo.RevokeByUser(userID); err != nil {
+		return err
+	}
+	return a.revocation.RevokeUser(userID)
+}
+
+// revokeReusedFamily revoke session when a used refresh token is presented again
+func (a *AuthService) revokeReusedFamily(stored *models.RefreshToken) error {
+	if err := revokeSession(a.sessionRepo, a.tokenRepo, a.revocation, stored.FamilyID); err != nil {
+		return err
+	}
+
+	event := &models.AuditEvent{
+		Event:  models.AuditRefreshTokenReuse,
+		UserID: stored.UserID,
+		Detail: utils.JSONMarshalToString(map[string]string{
+			"family_id": stored.FamilyID,
+			"token_id":  stored.ID,
+		}),
+	}
+	if err := a.auditRepo.Create(event); err != nil {
+		logger.Error("Failed to record audit event: ", err)
+	}
+	return nil
+}
+
+// startMFAChallenge return mfa pending token instead of tokens, login is finished by LoginMFA
+func (a *AuthService) startMFAChallenge(user *models.User) (*schema.UserTokenInfo, error) {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.mfaRepo.CreateChallenge(&models.MFAChallenge{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(mfaChallengeExpired()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.UserTokenInfo{
+		MFARequired: true,
+		MFAToken:    token,
+	}, nil
+}
+
+// verifySecondFactor check totp code or recovery code of user
+func (a *AuthService) verifySecondFactor(userID string, bodyParam *schema.LoginMFABodyParams) (bool, error) {
+	if bodyParam.RecoveryCode != "" {
+		return a.mfaRepo.UseRecoveryCode(userID, hashRecoveryCode(bodyParam.RecoveryCode))
+	}
+
+	mfa, err := a.mfaRepo.GetByUserID(userID)
+	if err != nil || !mfa.IsEnabled() {
+		return false, errors.ErrorTokenInvalid.New()
+	}
+	return verifyTOTP(a.mfaRepo, mfa, bodyParam.Code)
+}
+
+// Login handle user login. Users with two-factor authentication get a mfa pending token
+func (a *AuthService) Login(ctx context.Context, bodyParam *schema.LoginBodyParams) (*schema.UserTokenInfo, error) {
+	ip := bodyParam.ClientInfo.IP
+	retryAfter, disabled, err := a.lockout.Check(bodyParam.Username, ip)
+	if err != nil {
+		logger.Error("Failed to check login lockout: ", err)
+	}
+	if disabled {
+		return nil, errors.ErrorUserDisabled.New()
+	}
+	if retryAfter > 0 {
+		return nil, errors.ErrorTooManyRequest.Newm(fmt.Sprintf("retry after %s", retryAfter.Round(time.Second)))
+	}
+
+	user, err := a.userRepo.Login(bodyParam)
+	if err != nil {
+		if errors.GetType(err) == errors.ErrorLoginFailed {
+			if err := a.lockout.Fail(bodyParam.Username, ip); err != nil {
+				logger.Error("Failed to record failed login: ", err)
+			}
+		}
+		return nil, err
+	}
+	// service accounts authenticate by api keys only
+	if user.ServiceAccount {
+		return nil, errors.ErrorLoginFailed.New()
+	}
+	if err := a.lockout.Succeed(user.Username); err != nil {
+		logger.Error("Failed to reset failed logins: ", err)
+	}
+
+	if config.Config.Account.RequireVerifiedEmail && !user.IsEmailVerified() {
+		return nil, errors.ErrorEmailNotVerified.New()
+	}
+
+	enabled, err := a.mfaRepo.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return a.startMFAChallenge(user)
+	}
+
+	return a.startSession(user, bodyParam.ClientInfo)
+}
+
+// LoginMFA finish login of user with two-factor authentication
+func (a *AuthService) LoginMFA(ctx context.Context, bodyParam *schema.LoginMFABodyParams) (*schema.UserTokenInfo, error) {
+	challenge, err := a.mfaRepo.GetChallengeByHash(utils.HashToken(bodyParam.MFAToken))
+	if err != nil || !challenge.IsActive() {
+		return nil, errors.ErrorTokenInvalid.New()
+	}
+
+	// the attempt is counted before the code is checked, so parallel guesses share the limit
+	allowed, err := a.mfaRepo.IncrementChallengeAttempts(challenge.ID, mfaMaxAttempts())
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.ErrorTokenInvalid.New()
+	}
+
+	ok, err := a.verifySecondFactor(challenge.UserID, bodyParam)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.ErrorInvalidOTP.New()
+	}
+
+	used, err := a.mfaRepo.UseChallenge(challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errors.ErrorTokenInvalid.New()
+	}
+
+	user, err := a.userRepo.GetByID(challenge.UserID)
+	if err != nil {
+		return nil, errors.ErrorNotExistUser.New()
+	}
+
+	return a.startSession(user, bodyParam.ClientInfo)
+}
+
 // Register register user
 func (a *AuthService) Register(ctx context.Context, param *schema.RegisterBodyParams) (*schema.UserTokenInfo, error) {
-	if param.RoleID == "" {
-		role, err := a.roleRepo.GetByName("user")
-		if err != nil {
-			return nil, err
-		}
+	// self registered accounts always start with the default role,
+	// anything else has to be granted by an administrator
+	role, err := a.roleRepo.GetByName("user")
+	if err != nil {
+		return nil, err
+	}
 
-		param.RoleID = role.ID
+	if _, err := a.userRepo.GetByEmail(param.Email); err == nil {
+		return nil, errors.ErrorExistEmail.New()
 	}
 
 	var user models.User
 	copier.Copy(&user, &param)
-	err := a.userRepo.Create(&user)
+	user.RoleID = role.ID
+	err = a.userRepo.Create(&user)
 	if err != nil {
 		return nil, err
 	}
 
-	token, _ := a.jwt.GenerateToken(user.ID)
-	values := schema.UserUpdateBodyParam{RefreshToken: token.GetRefreshToken()}
-	_, err = a.userRepo.Update(user.ID, &values)
+	if err := a.sendEmailVerification(ctx, &user); err != nil {
+		logger.Error("Failed to send email verification: ", err)
+	}
+
+	// login is refused until the email address is verified, so is the session of the registration
+	if config.Config.Account.RequireVerifiedEmail {
+		return &schema.UserTokenInfo{EmailVerificationRequired: true}, nil
+	}
+	return a.startSession(&user, param.ClientInfo)
+}
+
+// Refresh rotate refresh token of the session.
+// Presenting an already used refresh token revokes the whole session
+func (a *AuthService) Refresh(ctx context.Context, bodyParam *schema.RefreshBodyParams) (*schema.UserTokenInfo, error) {
+	claims, err := a.jwt.ParseRefreshToken(bodyParam.RefreshToken)
 	if err != nil {
 		return nil, err
 	}
 
-	tokenInfo := schema.UserTokenInfo{
-		AccessToken:  token.GetAccessToken(),
-		RefreshToken: token.GetRefreshToken(),
-		TokenType:    token.GetTokenType(),
+	stored, err := a.tokenRepo.GetByID(claims.ID)
+	if err != nil || stored.TokenHash != utils.HashToken(bodyParam.RefreshToken) {
+		return nil, errors.ErrorTokenInvalid.New()
 	}
 
-	return &tokenInfo, nil
-}
+	if stored.IsRevoked() {
+		return nil, errors.ErrorTokenInvalid.New()
+	}
 
-// Refresh refresh token for user
-func (a *AuthService) Refresh(ctx context.Context, bodyParam *schema.RefreshBodyParams) (*schema.UserTokenInfo, error) {
-	user, err := a.userRepo.GetUserByToken(bodyParam.RefreshToken)
+	if stored.IsUsed() {
+		if err := a.revokeReusedFamily(stored); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrorTokenInvalid.New()
+	}
+
+	session, err := a.sessionRepo.GetByID(stored.FamilyID)
+	if err != nil || !session.IsActive() {
+		return nil, errors.ErrorTokenInvalid.New()
+	}
+
+	user, err := a.userRepo.GetByID(stored.UserID)
 	if err != nil {
 		return nil, errors.ErrorTokenInvalid.New()
 	}
 
-	token, err := a.jwt.RefreshToken(bodyParam.RefreshToken)
+	custom, err := a.authorizationClaims(user)
 	if err != nil {
 		return nil, err
 	}
 
-	if token.GetRefreshToken() != user.RefreshToken {
-		values := schema.UserUpdateBodyParam{RefreshToken: token.GetRefreshToken()}
-		_, err = a.userRepo.Update(user.ID, &values)
+	token, err := a.jwt.RefreshTokenWithClaims(bodyParam.RefreshToken, custom)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := a.tokenRepo.MarkUsed(stored.ID, token.GetRefreshClaims().ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// another request exchanged the token first
+		if err := a.revokeReusedFamily(stored); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrorTokenInvalid.New()
+	}
+
+	err = a.storeRefreshToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	session.LastUsedAt = time.Now()
+	session.ExpiresAt = token.GetRefreshClaims().ExpiresAt.Time
+	if bodyParam.IP != "" {
+		session.IP = bodyParam.IP
+	}
+	err = a.sessionRepo.Touch(session)
+	if err != nil {
+		return nil, err
 	}
 
 	tokenInfo := schema.UserTokenInfo{
 		AccessToken:  token.GetAccessToken(),
 		RefreshToken: token.GetRefreshToken(),
 		TokenType:    token.GetTokenType(),
+		SessionID:    session.ID,
 		Roles:        []string{user.RoleID},
 	}
 
 	return &tokenInfo, nil
 }
 
-// Logout logout user
+// Logout logout current session of user
 func (a *AuthService) Logout(ctx context.Context) error {
-	_, err := a.userRepo.RemoveToken(app.GetUserID(ctx))
+	session, err := a.sessionRepo.GetByID(app.GetSessionID(ctx))
+	if err != nil || session.UserID != app.GetUserID(ctx) {
+		return errors.ErrorTokenInvalid.New()
+	}
+
+	err = revokeSession(a.sessionRepo, a.tokenRepo, a.revocation, session.ID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// ChangePassword change password of current user and sign out all of its sessions
+func (a *AuthService) ChangePassword(ctx context.Context, bodyParam *schema.ChangePasswordBodyParams) error {
+	user, err := a.userRepo.GetByID(app.GetUserID(ctx))
+	if err != nil {
+		return errors.ErrorNotExistUser.New()
+	}
+
+	if !utils.CheckPassword(user.Password, []byte(bodyParam.OldPassword)) {
+		return errors.ErrorInvalidOldPass.New()
+	}
+
+	if err := validation.CheckPassword(bodyParam.NewPassword, user.Username, user.Email); err != nil {
+		return errors.AddErrorContext(errors.InvalidParams.New(), "new_password", err.Error())
+	}
+
+	hashed, err := utils.HashPassword([]byte(bodyParam.NewPassword))
+	if err != nil {
+		return err
+	}
+	err = a.userRepo.UpdatePassword(user.ID, hashed)
+	if err != nil {
+		return err
+	}
+
+	return a.revokeUser(user.ID)
+}
+
+// SignOutUser force sign out all sessions of user
+func (a *AuthService) SignOutUser(ctx context.Context, userID string) error {
+	user, err := a.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.ErrorNotExistUser.New()
+	}
+
+	return a.revokeUser(user.ID)
+}
+
+// UnlockUser clear failed logins and lockout of user
+func (a *AuthService) UnlockUser(ctx context.Context, userID string) error {
+	user, err := a.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.ErrorNotExistUser.New()
+	}
+
+	err = a.lockout.Unlock(user.Username)
+	if err != nil {
+		return err
+	}
+
+	err = a.auditRepo.Create(&models.AuditEvent{
+		Event:   models.AuditLoginUnlocked,
+		UserID:  user.ID,
+		ActorID: app.GetUserID(ctx),
+	})
+	if err != nil {
+		logger.Error("Failed to record audit event: ", err)
+	}
+	return nil
+}
+
+// Impersonate issue short-lived access token of user to the admin of the context, the token carries the admin
+// as actor and has no refresh token. Admins cannot be impersonated, neither can impersonation be chained
+func (a *AuthService) Impersonate(ctx context.Context, userID string) (*schema.ImpersonationTokenInfo, error) {
+	claims := app.GetClaims(ctx)
+	if claims == nil || claims.ActorID() != "" || !claims.HasRole(models.RoleAdmin) {
+		return nil, errors.ErrNoPermission
+	}
+	actorID := claims.Subject
+	if actorID == userID {
+		return nil, errors.ErrorBadRequest.Newm("cannot impersonate yourself")
+	}
+
+	user, err := a.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, errors.ErrorNotExistUser.New()
+	}
+	if user.ServiceAccount {
+		return nil, errors.ErrNoPermission
+	}
+
+	custom, err := a.authorizationClaims(user)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range custom.Roles {
+		if role == models.RoleAdmin {
+			return nil, errors.ErrNoPermission
+		}
+	}
+	custom.Actor = &jwt.Actor{Subject: actorID}
+
+	token, err := a.jwt.GenerateAccessTokenWithExpired(user.ID, custom, impersonationExpired())
+	if err != nil {
+		return nil, err
+	}
+	accessClaims := token.GetAccessClaims()
+
+	err = a.auditRepo.Create(&models.AuditEvent{
+		Event:   models.AuditImpersonation,
+		UserID:  user.ID,
+		ActorID: actorID,
+		Detail: utils.JSONMarshalToString(map[string]interface{}{
+			"token_id":   accessClaims.ID,
+			"expires_at": accessClaims.ExpiresAt.Time,
+		}),
+	})
+	if err != nil {
+		logger.Error("Failed to record audit event: ", err)
+	}
+
+	return &schema.ImpersonationTokenInfo{
+		AccessToken: token.GetAccessToken(),
+		TokenType:   token.GetTokenType(),
+		ExpiresIn:   accessClaims.ExpiresAt.Unix() - accessClaims.IssuedAt.Unix(),
+		UserID:      user.ID,
+		ActorID:     actorID,
+	}, nil
+}
+
+// issueUserToken invalidate outstanding tokens of the purpose and issue a new one
+func (a *AuthService) issueUserToken(user *models.User, purpose string, ttl time.Duration) (string, error) {
+	err := a.userToken.InvalidateByUser(user.ID, purpose)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.userToken.Create(&models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeUserToken verify token of the purpose and mark it as used, the user must still own the email address
+func (a *AuthService) consumeUserToken(purpose, token string) (*models.User, error) {
+	stored, user, err := a.findUserToken(purpose, token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.useUserToken(stored)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// findUserToken get active token of the purpose and its user without using it
+func (a *AuthService) findUserToken(purpose, token string) (*models.UserToken, *models.User, error) {
+	stored, err := a.userToken.GetByHash(purpose, utils.HashToken(token))
+	if err != nil || !stored.IsActive() {
+		return nil, nil, errors.ErrorTokenInvalid.New()
+	}
+
+	user, err := a.userRepo.GetByID(stored.UserID)
+	if err != nil || user.Email != stored.Email {
+		return nil, nil, errors.ErrorTokenInvalid.New()
+	}
+	return stored, user, nil
+}
+
+// useUserToken mark token as used, fails if it has been used concurrently
+func (a *AuthService) useUserToken(stored *models.UserToken) error {
+	ok, err := a.userToken.Use(stored.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.ErrorTokenInvalid.New()
+	}
+	return nil
+}
+
+// sendEmailVerification send verification link to email address of user
+func (a *AuthService) sendEmailVerification(ctx context.Context, user *models.User) error {
+	ttl := tokenExpired(config.Config.Account.EmailVerifyExpired, defaultEmailVerifyExpired)
+	token, err := a.issueUserToken(user, models.UserTokenEmailVerification, ttl)
+	if err != nil {
+		return err
+	}
+
+	return a.mailer.Send(ctx, schema.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease verify your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username, tokenLink(config.Config.Account.EmailVerifyURL, token), ttl),
+	})
+}
+
+// ForgotPassword send password reset link to the email address.
+// Nothing tells whether the address belongs to an account
+func (a *AuthService) ForgotPassword(ctx context.Context, bodyParam *schema.ForgotPasswordBodyParams) error {
+	user, err := a.userRepo.GetByEmail(bodyParam.Email)
+	if err != nil {
+		return nil
+	}
+
+	ttl := tokenExpired(config.Config.Account.PasswordResetExpired, defaultPasswordResetExpired)
+	token, err := a.issueUserToken(user, models.UserTokenPasswordReset, ttl)
+	if err != nil {
+		return err
+	}
+
+	err = a.mailer.Send(ctx, schema.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone requested a password reset for your account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in %s. If you did not request it, you can ignore this email.\n",
+			user.Username, tokenLink(config.Config.Account.PasswordResetURL, token), ttl),
+	})
+	if err != nil {
+		logger.Error("Failed to send password reset: ", err)
+	}
+	return nil
+}
+
+// ResetPassword set new password by password reset token and sign out all sessions of the user.
+// The token proves ownership of the email address, so it is marked as verified
+func (a *AuthService) ResetPassword(ctx context.Context, bodyParam *schema.ResetPasswordBodyParams) error {
+	if bodyParam.Password == "" {
+		return errors.ErrorPasswordRequired.New()
+	}
+
+	// the token stays valid when the password is rejected by the policy
+	stored, user, err := a.findUserToken(models.UserTokenPasswordReset, bodyParam.Token)
+	if err != nil {
+		return err
+	}
+	if err := validation.CheckPassword(bodyParam.Password, user.Username, user.Email); err != nil {
+		return errors.AddErrorContext(errors.InvalidParams.New(), "password", err.Error())
+	}
+	err = a.useUserToken(stored)
+	if err != nil {
+		return err
+	}
+
+	hashed, err := utils.HashPassword([]byte(bodyParam.Password))
+	if err != nil {
+		return err
+	}
+	err = a.userRepo.UpdatePassword(user.ID, hashed)
+	if err != nil {
+		return err
+	}
+
+	err = a.userRepo.VerifyEmail(user.ID)
+	if err != nil {
+		return err
+	}
+
+	return a.revokeUser(user.ID)
+}
+
+// SendEmailVerification send new verification link to current user
+func (a *AuthService) SendEmailVerification(ctx context.Context) error {
+	user, err := a.userRepo.GetByID(app.GetUserID(ctx))
+	if err != nil {
+		return errors.ErrorNotExistUser.New()
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+	return a.sendEmailVerification(ctx, user)
+}
+
+// VerifyEmail mark email address as verified by email verification token
+func (a *AuthService) VerifyEmail(ctx context.Context, bodyParam *schema.VerifyEmailBodyParams) error {
+	user, err := a.consumeUserToken(models.UserTokenEmailVerification, bodyParam.Token)
+	if err != nil {
+		return err
+	}
+
+	return a.userRepo.VerifyEmail(user.ID)
+}
+
+// tokenLink append token as query parameter of the link
+func tokenLink(link, token string) string {
+	target, err := url.Parse(link)
+	if err != nil {
+		return link + "?token=" + url.QueryEscape(token)
+	}
+
+	query := target.Query()
+	query.Set("token", token)
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// tokenExpired lifetime of user token from config in seconds
+func tokenExpired(expired, fallback int) time.Duration {
+	if expired <= 0 {
+		expired = fallback
+	}
+	return time.Duration(expired) * time.Second
+}
+
+// impersonationExpired lifetime of impersonation access token from config in seconds,
+// capped at the lifetime of access tokens so revoking the user or the admin covers it
+func impersonationExpired() int {
+	expired := config.Config.Impersonation.Expired
+	if expired <= 0 {
+		expired = defaultImpersonationExpired
+	}
+	if limit := int(revocation.TTL() / time.Second); expired > limit {
+		return limit
+	}
+	return expired
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/logger"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/totp"
+	"github.com/shasw94/projX/pkg/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// recoveryCodeCount number of recovery codes generated for user
+	recoveryCodeCount = 10
+	// defaultMFAIssuer issuer of provisioning uri when not configured
+	defaultMFAIssuer = "projX"
+	// defaultMFAChallengeExpired lifetime of mfa pending token in seconds when not configured
+	defaultMFAChallengeExpired = 300
+	// defaultMFAMaxAttempts failed attempts allowed per mfa pending token when not configured
+	defaultMFAMaxAttempts = 5
+)
+
+// MFAService totp two-factor authentication service
+type MFAService struct {
+	userRepo  interfaces.IUserRepository
+	mfaRepo   interfaces.IMFARepository
+	auditRepo interfaces.IAuditRepository
+}
+
+// NewMFAService return new IMFAService interface
+func NewMFAService(
+	user interfaces.IUserRepository,
+	mfa interfaces.IMFARepository,
+	audit interfaces.IAuditRepository,
+) interfaces.IMFAService {
+	return &MFAService{
+		userRepo:  user,
+		mfaRepo:   mfa,
+		auditRepo: audit,
+	}
+}
+
+// Enroll generate new totp secret for current user, it has to be confirmed by a first code
+func (m *MFAService) Enroll(ctx context.Context) (*schema.MFAEnrollment, error) {
+	user, err := m.userRepo.GetByID(app.GetUserID(ctx))
+	if err != nil {
+		return nil, errors.ErrorNotExistUser.New()
+	}
+
+	enabled, err := m.mfaRepo.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errors.ErrorMFAEnabled.New()
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.mfaRepo.Save(&models.UserMFA{UserID: user.ID, Secret: secret})
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.MFAEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(mfaIssuer(), user.Username, secret),
+	}, nil
+}
+
+// Confirm enable totp of current user by its first code, return new recovery codes
+func (m *MFAService) Confirm(ctx context.Context, bodyParam *schema.MFACodeBodyParams) (*schema.MFARecoveryCodes, error) {
+	userID := app.GetUserID(ctx)
+	mfa, err := m.mfaRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, errors.ErrorMFANotEnrolled.New()
+	}
+	if mfa.IsEnabled() {
+		return nil, errors.ErrorMFAEnabled.New()
+	}
+
+	ok, err := verifyTOTP(m.mfaRepo, mfa, bodyParam.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.ErrorInvalidOTP.New()
+	}
+
+	err = m.mfaRepo.Confirm(userID)
+	if err != nil {
+		return nil, err
+	}
+	m.audit(models.AuditMFAEnabled, userID, userID)
+
+	return generateRecoveryCodes(m.mfaRepo, userID)
+}
+
+// RegenerateRecoveryCodes replace recovery codes of current user, requires a valid totp code
+func (m *MFAService) RegenerateRecoveryCodes(ctx context.Context, bodyParam *schema.MFACodeBodyParams) (*schema.MFARecoveryCodes, error) {
+	userID := app.GetUserID(ctx)
+	mfa, err := m.mfaRepo.GetByUserID(userID)
+	if err != nil || !mfa.IsEnabled() {
+		return nil, errors.ErrorMFANotEnrolled.New()
+	}
+
+	ok, err := verifyTOTP(m.mfaRepo, mfa, bodyParam.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.ErrorInvalidOTP.New()
+	}
+
+	return generateRecoveryCodes(m.mfaRepo, userID)
+}
+
+// Reset remove totp and recovery codes of user, used by admin when user lost its device
+func (m *MFAService) Reset(ctx context.Context, userID string) error {
+	user, err := m.userRepo.GetByID(userID)
+	if err != nil {
+		return errors.ErrorNotExistUser.New()
+	}
+
+	err = m.mfaRepo.Delete(user.ID)
+	if err != nil {
+		return err
+	}
+	m.audit(models.AuditMFAReset, user.ID, app.GetUserID(ctx))
+	return nil
+}
+
+// audit record mfa audit event, failures are only logged
+func (m *MFAService) audit(event, userID, actorID string) {
+	err := m.auditRepo.Create(&models.AuditEvent{
+		Event:   event,
+		UserID:  userID,
+		ActorID: actorID,
+	})
+	if err != nil {
+		logger.Error("Failed to record audit event: ", err)
+	}
+}
+
+// verifyTOTP check totp code of user, each time step is only accepted once
+func verifyTOTP(repo interfaces.IMFARepository, mfa *models.UserMFA, code string) (bool, error) {
+	step, ok := totp.Validate(mfa.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return repo.UseStep(mfa.UserID, step)
+}
+
+// generateRecoveryCodes replace recovery codes of user, only hashes are stored
+func generateRecoveryCodes(repo interfaces.IMFARepository, userID string) (*schema.MFARecoveryCodes, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret, err := totp.GenerateSecret()
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(secret[:5] + "-" + secret[5:10])
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	err := repo.ReplaceRecoveryCodes(userID, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return &schema.MFARecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// hashRecoveryCode hash recovery code ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return utils.HashToken(normalized)
+}
+
+// mfaIssuer issuer of provisioning uri
+func mfaIssuer() string {
+	if config.Config.MFA.Issuer == "" {
+		return defaultMFAIssuer
+	}
+	return config.Config.MFA.Issuer
+}
+
+// mfaChallengeExpired lifetime of mfa pending token
+func mfaChallengeExpired() time.Duration {
+	expired := config.Config.MFA.ChallengeExpired
+	if expired <= 0 {
+		expired = defaultMFAChallengeExpired
+	}
+	return time.Duration(expired) * time.Second
+}
+
+// mfaMaxAttempts failed attempts allowed per mfa pending token
+func mfaMaxAttempts() int {
+	if config.Config.MFA.MaxAttempts <= 0 {
+		return defaultMFAMaxAttempts
+	}
+	return config.Config.MFA.MaxAttempts
+}
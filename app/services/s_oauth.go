@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/shasw94/projX/pkg/utils"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultOAuthCodeExpired lifetime of authorization code in seconds when not configured
+const defaultOAuthCodeExpired = 600
+
+// OAuthService oauth2 authorization server.
+// Scopes are permission guard names, tokens carry the granted scopes as permissions
+type OAuthService struct {
+	jwt        jwt.IJWTAuth
+	clientRepo interfaces.IOAuthClientRepository
+	codeRepo   interfaces.IOAuthCodeRepository
+	userRepo   interfaces.IUserRepository
+	permRepo   interfaces.IPermissionRepository
+	revocation interfaces.IRevocationStore
+}
+
+// NewOAuthService return new IOAuthService interface
+func NewOAuthService(
+	jwt jwt.IJWTAuth,
+	client interfaces.IOAuthClientRepository,
+	code interfaces.IOAuthCodeRepository,
+	user interfaces.IUserRepository,
+	perm interfaces.IPermissionRepository,
+	revocation interfaces.IRevocationStore,
+) interfaces.IOAuthService {
+	return &OAuthService{
+		jwt:        jwt,
+		clientRepo: client,
+		codeRepo:   code,
+		userRepo:   user,
+		permRepo:   perm,
+		revocation: revocation,
+	}
+}
+
+// CreateClient register new client, return the client and its plain secret.
+// Public clients have no secret
+func (s *OAuthService) CreateClient(ctx context.Context, param *schema.OAuthClientBodyParams) (*models.OAuthClient, string, error) {
+	grantTypes := utils.RemoveDuplicateValues(param.GrantTypes)
+	if param.Public && utils.InArray(models.OAuthGrantClientCredentials, grantTypes) {
+		return nil, "", errors.InvalidParams.Newm("public client can not use client_credentials grant")
+	}
+	if utils.InArray(models.OAuthGrantAuthorizationCode, grantTypes) && len(param.RedirectURIs) == 0 {
+		return nil, "", errors.InvalidParams.Newm("redirect_uris is required for authorization_code grant")
+	}
+
+	scopes := utils.RemoveDuplicateValues(param.Scopes)
+	if len(scopes) > 0 {
+		permissions, err := s.permRepo.GetPermissionsByGuardNames(scopes)
+		if err != nil {
+			return nil, "", errors.ErrorDatabaseGet.Newm(err.Error())
+		}
+		if len(permissions.GuardNames()) != len(scopes) {
+			return nil, "", errors.InvalidParams.Newm("scopes must be existing permission guard names")
+		}
+	}
+
+	client := &models.OAuthClient{
+		Name:         param.Name,
+		RedirectURIs: strings.Join(param.RedirectURIs, " "),
+		Scopes:       strings.Join(scopes, " "),
+		GrantTypes:   strings.Join(grantTypes, " "),
+		Public:       param.Public,
+		CreatedBy:    app.GetUserID(ctx),
+	}
+
+	var secret string
+	if !param.Public {
+		var err error
+		secret, err = utils.RandomToken(32)
+		if err != nil {
+			return nil, "", err
+		}
+		client.SecretHash = utils.HashToken(secret)
+	}
+
+	err := s.clientRepo.Create(client)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+// GetClient get client by id
+func (s *OAuthService) GetClient(ctx context.Context, id string) (*models.OAuthClient, error) {
+	client, err := s.clientRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.ErrorNotFound.New()
+	}
+	return client, nil
+}
+
+// ListClients list registered clients
+func (s *OAuthService) ListClients(ctx context.Context, param *schema.OAuthClientQueryParam) (*[]models.OAuthClient, error) {
+	return s.clientRepo.List(param)
+}
+
+// RevokeClient revoke client and every access token issued to it, including tokens acting for users
+func (s *OAuthService) RevokeClient(ctx context.Context, id string) error {
+	client, err := s.GetClient(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.clientRepo.Revoke(client.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.revocation.RevokeClient(client.ID)
+}
+
+// Authorize issue authorization code to client on behalf of current user.
+// The granted scopes are limited to permissions the user holds
+func (s *OAuthService) Authorize(ctx context.Context, param *schema.OAuthAuthorizeBodyParams) (*schema.OAuthAuthorizeResult, error) {
+	client, err := s.clientRepo.GetByID(param.ClientID)
+	if err != nil || client.IsRevoked() {
+		return nil, errors.ErrorInvalidClient.New()
+	}
+	if !client.HasGrantType(models.OAuthGrantAuthorizationCode) {
+		return nil, errors.ErrorUnauthorizedClient.New()
+	}
+
+	redirectURI := param.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIList()) == 1 {
+		redirectURI = client.RedirectURIList()[0]
+	}
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, errors.InvalidParams.Newm("redirect_uri is not registered for the client")
+	}
+
+	var held []string
+	if claims := app.GetClaims(ctx); claims != nil {
+		held = claims.Permissions
+	}
+	var allowed []string
+	for _, scope := range client.ScopeList() {
+		if utils.MatchAnyGuard(held, scope) {
+			allowed = append(allowed, scope)
+		}
+	}
+
+	scopes, err := s.grantScopes(param.Scope, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.codeRepo.Create(&models.OAuthAuthorizationCode{
+		CodeHash:            utils.HashToken(code),
+		ClientID:            client.ID,
+		UserID:              app.GetUserID(ctx),
+		RedirectURI:         redirectURI,
+		RedirectURIExplicit: param.RedirectURI != "",
+		Scope:               strings.Join(scopes, " "),
+		CodeChallenge:       param.CodeChallenge,
+		CodeChallengeMethod: param.CodeChallengeMethod,
+		ExpiresAt:           time.Now().Add(codeExpired()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		return nil, errors.InvalidParams.Newm(err.Error())
+	}
+	query := target.Query()
+	query.Set("code", code)
+	if param.State != "" {
+		query.Set("state", param.State)
+	}
+	target.RawQuery = query.Encode()
+
+	return &schema.OAuthAuthorizeResult{
+		Code:        code,
+		State:       param.State,
+		RedirectURI: target.String(),
+	}, nil
+}
+
+// Token handle token endpoint for client_credentials and authorization_code grants
+func (s *OAuthService) Token(ctx context.Context, param *schema.OAuthTokenParams) (*schema.OAuthTokenResponse, error) {
+	switch param.GrantType {
+	case models.OAuthGrantClientCredentials, models.OAuthGrantAuthorizationCode:
+	default:
+		return nil, errors.ErrorUnsupportedGrantType.New()
+	}
+
+	client, err := s.authenticateClient(param.ClientID, param.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !client.HasGrantType(param.GrantType) {
+		return nil, errors.ErrorUnauthorizedClient.New()
+	}
+
+	if param.GrantType == models.OAuthGrantClientCredentials {
+		scopes, err := s.grantScopes(param.Scope, client.ScopeList())
+		if err != nil {
+			return nil, err
+		}
+		return s.issueToken(client.ID, client, scopes)
+	}
+
+	return s.exchangeCode(client, param)
+}
+
+// authenticateClient verify client id and secret, public clients are identified by id only
+func (s *OAuthService) authenticateClient(clientID, secret string) (*models.OAuthClient, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil || client.IsRevoked() {
+		return nil, errors.ErrorInvalidClient.New()
+	}
+
+	if client.Public {
+		return client, nil
+	}
+
+	hash := utils.HashToken(secret)
+	if secret == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+		return nil, errors.ErrorInvalidClient.New()
+	}
+	return client, nil
+}
+
+// exchangeCode exchange authorization code for access token, verifying PKCE code verifier
+func (s *OAuthService) exchangeCode(client *models.OAuthClient, param *schema.OAuthTokenParams) (*schema.OAuthTokenResponse, error) {
+	code, err := s.codeRepo.GetByHash(utils.HashToken(param.Code))
+	if err != nil || code.ClientID != client.ID || code.IsUsed() || code.IsExpired() {
+		return nil, errors.ErrorInvalidGrant.New()
+	}
+	// redirect_uri sent with the authorization request must be repeated exactly (RFC 6749 4.1.3)
+	if (code.RedirectURIExplicit || param.RedirectURI != "") && param.RedirectURI != code.RedirectURI {
+		return nil, errors.ErrorInvalidGrant.New()
+	}
+	if !verifyCodeChallenge(code.CodeChallenge, param.CodeVerifier) {
+		return nil, errors.ErrorInvalidGrant.New()
+	}
+
+	ok, err := s.codeRepo.MarkUsed(code.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// another request exchanged the code first
+		return nil, errors.ErrorInvalidGrant.New()
+	}
+
+	user, err := s.userRepo.GetByID(code.UserID)
+	if err != nil {
+		return nil, errors.ErrorInvalidGrant.New()
+	}
+
+	scopes, err := s.grantScopes(code.Scope, strings.Fields(code.Scope))
+	if err != nil {
+		return nil, err
+	}
+	return s.issueToken(user.ID, client, scopes)
+}
+
+// grantScopes check requested scopes are allowed, all allowed scopes are granted when nothing is requested.
+// Scopes whose permission no longer exists are dropped
+func (s *OAuthService) grantScopes(requested string, allowed []string) ([]string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		scopes = allowed
+	}
+	for _, scope := range scopes {
+		if !utils.InArray(scope, allowed) {
+			return nil, errors.ErrorInvalidScope.New()
+		}
+	}
+	if len(scopes) == 0 {
+		return []string{}, nil
+	}
+
+	permissions, err := s.permRepo.GetPermissionsByGuardNames(utils.RemoveDuplicateValues(scopes))
+	if err != nil {
+		return nil, errors.ErrorDatabaseGet.Newm(err.Error())
+	}
+
+	granted := permissions.GuardNames()
+	sort.Strings(granted)
+	return granted, nil
+}
+
+// issueToken issue access token for subject, oauth tokens are never refreshed
+func (s *OAuthService) issueToken(subject string, client *models.OAuthClient, scopes []string) (*schema.OAuthTokenResponse, error) {
+	scope := strings.Join(scopes, " ")
+	token, err := s.jwt.GenerateAccessToken(subject, jwt.CustomClaims{
+		Permissions: scopes,
+		ClientID:    client.ID,
+		Scope:       scope,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims := token.GetAccessClaims()
+	return &schema.OAuthTokenResponse{
+		AccessToken: token.GetAccessToken(),
+		TokenType:   token.GetTokenType(),
+		ExpiresIn:   claims.ExpiresAt.Unix() - claims.IssuedAt.Unix(),
+		Scope:       scope,
+	}, nil
+}
+
+// verifyCodeChallenge verify PKCE code verifier against S256 code challenge
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// codeExpired lifetime of authorization code
+func codeExpired() time.Duration {
+	expired := config.Config.OAuth.CodeExpired
+	if expired <= 0 {
+		expired = defaultOAuthCodeExpired
+	}
+	return time.Duration(expired) * time.Second
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/shasw94/projX/pkg/errors"
+)
+
+// SessionService session service
+type SessionService struct {
+	sessionRepo interfaces.ISessionRepository
+	tokenRepo   interfaces.IRefreshTokenRepository
+	revocation  interfaces.IRevocationStore
+}
+
+// NewSessionService return new ISessionService interface
+func NewSessionService(
+	session interfaces.ISessionRepository,
+	token interfaces.IRefreshTokenRepository,
+	revocation interfaces.IRevocationStore,
+) interfaces.ISessionService {
+	return &SessionService{
+		sessionRepo: session,
+		tokenRepo:   token,
+		revocation:  revocation,
+	}
+}
+
+// revokeSession revoke session, its refresh token family and its access tokens
+func revokeSession(
+	sessionRepo interfaces.ISessionRepository,
+	tokenRepo interfaces.IRefreshTokenRepository,
+	revocation interfaces.IRevocationStore,
+	sessionID string,
+) error {
+	if err := sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+	if err := tokenRepo.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+	return revocation.RevokeSession(sessionID)
+}
+
+// List active sessions of current user
+func (s *SessionService) List(ctx context.Context) (*[]models.Session, error) {
+	return s.sessionRepo.ListActiveByUser(app.GetUserID(ctx))
+}
+
+// Revoke a session of current user
+func (s *SessionService) Revoke(ctx context.Context, id string) error {
+	session, err := s.sessionRepo.GetByID(id)
+	if err != nil || session.UserID != app.GetUserID(ctx) {
+		return errors.ErrorNotFound.New()
+	}
+
+	return revokeSession(s.sessionRepo, s.tokenRepo, s.revocation, session.ID)
+}
+
+// RevokeOthers revoke all sessions of current user except the current one
+func (s *SessionService) RevokeOthers(ctx context.Context) error {
+	sessions, err := s.sessionRepo.ListActiveByUser(app.GetUserID(ctx))
+	if err != nil {
+		return err
+	}
+
+	current := app.GetSessionID(ctx)
+	for _, session := range *sessions {
+		if session.ID == current {
+			continue
+		}
+		if err := revokeSession(s.sessionRepo, s.tokenRepo, s.revocation, session.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
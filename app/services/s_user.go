@@ -23,8 +23,14 @@ func NewUserService(user interfaces.IUserRepository, role interfaces.IRoleReposi
 	}
 }
 
-func (u *UserService) checkPermission(id string, data map[string]interface{}) bool {
-	return data["id"] == id
+// LoadAttributes attributes of the user evaluated by authorization rules, users own their record
+func (u *UserService) LoadAttributes(ctx context.Context, id string) (*schema.ResourceAttributes, error) {
+	user, err := u.userRepo.GetByID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "UserService.LoadAttributes")
+	}
+
+	return &schema.ResourceAttributes{ID: user.ID, OwnerID: user.ID}, nil
 }
 
 // GetByID get user by ID
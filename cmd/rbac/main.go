@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/shasw94/projX/app"
+	"github.com/shasw94/projX/app/migration"
+	"github.com/shasw94/projX/config"
+	"os"
+)
+
+const usage = `Usage: rbac sync [flags]
+
+Bring roles, permissions and bootstrap users in line with the rbac seed file.
+
+Flags:
+`
+
+func main() {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	file := flags.String("file", config.Config.RBAC.SeedFile, "yaml or json seed file")
+	dryRun := flags.Bool("dry-run", false, "print the plan without changing anything")
+	prune := flags.Bool("prune", false, "delete roles and permissions missing from the seed file")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	if len(os.Args) < 2 || os.Args[1] != "sync" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	_ = flags.Parse(os.Args[2:])
+
+	container := app.BuildContainer()
+	if err := migration.Migrate(container); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to migrate data:", err)
+		os.Exit(1)
+	}
+
+	plan, err := migration.SyncRBAC(container, *file, *prune, *dryRun)
+	if plan != nil {
+		fmt.Println(plan)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to sync rbac seed:", err)
+		os.Exit(1)
+	}
+	if *dryRun {
+		fmt.Println("dry run, nothing was changed")
+	}
+}
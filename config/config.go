@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -37,16 +39,125 @@ type Schema struct {
 		Expired             int    `mapstructure:"expired"`
 		SigningRefreshKey   string `mapstructure:"signing_refresh_key"`
 		ExpiredRefreshToken int    `mapstructure:"expired_refresh_token"`
+		SigningKID          string `mapstructure:"signing_kid"`
+		Keys                []struct {
+			KID            string `mapstructure:"kid"`
+			Algorithm      string `mapstructure:"algorithm"`
+			PrivateKeyFile string `mapstructure:"private_key_file"`
+			PublicKeyFile  string `mapstructure:"public_key_file"`
+			RetireAt       string `mapstructure:"retire_at"`
+		} `mapstructure:"keys"`
 	} `mapstructure:"jwt_auth"`
 
+	SessionCookie struct {
+		Enable   bool   `mapstructure:"enable"`
+		Domain   string `mapstructure:"domain"`
+		Path     string `mapstructure:"path"`
+		Secure   bool   `mapstructure:"secure"`
+		SameSite string `mapstructure:"same_site"`
+	} `mapstructure:"session_cookie"`
+
+	OAuth struct {
+		CodeExpired int `mapstructure:"code_expired"`
+	} `mapstructure:"oauth"`
+
+	Impersonation struct {
+		Expired int `mapstructure:"expired"`
+	} `mapstructure:"impersonation"`
+
+	MFA struct {
+		Issuer           string `mapstructure:"issuer"`
+		ChallengeExpired int    `mapstructure:"challenge_expired"`
+		MaxAttempts      int    `mapstructure:"max_attempts"`
+	} `mapstructure:"mfa"`
+
+	Mailer struct {
+		Driver string `mapstructure:"driver"`
+		From   string `mapstructure:"from"`
+		Dir    string `mapstructure:"dir"`
+		SMTP   struct {
+			Host     string `mapstructure:"host"`
+			Port     int    `mapstructure:"port"`
+			Username string `mapstructure:"username"`
+			Password string `mapstructure:"password"`
+		} `mapstructure:"smtp"`
+	} `mapstructure:"mailer"`
+
+	Account struct {
+		RequireVerifiedEmail bool   `mapstructure:"require_verified_email"`
+		PasswordResetExpired int    `mapstructure:"password_reset_expired"`
+		EmailVerifyExpired   int    `mapstructure:"email_verify_expired"`
+		PasswordResetURL     string `mapstructure:"password_reset_url"`
+		EmailVerifyURL       string `mapstructure:"email_verify_url"`
+	} `mapstructure:"account"`
+
+	Lockout struct {
+		MaxAttempts   int `mapstructure:"max_attempts"`
+		IPMaxAttempts int `mapstructure:"ip_max_attempts"`
+		DisableAfter  int `mapstructure:"disable_after"`
+		Window        int `mapstructure:"window"`
+		BaseDelay     int `mapstructure:"base_delay"`
+		MaxDelay      int `mapstructure:"max_delay"`
+	} `mapstructure:"lockout"`
+
+	RateLimit struct {
+		Enable   bool `mapstructure:"enable"`
+		Policies map[string]struct {
+			Algorithm string `mapstructure:"algorithm"`
+			Limit     int    `mapstructure:"limit"`
+			Period    int    `mapstructure:"period"`
+			KeyBy     string `mapstructure:"key_by"`
+		} `mapstructure:"policies"`
+	} `mapstructure:"rate_limit"`
+
+	PasswordPolicy struct {
+		MinLength     int    `mapstructure:"min_length"`
+		MaxLength     int    `mapstructure:"max_length"`
+		RequireUpper  bool   `mapstructure:"require_upper"`
+		RequireLower  bool   `mapstructure:"require_lower"`
+		RequireDigit  bool   `mapstructure:"require_digit"`
+		RequireSymbol bool   `mapstructure:"require_symbol"`
+		BanUsername   bool   `mapstructure:"ban_username"`
+		BanEmail      bool   `mapstructure:"ban_email"`
+		BreachedList  string `mapstructure:"breached_list"`
+	} `mapstructure:"password_policy"`
+
+	PasswordHash struct {
+		Algorithm  string `mapstructure:"algorithm"`
+		BcryptCost int    `mapstructure:"bcrypt_cost"`
+		Argon2id   struct {
+			Memory      int `mapstructure:"memory"`
+			Iterations  int `mapstructure:"iterations"`
+			Parallelism int `mapstructure:"parallelism"`
+			SaltLength  int `mapstructure:"salt_length"`
+			KeyLength   int `mapstructure:"key_length"`
+		} `mapstructure:"argon2id"`
+	} `mapstructure:"password_hash"`
+
 	Casbin struct {
-		Enable           bool   `mapstructure:"enable""`
+		Enable           bool   `mapstructure:"enable"`
 		Debug            bool   `mapstructure:"debug"`
 		Model            string `mapstructure:"model"`
 		AutoLoad         bool   `mapstructure:"auto_load"`
-		AutoLoadInternal int    `mapstructure:"auto_load_internal"`
+		AutoLoadInterval int    `mapstructure:"auto_load_interval"`
 	} `mapstructure:"casbin"`
 
+	PermissionCache struct {
+		Enable bool `mapstructure:"enable"`
+		TTL    int  `mapstructure:"ttl"`
+	} `mapstructure:"permission_cache"`
+
+	GrantSweeper struct {
+		Enable   bool `mapstructure:"enable"`
+		Interval int  `mapstructure:"interval"`
+	} `mapstructure:"grant_sweeper"`
+
+	RBAC struct {
+		SeedFile      string `mapstructure:"seed_file"`
+		SyncOnStartup bool   `mapstructure:"sync_on_startup"`
+		Prune         bool   `mapstructure:"prune"`
+	} `mapstructure:"rbac"`
+
 	CORS struct {
 		Enable           bool     `mapstructure:"enable"`
 		AllowOrigins     []string `mapstructure:"allow_origins"`
@@ -60,6 +171,21 @@ type Schema struct {
 // Config global parameter config
 var Config Schema
 
+// configFile path of the loaded config file
+var configFile string
+
+// Path resolve relative path of a file referenced by config, paths which do not exist
+// relative to the working directory are resolved relative to the config file
+func Path(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	if _, err := os.Stat(p); err == nil || configFile == "" {
+		return p
+	}
+	return filepath.Join(filepath.Dir(configFile), p)
+}
+
 func init() {
 	config := viper.New()
 	config.SetConfigName("config")
@@ -77,6 +203,7 @@ func init() {
 	if err != nil {
 		panic(fmt.Errorf("fatal error config file: %s", err))
 	}
+	configFile = config.ConfigFileUsed()
 
 	err = config.Unmarshal(&Config)
 	if err != nil {
@@ -203,9 +203,6 @@ const docTemplate = `{
                 "password": {
                     "type": "string"
                 },
-                "role_id": {
-                    "type": "string"
-                },
                 "username": {
                     "type": "string"
                 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/shasw94/projX/app"
 	"github.com/shasw94/projX/app/migration"
+	"github.com/shasw94/projX/config"
 	_ "github.com/shasw94/projX/docs"
 	"github.com/shasw94/projX/logger"
 	"net/http"
@@ -27,6 +28,12 @@ func main() {
 		logger.Warn("Failed to migrate data: ", err)
 	}
 
+	if cfg := config.Config.RBAC; cfg.SyncOnStartup {
+		if _, err := migration.SyncRBAC(container, cfg.SeedFile, cfg.Prune, false); err != nil {
+			logger.Warn("Failed to sync rbac seed: ", err)
+		}
+	}
+
 	server := &http.Server{
 		Addr:    ":8888",
 		Handler: engine,
@@ -41,7 +48,7 @@ func main() {
 
 	// Wait for interrupt signal to gracefully shut down the server with
 	// a timeout of 1 seconds
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	// kill (no param) default send syscall.SIGTERM
 	// kill -2 is syscall.SIGINT
 	// kill -9 is syscall.SIGKILL but can't be a catch, so don't need to add it
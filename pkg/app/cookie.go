@@ -0,0 +1,67 @@
+package app
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// cookies of browser sessions
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	CSRFTokenCookie    = "csrf_token"
+	CSRFTokenHeader    = "X-CSRF-Token"
+)
+
+// CookieOptions attributes of session cookies
+type CookieOptions struct {
+	Domain   string
+	Path     string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// ParseSameSite parse SameSite attribute from strict, lax or none, defaults to strict
+func ParseSameSite(s string) http.SameSite {
+	switch s {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
+}
+
+// SetCookie set cookie which expires after maxAge seconds, httpOnly cookies cannot be read by scripts
+func SetCookie(c *gin.Context, opts CookieOptions, name, value string, maxAge int, httpOnly bool) {
+	path := opts.Path
+	if path == "" {
+		path = "/"
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   opts.Secure,
+		HttpOnly: httpOnly,
+		SameSite: opts.SameSite,
+	})
+}
+
+// ClearCookie remove cookie from the browser
+func ClearCookie(c *gin.Context, opts CookieOptions, name string, httpOnly bool) {
+	SetCookie(c, opts, name, "", -1, httpOnly)
+}
+
+// HasSessionCookie the request carries the access or refresh token cookie
+func HasSessionCookie(c *gin.Context) bool {
+	for _, name := range []string{AccessTokenCookie, RefreshTokenCookie} {
+		if value, err := c.Cookie(name); err == nil && value != "" {
+			return true
+		}
+	}
+	return false
+}
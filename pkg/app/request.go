@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/pkg/jwt"
 	"strings"
 )
 
@@ -10,12 +11,16 @@ import (
 const (
 	prefix           = "gin-go"
 	UserIDKey        = prefix + "/user-id"
+	SessionIDKey     = prefix + "/session-id"
+	ActorIDKey       = prefix + "/actor-id"
+	ClaimsKey        = prefix + "/claims"
 	ReqBodyKey       = prefix + "/req-body"
 	ResBodyKey       = prefix + "/res-body"
 	LoggerReqBodyKey = prefix + "/logger-req-body"
 )
 
-// GetToken from header
+// GetToken from header, falls back to the access token cookie of browser sessions
+// when the request has no Authorization header
 func GetToken(c *gin.Context) string {
 	var token string
 	auth := c.GetHeader("Authorization")
@@ -23,9 +28,25 @@ func GetToken(c *gin.Context) string {
 	if auth != "" && strings.HasPrefix(auth, prefix) {
 		token = auth[len(prefix):]
 	}
+	if auth == "" {
+		token, _ = c.Cookie(AccessTokenCookie)
+	}
 	return token
 }
 
+// GetAPIKey from X-API-Key header or Authorization header with ApiKey scheme
+func GetAPIKey(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	auth := c.GetHeader("Authorization")
+	prefix := "ApiKey "
+	if strings.HasPrefix(auth, prefix) {
+		return auth[len(prefix):]
+	}
+	return ""
+}
+
 // GetUserID get user id from context
 func GetUserID(c context.Context) string {
 	userID := c.Value(UserIDKey)
@@ -39,3 +60,45 @@ func GetUserID(c context.Context) string {
 func SetUserID(c *gin.Context, userID string) {
 	c.Set(UserIDKey, userID)
 }
+
+// GetActorID get id of the impersonating user from context
+func GetActorID(c context.Context) string {
+	actorID := c.Value(ActorIDKey)
+	if actorID == nil {
+		return ""
+	}
+	return actorID.(string)
+}
+
+// SetActorID to context
+func SetActorID(c *gin.Context, actorID string) {
+	c.Set(ActorIDKey, actorID)
+}
+
+// GetSessionID get session id from context
+func GetSessionID(c context.Context) string {
+	sessionID := c.Value(SessionIDKey)
+	if sessionID == nil {
+		return ""
+	}
+	return sessionID.(string)
+}
+
+// SetSessionID to context
+func SetSessionID(c *gin.Context, sessionID string) {
+	c.Set(SessionIDKey, sessionID)
+}
+
+// GetClaims get access token claims from context
+func GetClaims(c context.Context) *jwt.AccessClaims {
+	claims := c.Value(ClaimsKey)
+	if claims == nil {
+		return nil
+	}
+	return claims.(*jwt.AccessClaims)
+}
+
+// SetClaims to context
+func SetClaims(c *gin.Context, claims *jwt.AccessClaims) {
+	c.Set(ClaimsKey, claims)
+}
@@ -19,6 +19,10 @@ var ErrorCodeMap = map[ErrorType]string{
 	ErrorInvalidOldPass:        "ERROR_INVALID_OLD_PASS",
 	ErrorNotFound:              "ERROR_NOT_FOUND",
 	ErrorPasswordRequired:      "ERROR_PASSWORD_REQUIRED",
+	ErrorInvalidOTP:            "ERROR_INVALID_OTP",
+	ErrorMFAEnabled:            "ERROR_MFA_ENABLED",
+	ErrorMFANotEnrolled:        "ERROR_MFA_NOT_ENROLLED",
+	ErrorEmailNotVerified:      "ERROR_EMAIL_NOT_VERIFIED",
 	ErrorExistMenuName:         "ERROR_EXIST_MENU_NAME",
 	ErrorUserDisabled:          "ERROR_USER_DISABLED",
 	ErrorNoPermission:          "ERROR_NO_PERMISSION",
@@ -29,9 +33,19 @@ var ErrorCodeMap = map[ErrorType]string{
 	ErrorNotExistUser:          "ERROR_NOT_EXIST_USER",
 	ErrorExistRoleUser:         "ERROR_EXIST_ROLE_USER",
 	ErrorNotExistRole:          "ERROR_NOT_EXIST_ROLE",
+	ErrorNotExistPermission:    "ERROR_NOT_EXIST_PERMISSION",
+	ErrorExistPermission:       "ERROR_EXIST_PERMISSION",
 	ErrorTokenExpired:          "ERROR_TOKEN_EXPIRED",
 	ErrorTokenInvalid:          "ERROR_TOKEN_INVALID",
 	ErrorTokenMalformed:        "ERROR_TOKEN_MALFORMED",
+	ErrorTokenRevoked:          "ERROR_TOKEN_REVOKED",
+	ErrorAPIKeyInvalid:         "ERROR_API_KEY_INVALID",
+	ErrorCSRFTokenInvalid:      "ERROR_CSRF_TOKEN_INVALID",
+	ErrorInvalidClient:         "ERROR_INVALID_CLIENT",
+	ErrorInvalidGrant:          "ERROR_INVALID_GRANT",
+	ErrorInvalidScope:          "ERROR_INVALID_SCOPE",
+	ErrorUnauthorizedClient:    "ERROR_UNAUTHORIZED_CLIENT",
+	ErrorUnsupportedGrantType:  "ERROR_UNSUPPORTED_GRANT_TYPE",
 }
 
 // GetCode get error code
@@ -18,6 +18,10 @@ var MsgMap = map[ErrorType]string{
 	ErrorInvalidOldPass:        "Old password is incorrect",
 	ErrorNotFound:              "Resource does not exist",
 	ErrorPasswordRequired:      "Password is required",
+	ErrorInvalidOTP:            "Verification code is invalid",
+	ErrorMFAEnabled:            "Two-factor authentication is already enabled",
+	ErrorMFANotEnrolled:        "Two-factor authentication is not enrolled",
+	ErrorEmailNotVerified:      "Email address is not verified",
 	ErrorExistMenuName:         "Menu name already exists",
 	ErrorUserDisabled:          "User is disabled, please contact administrator",
 	ErrorNoPermission:          "No access",
@@ -28,9 +32,19 @@ var MsgMap = map[ErrorType]string{
 	ErrorNotExistUser:          "Account is invalid",
 	ErrorExistRoleUser:         "The role has been given to the user and is not allowed to be deleted",
 	ErrorNotExistRole:          "Role user is disabled, please contact administrator",
+	ErrorNotExistPermission:    "Permission does not exist",
+	ErrorExistPermission:       "Permission name already exists",
 	ErrorTokenExpired:          "Token is expired",
 	ErrorTokenInvalid:          "Token is invalid",
 	ErrorTokenMalformed:        "That's not even a token",
+	ErrorTokenRevoked:          "Token has been revoked",
+	ErrorAPIKeyInvalid:         "API key is invalid, expired or revoked",
+	ErrorCSRFTokenInvalid:      "CSRF token is missing or invalid",
+	ErrorInvalidClient:         "Client authentication failed",
+	ErrorInvalidGrant:          "Authorization grant is invalid, expired or revoked",
+	ErrorInvalidScope:          "Requested scope is invalid or exceeds the granted scope",
+	ErrorUnauthorizedClient:    "Client is not allowed to use this grant type",
+	ErrorUnsupportedGrantType:  "Grant type is not supported",
 }
 
 // GetMsg from status
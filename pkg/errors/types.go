@@ -24,6 +24,10 @@ const (
 	ErrorLoginFailed           ErrorType = 422
 	ErrorInvalidOldPass        ErrorType = 423
 	ErrorPasswordRequired      ErrorType = 424
+	ErrorInvalidOTP            ErrorType = 425
+	ErrorMFAEnabled            ErrorType = 426
+	ErrorMFANotEnrolled        ErrorType = 427
+	ErrorEmailNotVerified      ErrorType = 428
 	ErrorTooManyRequest        ErrorType = 429
 	ErrorInternalServer        ErrorType = 512
 	ErrorAuthCheckTokenFail    ErrorType = 401
@@ -32,19 +36,29 @@ const (
 	ErrorAuth                  ErrorType = 407
 	ErrorExistEmail            ErrorType = 430
 	ErrorNotExistRole          ErrorType = 431
+	ErrorNotExistPermission    ErrorType = 432
+	ErrorExistPermission       ErrorType = 433
 	ErrorTokenExpired          ErrorType = 461
 	ErrorTokenInvalid          ErrorType = 462
 	ErrorTokenMalformed        ErrorType = 463
+	ErrorTokenRevoked          ErrorType = 464
+	ErrorAPIKeyInvalid         ErrorType = 465
+	ErrorCSRFTokenInvalid      ErrorType = 466
+	ErrorInvalidClient         ErrorType = 470
+	ErrorInvalidGrant          ErrorType = 471
+	ErrorInvalidScope          ErrorType = 472
+	ErrorUnauthorizedClient    ErrorType = 473
+	ErrorUnsupportedGrantType  ErrorType = 474
 
-	// System errors
-	ErrorMarshal ErrorType = iota + 1000
-	ErrorUnmarshal
-	ErrorDatabaseGet
-	ErrorDatabaseCreate
-	ErrorDatabaseUpdate
-	ErrorDatabaseDelete
+	// System errors, values are pinned so adding errors above does not renumber them
+	ErrorMarshal        ErrorType = 1029
+	ErrorUnmarshal      ErrorType = 1030
+	ErrorDatabaseGet    ErrorType = 1031
+	ErrorDatabaseCreate ErrorType = 1032
+	ErrorDatabaseUpdate ErrorType = 1033
+	ErrorDatabaseDelete ErrorType = 1034
 
-	ErrorInvalidPassword
+	ErrorInvalidPassword ErrorType = 1035
 )
 
 type ErrorType int
@@ -98,4 +112,5 @@ var (
 	ErrTokenExpired   = ErrorTokenExpired.New()
 	ErrTokenInvalid   = ErrorTokenInvalid.New()
 	ErrTokenMalformed = ErrorTokenMalformed.New()
+	ErrTokenRevoked   = ErrorTokenRevoked.New()
 )
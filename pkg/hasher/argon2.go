@@ -0,0 +1,147 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+// Argon2id name of argon2id algorithm
+const Argon2id = "argon2id"
+
+// ErrInvalidArgon2idHash the encoded hash is not a valid argon2id PHC string
+var ErrInvalidArgon2idHash = errors.New("hasher: invalid argon2id hash")
+
+// Argon2idParams parameters of argon2id, memory in KiB
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams parameters recommended by OWASP, used when not configured
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var b64 = base64.RawStdEncoding
+
+// Argon2idAlgorithm argon2id hashes in PHC string format,
+// e.g. $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+type Argon2idAlgorithm struct {
+	params Argon2idParams
+}
+
+// NewArgon2id return argon2id algorithm hashing with params, unset params fall back to defaults
+func NewArgon2id(params Argon2idParams) *Argon2idAlgorithm {
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2idParams.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = DefaultArgon2idParams.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultArgon2idParams.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultArgon2idParams.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultArgon2idParams.KeyLength
+	}
+	return &Argon2idAlgorithm{params: params}
+}
+
+// Name of the algorithm
+func (a *Argon2idAlgorithm) Name() string {
+	return Argon2id
+}
+
+// Hash return argon2id PHC string of password with random salt
+func (a *Argon2idAlgorithm) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := a.params
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		Argon2id, argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		b64.EncodeToString(salt), b64.EncodeToString(key),
+	), nil
+}
+
+// Verify compare password with argon2id PHC string using the parameters stored in it
+func (a *Argon2idAlgorithm) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// Match the encoded hash is an argon2id PHC string
+func (a *Argon2idAlgorithm) Match(encoded string) bool {
+	_, _, _, err := decodeArgon2id(encoded)
+	return err == nil
+}
+
+// NeedsRehash the hash was made with other parameters
+func (a *Argon2idAlgorithm) NeedsRehash(encoded string) bool {
+	params, salt, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	p := a.params
+	return params.Memory != p.Memory ||
+		params.Iterations != p.Iterations ||
+		params.Parallelism != p.Parallelism ||
+		params.KeyLength != p.KeyLength ||
+		uint32(len(salt)) != p.SaltLength
+}
+
+// decodeArgon2id parse argon2id PHC string, return its parameters, salt and key
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != Argon2id {
+		return params, nil, nil, ErrInvalidArgon2idHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidArgon2idHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidArgon2idHash
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, ErrInvalidArgon2idHash
+	}
+
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil || len(salt) == 0 {
+		return params, nil, nil, ErrInvalidArgon2idHash
+	}
+	key, err := b64.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidArgon2idHash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
@@ -0,0 +1,63 @@
+package hasher
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+// Bcrypt name of bcrypt algorithm
+const Bcrypt = "bcrypt"
+
+// DefaultBcryptCost cost of bcrypt when not configured
+const DefaultBcryptCost = bcrypt.DefaultCost
+
+// BcryptAlgorithm bcrypt hashes in modular crypt format, e.g. $2a$10$...
+type BcryptAlgorithm struct {
+	cost int
+}
+
+// NewBcrypt return bcrypt algorithm hashing with cost
+func NewBcrypt(cost int) *BcryptAlgorithm {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = DefaultBcryptCost
+	}
+	return &BcryptAlgorithm{cost: cost}
+}
+
+// Name of the algorithm
+func (b *BcryptAlgorithm) Name() string {
+	return Bcrypt
+}
+
+// Hash return bcrypt hash of password
+func (b *BcryptAlgorithm) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Verify compare password with bcrypt hash
+func (b *BcryptAlgorithm) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Match the encoded hash is a bcrypt hash
+func (b *BcryptAlgorithm) Match(encoded string) bool {
+	if !strings.HasPrefix(encoded, "$2a$") && !strings.HasPrefix(encoded, "$2b$") && !strings.HasPrefix(encoded, "$2y$") {
+		return false
+	}
+	_, err := bcrypt.Cost([]byte(encoded))
+	return err == nil
+}
+
+// NeedsRehash the hash was made with another cost
+func (b *BcryptAlgorithm) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != b.cost
+}
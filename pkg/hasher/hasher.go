@@ -0,0 +1,90 @@
+package hasher
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrUnknownAlgorithm the encoded hash was not made by any known algorithm
+var ErrUnknownAlgorithm = errors.New("hasher: unknown password hash algorithm")
+
+// Algorithm hash and verify passwords of one algorithm
+type Algorithm interface {
+	// Name of the algorithm
+	Name() string
+	// Hash return encoded hash of password with current parameters
+	Hash(password string) (string, error)
+	// Verify compare password with encoded hash made by the algorithm
+	Verify(encoded, password string) (bool, error)
+	// Match the encoded hash was made by the algorithm
+	Match(encoded string) bool
+	// NeedsRehash the encoded hash was made with other parameters than the current ones
+	NeedsRehash(encoded string) bool
+}
+
+// Hasher hash passwords with the preferred algorithm and verify hashes of every known algorithm
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+// New return hasher preferring the first algorithm, the others are only used to verify
+func New(preferred Algorithm, others ...Algorithm) *Hasher {
+	return &Hasher{
+		preferred:  preferred,
+		algorithms: append([]Algorithm{preferred}, others...),
+	}
+}
+
+// Hash return encoded hash of password by the preferred algorithm
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify compare password with encoded hash by the algorithm which made it
+func (h *Hasher) Verify(encoded, password string) (bool, error) {
+	algorithm := h.algorithm(encoded)
+	if algorithm == nil {
+		return false, ErrUnknownAlgorithm
+	}
+	return algorithm.Verify(encoded, password)
+}
+
+// NeedsRehash the encoded hash was not made by the preferred algorithm with its current parameters
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	return !h.preferred.Match(encoded) || h.preferred.NeedsRehash(encoded)
+}
+
+// IsHashed the value is an encoded hash of a known algorithm
+func (h *Hasher) IsHashed(value string) bool {
+	return h.algorithm(value) != nil
+}
+
+func (h *Hasher) algorithm(encoded string) Algorithm {
+	for _, algorithm := range h.algorithms {
+		if algorithm.Match(encoded) {
+			return algorithm
+		}
+	}
+	return nil
+}
+
+var (
+	mu       sync.RWMutex
+	fallback = New(NewArgon2id(DefaultArgon2idParams), NewBcrypt(DefaultBcryptCost))
+	current  = fallback
+)
+
+// Default return hasher used by the application, argon2id with default parameters until SetDefault is called
+func Default() *Hasher {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// SetDefault replace hasher used by the application
+func SetDefault(h *Hasher) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = h
+}
@@ -1,9 +1,11 @@
 package wrapper
 
 import (
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/shasw94/projX/pkg/errors"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -25,13 +27,25 @@ func Wrap(fn GinHandlerFn) gin.HandlerFunc {
 	}
 }
 
+// withContext fill message with the context of the error, e.g. failed validation rules
+func withContext(msg string, err error) string {
+	context := errors.GetErrorContext(err)
+	if context == nil || context["message"] == "" {
+		return msg
+	}
+	if strings.Contains(msg, "%s") {
+		return fmt.Sprintf(msg, context["message"])
+	}
+	return msg + " - " + context["message"]
+}
+
 // Translate gohttp.Response to response
 func Translate(c *gin.Context, res Response) {
 	result := gin.H{}
 	if _, ok := res.Error.(errors.CustomError); ok {
 		status := int(errors.GetType(res.Error))
 		result[StatusField] = status
-		result[MessageField] = errors.GetMsg(status)
+		result[MessageField] = withContext(errors.GetMsg(status), res.Error)
 		result[CodeField] = errors.GetCode(status)
 	}
 
@@ -40,9 +54,12 @@ func Translate(c *gin.Context, res Response) {
 		result[DataField] = res.Data
 	}
 
-	if result[CodeField] == "ERROR_TOKEN_EXPIRED" {
+	switch result[CodeField] {
+	case "ERROR_TOKEN_EXPIRED", "ERROR_NO_PERMISSION":
 		c.JSON(http.StatusForbidden, result)
-	} else {
+	case "ERROR_TOO_MANY_REQUEST":
+		c.JSON(http.StatusTooManyRequests, result)
+	default:
 		c.JSON(http.StatusOK, result)
 	}
 }
@@ -0,0 +1,60 @@
+package jwt
+
+import "github.com/golang-jwt/jwt/v4"
+
+// RefreshClaims claims of refresh token
+type RefreshClaims struct {
+	jwt.RegisteredClaims
+	FamilyID string `json:"fid"`
+}
+
+// Actor party acting on behalf of the subject of the token (RFC 8693 act claim)
+type Actor struct {
+	Subject string `json:"sub"`
+}
+
+// CustomClaims authorization claims embedded in access token
+type CustomClaims struct {
+	Roles             []string               `json:"roles,omitempty"`
+	Permissions       []string               `json:"perms,omitempty"`
+	PermissionVersion string                 `json:"pver,omitempty"`
+	Extra             map[string]interface{} `json:"ext,omitempty"`
+	ClientID          string                 `json:"client_id,omitempty"`
+	Scope             string                 `json:"scope,omitempty"`
+	Actor             *Actor                 `json:"act,omitempty"`
+}
+
+// AccessClaims claims of access token
+type AccessClaims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid,omitempty"`
+	CustomClaims
+}
+
+// HasRole the token carries the role guard name
+func (c *AccessClaims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission the token carries the permission guard name
+func (c *AccessClaims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ActorID id of the user acting on behalf of the subject, empty unless the token is issued by impersonation
+func (c *AccessClaims) ActorID() string {
+	if c.Actor == nil {
+		return ""
+	}
+	return c.Actor.Subject
+}
@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWKSet JSON Web Key Set (RFC 7517)
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK public JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func encodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// newJWK return JWK of public key
+func newJWK(key *SigningKey) (JWK, bool) {
+	jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+	switch public := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeBase64URL(public.N.Bytes())
+		jwk.E = encodeBase64URL(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = public.Curve.Params().Name
+		jwk.X = encodeBase64URL(public.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeBase64URL(public.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encodeBase64URL(public)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
@@ -2,14 +2,22 @@ package jwt
 
 import (
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/shasw94/projX/pkg/errors"
 	"time"
 )
 
 type IJWTAuth interface {
 	GenerateToken(userID string) (TokenInfo, error)
+	GenerateTokenWithClaims(userID string, custom CustomClaims) (TokenInfo, error)
+	GenerateAccessToken(subject string, custom CustomClaims) (TokenInfo, error)
+	GenerateAccessTokenWithExpired(subject string, custom CustomClaims, expired int) (TokenInfo, error)
 	RefreshToken(refreshToken string) (TokenInfo, error)
+	RefreshTokenWithClaims(refreshToken string, custom CustomClaims) (TokenInfo, error)
 	ParseUserID(accessToken string, refresh bool) (string, error)
+	ParseAccessToken(accessToken string) (*AccessClaims, error)
+	ParseRefreshToken(refreshToken string) (*RefreshClaims, error)
+	JWKS() JWKSet
 }
 
 const defaultKey = "gin-go"
@@ -32,8 +40,9 @@ var defaultOptions = options{
 		}
 		return []byte(defaultRefreshKey), nil
 	},
-	expiredRefresh:    24,
-	signingRefreshKey: []byte(defaultRefreshKey),
+	expiredRefresh:       24,
+	signingMethodRefresh: jwt.SigningMethodHS512,
+	signingRefreshKey:    []byte(defaultRefreshKey),
 }
 
 // NewJWTAuth returs new Auth pointer
@@ -52,14 +61,17 @@ type Auth struct {
 }
 
 type options struct {
-	signingMethod     jwt.SigningMethod
-	signingKey        interface{}
-	keyFunc           jwt.Keyfunc
-	expired           int
-	tokenType         string
-	keyFuncRefresh    jwt.Keyfunc
-	expiredRefresh    int
-	signingRefreshKey interface{}
+	signingMethod        jwt.SigningMethod
+	signingKey           interface{}
+	keyID                string
+	keySet               *KeySet
+	keyFunc              jwt.Keyfunc
+	expired              int
+	tokenType            string
+	keyFuncRefresh       jwt.Keyfunc
+	expiredRefresh       int
+	signingMethodRefresh jwt.SigningMethod
+	signingRefreshKey    interface{}
 }
 
 // Option jwt option
@@ -93,6 +105,18 @@ func WithSigningKey(key interface{}) Option {
 	}
 }
 
+// WithKeySet sign access token by the signing key of the set,
+// verify access token by the key of its kid header
+func WithKeySet(ks *KeySet) Option {
+	return func(o *options) {
+		o.keySet = ks
+		o.signingMethod = ks.SigningKey().Method
+		o.signingKey = ks.SigningKey().PrivateKey
+		o.keyID = ks.SigningKey().ID
+		o.keyFunc = ks.KeyFunc
+	}
+}
+
 // WithKeyFuncRefresh set key function for refresh token
 func WithKeyFuncRefresh(keyFunc jwt.Keyfunc) Option {
 	return func(o *options) {
@@ -107,14 +131,26 @@ func WithSigningKeyRefresh(key interface{}) Option {
 	}
 }
 
-// GenerateToken return new TokenInfo, generate new access and refresh token
+// GenerateToken return new TokenInfo, generate new access and refresh token.
+// The refresh token starts a new token family, the family id is also the session id of the access token
 func (a *Auth) GenerateToken(userID string) (TokenInfo, error) {
-	accessToken, err := a.generateAccess(userID)
-	if err != nil {
-		return nil, err
-	}
+	return a.GenerateTokenWithClaims(userID, CustomClaims{})
+}
 
-	refreshToken, err := a.generateRefresh(userID)
+// GenerateTokenWithClaims same as GenerateToken, the access token carries the custom claims
+func (a *Auth) GenerateTokenWithClaims(userID string, custom CustomClaims) (TokenInfo, error) {
+	return a.generateTokenInfo(userID, uuid.New().String(), custom)
+}
+
+// GenerateAccessToken return new TokenInfo with access token only,
+// the token neither belongs to a session nor can be refreshed
+func (a *Auth) GenerateAccessToken(subject string, custom CustomClaims) (TokenInfo, error) {
+	return a.GenerateAccessTokenWithExpired(subject, custom, a.opts.expired)
+}
+
+// GenerateAccessTokenWithExpired same as GenerateAccessToken, the token expires after expired seconds
+func (a *Auth) GenerateAccessTokenWithExpired(subject string, custom CustomClaims, expired int) (TokenInfo, error) {
+	accessToken, claims, err := a.generateAccess(subject, "", custom, expired)
 	if err != nil {
 		return nil, err
 	}
@@ -122,36 +158,67 @@ func (a *Auth) GenerateToken(userID string) (TokenInfo, error) {
 	tokenInfo := &tokenInfo{
 		TokenType:    a.opts.tokenType,
 		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		accessClaims: claims,
 	}
 	return tokenInfo, nil
 }
 
-// parseToken parse claims from token
-func (a *Auth) parseToken(tokenString string, refresh bool) (*jwt.RegisteredClaims, error) {
-	option := a.opts.keyFunc
-	if refresh == true {
-		option = a.opts.keyFuncRefresh
+// generateTokenInfo generate access token and refresh token belongs to the family
+func (a *Auth) generateTokenInfo(userID, familyID string, custom CustomClaims) (TokenInfo, error) {
+	accessToken, accessClaims, err := a.generateAccess(userID, familyID, custom, a.opts.expired)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, claims, err := a.generateRefresh(userID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo := &tokenInfo{
+		TokenType:     a.opts.tokenType,
+		AccessToken:   accessToken,
+		RefreshToken:  refreshToken,
+		accessClaims:  accessClaims,
+		refreshClaims: claims,
 	}
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, option)
+	return tokenInfo, nil
+}
 
+// parseClaims parse token into claims
+func (a *Auth) parseClaims(tokenString string, claims jwt.Claims, keyFunc jwt.Keyfunc) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
 	if err != nil {
 		if ve, ok := err.(*jwt.ValidationError); ok {
 			if ve.Errors&jwt.ValidationErrorMalformed != 0 {
-				return nil, errors.ErrTokenMalformed
+				return errors.ErrTokenMalformed
 			} else if ve.Errors&jwt.ValidationErrorExpired != 0 {
-				return nil, errors.ErrTokenExpired
-			} else if ve.Errors&jwt.ValidationErrorNotValidYet != 0 {
-				return nil, errors.ErrTokenInvalid
-			} else {
-				return nil, errors.ErrTokenInvalid
+				return errors.ErrTokenExpired
 			}
 		}
+		return errors.ErrTokenInvalid
 	} else if !token.Valid {
-		return nil, errors.ErrTokenInvalid
+		return errors.ErrTokenInvalid
+	}
+
+	return nil
+}
+
+// parseToken parse claims from token
+func (a *Auth) parseToken(tokenString string, refresh bool) (*jwt.RegisteredClaims, error) {
+	if refresh {
+		claims, err := a.ParseRefreshToken(tokenString)
+		if err != nil {
+			return nil, err
+		}
+		return &claims.RegisteredClaims, nil
 	}
 
-	return token.Claims.(*jwt.RegisteredClaims), nil
+	claims, err := a.ParseAccessToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return &claims.RegisteredClaims, nil
 }
 
 // ParseUserID parse user_id from token
@@ -163,59 +230,95 @@ func (a *Auth) ParseUserID(tokenString string, refresh bool) (string, error) {
 	return claims.Subject, nil
 }
 
-// RefreshToken refresh token, return new TokenInfo
-func (a *Auth) RefreshToken(refreshToken string) (TokenInfo, error) {
-	userID, err := a.ParseUserID(refreshToken, true)
-	if err != nil {
-		if err == errors.ErrTokenExpired {
-			return a.GenerateToken(userID)
-		}
+// ParseAccessToken parse claims from access token
+func (a *Auth) ParseAccessToken(accessToken string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+	if err := a.parseClaims(accessToken, claims, a.opts.keyFunc); err != nil {
 		return nil, err
 	}
+	return claims, nil
+}
 
-	accessToken, err := a.generateAccess(userID)
+// ParseRefreshToken parse claims from refresh token
+func (a *Auth) ParseRefreshToken(refreshToken string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+	if err := a.parseClaims(refreshToken, claims, a.opts.keyFuncRefresh); err != nil {
+		return nil, err
+	}
+	if claims.ID == "" || claims.FamilyID == "" {
+		return nil, errors.ErrTokenInvalid
+	}
+	return claims, nil
+}
+
+// RefreshToken rotate refresh token, return new TokenInfo.
+// The new refresh token stays in the family of the given one
+func (a *Auth) RefreshToken(refreshToken string) (TokenInfo, error) {
+	return a.RefreshTokenWithClaims(refreshToken, CustomClaims{})
+}
+
+// RefreshTokenWithClaims same as RefreshToken, the access token carries the custom claims
+func (a *Auth) RefreshTokenWithClaims(refreshToken string, custom CustomClaims) (TokenInfo, error) {
+	claims, err := a.ParseRefreshToken(refreshToken)
 	if err != nil {
 		return nil, err
 	}
 
-	tokenInfo := &tokenInfo{
-		TokenType:    a.opts.tokenType,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+	return a.generateTokenInfo(claims.Subject, claims.FamilyID, custom)
+}
+
+// JWKS return public keys to verify access token, empty if tokens are signed by HMAC
+func (a *Auth) JWKS() JWKSet {
+	if a.opts.keySet == nil {
+		return JWKSet{Keys: []JWK{}}
 	}
-	return tokenInfo, nil
+	return a.opts.keySet.JWKS()
 }
 
 // generateAccess generate access token
-func (a *Auth) generateAccess(userID string) (string, error) {
+func (a *Auth) generateAccess(userID, sessionID string, custom CustomClaims, expired int) (string, *AccessClaims, error) {
 	now := time.Now()
-	token := jwt.NewWithClaims(a.opts.signingMethod, jwt.RegisteredClaims{
-		IssuedAt:  jwt.NewNumericDate(now),
-		ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(a.opts.expired) * time.Second)),
-		NotBefore: jwt.NewNumericDate(now),
-		Subject:   userID,
-	})
+	claims := &AccessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(expired) * time.Second)),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   userID,
+		},
+		SessionID:    sessionID,
+		CustomClaims: custom,
+	}
+	token := jwt.NewWithClaims(a.opts.signingMethod, claims)
+	if a.opts.keyID != "" {
+		token.Header["kid"] = a.opts.keyID
+	}
 	tokenString, err := token.SignedString(a.opts.signingKey)
 	if err != nil {
-		return "", errors.New("generate token fail")
+		return "", nil, errors.New("generate token fail")
 	}
 
-	return tokenString, nil
+	return tokenString, claims, nil
 }
 
 // generateRefresh generate refresh token
-func (a *Auth) generateRefresh(userID string) (string, error) {
+func (a *Auth) generateRefresh(userID, familyID string) (string, *RefreshClaims, error) {
 	now := time.Now()
-	token := jwt.NewWithClaims(a.opts.signingMethod, jwt.RegisteredClaims{
-		IssuedAt:  jwt.NewNumericDate(now),
-		ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(a.opts.expiredRefresh) * time.Hour)),
-		NotBefore: jwt.NewNumericDate(now),
-		Subject:   userID,
-	})
+	claims := &RefreshClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(a.opts.expiredRefresh) * time.Hour)),
+			NotBefore: jwt.NewNumericDate(now),
+			Subject:   userID,
+		},
+		FamilyID: familyID,
+	}
+	token := jwt.NewWithClaims(a.opts.signingMethodRefresh, claims)
 	tokenString, err := token.SignedString(a.opts.signingRefreshKey)
 	if err != nil {
-		return "", errors.New("generate token fail")
+		return "", nil, errors.New("generate token fail")
 	}
 
-	return tokenString, nil
+	return tokenString, claims, nil
 }
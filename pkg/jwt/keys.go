@@ -0,0 +1,170 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/shasw94/projX/pkg/errors"
+	"os"
+	"time"
+)
+
+// SigningKey asymmetric key identified by kid.
+// A key without private key can only verify tokens
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+	RetireAt   time.Time
+}
+
+// IsRetired the key is no longer valid for verification
+func (k *SigningKey) IsRetired(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+// LoadPEMKey load key from PEM files.
+// Public key is derived from private key if publicFile is empty
+func LoadPEMKey(id, alg, privateFile, publicFile string, retireAt time.Time) (*SigningKey, error) {
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return nil, errors.Newf("jwt key %s: unsupported algorithm %s", id, alg)
+	}
+	key := &SigningKey{ID: id, Method: method, RetireAt: retireAt}
+
+	if privateFile != "" {
+		data, err := os.ReadFile(privateFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "jwt key %s", id)
+		}
+		if err := key.parsePrivateKey(data); err != nil {
+			return nil, errors.Wrapf(err, "jwt key %s", id)
+		}
+	}
+
+	if publicFile != "" {
+		data, err := os.ReadFile(publicFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "jwt key %s", id)
+		}
+		if err := key.parsePublicKey(data); err != nil {
+			return nil, errors.Wrapf(err, "jwt key %s", id)
+		}
+	}
+
+	if key.PublicKey == nil {
+		return nil, errors.Newf("jwt key %s: no key file configured", id)
+	}
+	return key, nil
+}
+
+func (k *SigningKey) parsePrivateKey(data []byte) error {
+	switch k.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		k.PrivateKey, k.PublicKey = private, &private.PublicKey
+	case *jwt.SigningMethodECDSA:
+		private, err := jwt.ParseECPrivateKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		k.PrivateKey, k.PublicKey = private, &private.PublicKey
+	case *jwt.SigningMethodEd25519:
+		private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return err
+		}
+		k.PrivateKey, k.PublicKey = private, private.(ed25519.PrivateKey).Public()
+	default:
+		return errors.Newf("algorithm %s is not asymmetric", k.Method.Alg())
+	}
+	return nil
+}
+
+func (k *SigningKey) parsePublicKey(data []byte) (err error) {
+	switch k.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		k.PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(data)
+	case *jwt.SigningMethodECDSA:
+		k.PublicKey, err = jwt.ParseECPublicKeyFromPEM(data)
+	case *jwt.SigningMethodEd25519:
+		k.PublicKey, err = jwt.ParseEdPublicKeyFromPEM(data)
+	default:
+		err = errors.Newf("algorithm %s is not asymmetric", k.Method.Alg())
+	}
+	return
+}
+
+// KeySet set of asymmetric keys, tokens are verified by the key of their kid header
+type KeySet struct {
+	keys       []*SigningKey
+	signingKey *SigningKey
+}
+
+// NewKeySet return new KeySet.
+// The key signingKID signs new tokens, if empty the first usable key is chosen
+func NewKeySet(keys []*SigningKey, signingKID string) (*KeySet, error) {
+	ks := &KeySet{keys: keys}
+	now := time.Now()
+	for _, key := range keys {
+		if key.PrivateKey == nil || key.IsRetired(now) {
+			continue
+		}
+		if signingKID == "" || key.ID == signingKID {
+			ks.signingKey = key
+			break
+		}
+	}
+
+	if ks.signingKey == nil {
+		return nil, errors.New("jwt: no usable signing key")
+	}
+	return ks, nil
+}
+
+// SigningKey return key used to sign new tokens
+func (ks *KeySet) SigningKey() *SigningKey {
+	return ks.signingKey
+}
+
+// Get return key by kid
+func (ks *KeySet) Get(kid string) *SigningKey {
+	for _, key := range ks.keys {
+		if key.ID == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// KeyFunc verify token by the key of its kid header
+func (ks *KeySet) KeyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key := ks.Get(kid)
+	if key == nil || key.IsRetired(time.Now()) {
+		return nil, errors.ErrTokenInvalid
+	}
+	if t.Method.Alg() != key.Method.Alg() {
+		return nil, errors.ErrTokenInvalid
+	}
+	return key.PublicKey, nil
+}
+
+// JWKS return public keys which are not retired
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	now := time.Now()
+	for _, key := range ks.keys {
+		if key.IsRetired(now) {
+			continue
+		}
+		if jwk, ok := newJWK(key); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
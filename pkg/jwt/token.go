@@ -6,13 +6,17 @@ type TokenInfo interface {
 	GetAccessToken() string
 	GetRefreshToken() string
 	GetTokenType() string
+	GetAccessClaims() *AccessClaims
+	GetRefreshClaims() *RefreshClaims
 	EncodeToJSON() ([]byte, error)
 }
 
 type tokenInfo struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	TokenType    string `json:"token_type"`
+	AccessToken   string `json:"access_token"`
+	RefreshToken  string `json:"refresh_token"`
+	TokenType     string `json:"token_type"`
+	accessClaims  *AccessClaims
+	refreshClaims *RefreshClaims
 }
 
 // GetAccessToken return access token
@@ -30,6 +34,16 @@ func (t *tokenInfo) GetTokenType() string {
 	return t.TokenType
 }
 
+// GetAccessClaims return claims of access token
+func (t *tokenInfo) GetAccessClaims() *AccessClaims {
+	return t.accessClaims
+}
+
+// GetRefreshClaims return claims of refresh token
+func (t *tokenInfo) GetRefreshClaims() *RefreshClaims {
+	return t.refreshClaims
+}
+
 func (t *tokenInfo) EncodeToJSON() ([]byte, error) {
 	return json.Marshal(t)
 }
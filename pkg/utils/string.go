@@ -3,19 +3,77 @@ package utils
 import (
 	"github.com/gosimple/slug"
 	"reflect"
+	"strings"
 )
 
+const (
+	// GuardSeparator separator of guard name namespaces
+	GuardSeparator = "."
+	// GuardWildcard guard name segment matching one or more segments
+	GuardWildcard = "*"
+)
+
+// Guard guard name of role or permission name. Dot separated segments are slugged one by one
+// and * segments are kept as wildcards. example: Users.Read Profile -> users.read-profile
 func Guard(b string) string {
-	return slug.Make(b)
+	segments := strings.Split(b, GuardSeparator)
+	guards := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if strings.TrimSpace(segment) == GuardWildcard {
+			guards = append(guards, GuardWildcard)
+			continue
+		}
+		if guard := slug.Make(segment); guard != "" {
+			guards = append(guards, guard)
+		}
+	}
+	return strings.Join(guards, GuardSeparator)
 }
 
 func GuardArray(b []string) (guardArray []string) {
 	for _, c := range b {
-		guardArray = append(guardArray, slug.Make(c))
+		guardArray = append(guardArray, Guard(c))
 	}
 	return
 }
 
+// MatchGuard the granted guard name covers the guard name, * segments of the grant match one or more segments.
+// example: users.* covers users.read and users.profile.read, *.read covers users.read
+func MatchGuard(granted, guard string) bool {
+	if granted == guard {
+		return true
+	}
+	if !strings.Contains(granted, GuardWildcard) {
+		return false
+	}
+	return matchGuardSegments(strings.Split(granted, GuardSeparator), strings.Split(guard, GuardSeparator))
+}
+
+// MatchAnyGuard one of the granted guard names covers the guard name
+func MatchAnyGuard(granted []string, guard string) bool {
+	for _, g := range granted {
+		if MatchGuard(g, guard) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGuardSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] != GuardWildcard {
+		return len(segments) > 0 && pattern[0] == segments[0] && matchGuardSegments(pattern[1:], segments[1:])
+	}
+	for i := 1; i <= len(segments); i++ {
+		if matchGuardSegments(pattern[1:], segments[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
 func IsNumber(value interface{}) bool {
 	if reflect.TypeOf(value).Kind() == reflect.Int || reflect.TypeOf(value).Kind() == reflect.Uint {
 		return true
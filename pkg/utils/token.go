@@ -1,17 +1,43 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"github.com/shasw94/projX/logger"
 	"github.com/shasw94/projX/pkg/errors"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/shasw94/projX/pkg/hasher"
 )
 
+// HashPassword hash password by the default hasher
 func HashPassword(pass []byte) (string, error) {
-	hashed, err := bcrypt.GenerateFromPassword(pass, bcrypt.MinCost)
+	hashed, err := hasher.Default().Hash(string(pass))
 	if err != nil {
 		logger.Error("Failed to genrate password: ", err)
 		return "", errors.Wrap(err, "utils.HashPassword")
 	}
 
-	return string(hashed), nil
+	return hashed, nil
+}
+
+// CheckPassword compare password with hashed password of any supported algorithm
+func CheckPassword(hashed string, pass []byte) bool {
+	ok, err := hasher.Default().Verify(hashed, string(pass))
+	return err == nil && ok
+}
+
+// HashToken return hex encoded sha256 of token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RandomToken return url safe random token of n bytes from crypto/rand
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "utils.RandomToken")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
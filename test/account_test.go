@@ -0,0 +1,126 @@
+package test
+
+import (
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/mailer"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/stretchr/testify/suite"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+var tokenLinkPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+type AccountTestSuite struct {
+	suite.Suite
+
+	mailer *mailer.MemoryMailer
+}
+
+func (s *AccountTestSuite) SetupSuite() {
+	err := container.Invoke(func(m interfaces.IMailer) {
+		s.mailer, _ = m.(*mailer.MemoryMailer)
+	})
+	s.Require().Nil(err)
+	s.Require().NotNil(s.mailer, "tests require the in-memory mailer")
+}
+
+// post send json request and return code of the response
+func (s *AccountTestSuite) post(path string, body interface{}) string {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest(path, body))
+
+	var res struct {
+		Code string `json:"code"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	return res.Code
+}
+
+// lastToken extract token of the link in the latest message sent to the address
+func (s *AccountTestSuite) lastToken(to string) string {
+	msg, ok := s.mailer.Last(to)
+	s.Require().True(ok, "no message sent to %s", to)
+
+	match := tokenLinkPattern.FindStringSubmatch(msg.Body)
+	s.Require().Len(match, 2)
+	return match[1]
+}
+
+func (s *AccountTestSuite) TestEmailVerification() {
+	email := "account-verify@tokoin.io"
+	code := s.post("/register", schema.RegisterBodyParams{
+		Username: "account-verify",
+		Email:    email,
+		Password: "account-pwd-1",
+	})
+	s.Require().Equal("SUCCESS", code)
+
+	token := s.lastToken(email)
+	s.Equal("SUCCESS", s.post("/email/verify", schema.VerifyEmailBodyParams{Token: token}))
+
+	// token is single-use
+	s.Equal("ERROR_TOKEN_INVALID", s.post("/email/verify", schema.VerifyEmailBodyParams{Token: token}))
+}
+
+func (s *AccountTestSuite) TestPasswordReset() {
+	email := "account-reset@tokoin.io"
+	code := s.post("/register", schema.RegisterBodyParams{
+		Username: "account-reset",
+		Email:    email,
+		Password: "account-pwd-1",
+	})
+	s.Require().Equal("SUCCESS", code)
+
+	s.mailer.Reset()
+	s.Equal("SUCCESS", s.post("/password/forgot", schema.ForgotPasswordBodyParams{Email: email}))
+	s.Len(s.mailer.Messages(), 1)
+	token := s.lastToken(email)
+
+	s.Equal("ERROR_PASSWORD_REQUIRED", s.post("/password/reset", schema.ResetPasswordBodyParams{Token: token}))
+	// rejected by the password policy, the token stays valid
+	s.Equal("INVALID_PARAMS", s.post("/password/reset", schema.ResetPasswordBodyParams{Token: token, Password: "account-reset-1"}))
+	s.Equal("SUCCESS", s.post("/password/reset", schema.ResetPasswordBodyParams{Token: token, Password: "account-pwd-2"}))
+	s.Equal("ERROR_TOKEN_INVALID", s.post("/password/reset", schema.ResetPasswordBodyParams{Token: token, Password: "account-pwd-3"}))
+
+	s.Equal("SUCCESS", s.post("/login", schema.LoginBodyParams{Username: "account-reset", Password: "account-pwd-2"}))
+}
+
+func (s *AccountTestSuite) TestRegisterRequiresVerifiedEmail() {
+	config.Config.Account.RequireVerifiedEmail = true
+	defer func() { config.Config.Account.RequireVerifiedEmail = false }()
+
+	email := "account-unverified@tokoin.io"
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest("/register", schema.RegisterBodyParams{
+		Username: "account-unverified",
+		Email:    email,
+		Password: "account-pwd-1",
+	}))
+	var res struct {
+		Code string               `json:"code"`
+		Data schema.UserTokenInfo `json:"data"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	s.Require().Equal("SUCCESS", res.Code)
+	s.True(res.Data.EmailVerificationRequired)
+	s.Empty(res.Data.AccessToken)
+	s.Empty(res.Data.RefreshToken)
+
+	login := schema.LoginBodyParams{Username: "account-unverified", Password: "account-pwd-1"}
+	s.Equal("ERROR_EMAIL_NOT_VERIFIED", s.post("/login", login))
+	s.Equal("SUCCESS", s.post("/email/verify", schema.VerifyEmailBodyParams{Token: s.lastToken(email)}))
+	s.Equal("SUCCESS", s.post("/login", login))
+}
+
+func (s *AccountTestSuite) TestForgotPasswordUnknownEmail() {
+	s.mailer.Reset()
+	s.Equal("SUCCESS", s.post("/password/forgot", schema.ForgotPasswordBodyParams{Email: "nobody@tokoin.io"}))
+	s.Empty(s.mailer.Messages())
+}
+
+func TestAccountTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountTestSuite))
+}
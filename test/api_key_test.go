@@ -0,0 +1,165 @@
+package test
+
+import (
+	"fmt"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type APIKeyTestSuite struct {
+	suite.Suite
+
+	accountID string
+}
+
+func (s *APIKeyTestSuite) SetupSuite() {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostAuthRequest("/admin/service-accounts", schema.ServiceAccountBodyParams{
+		Username: "ci-" + time.Now().Format("20060102150405.000"),
+	}))
+
+	var res struct {
+		Code string                `json:"code"`
+		Data schema.ServiceAccount `json:"data"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	s.Require().Equal("SUCCESS", res.Code)
+	s.accountID = res.Data.ID
+
+	err := container.Invoke(func(permRepo interfaces.IPermissionRepository, passport interfaces.IPassport) error {
+		err := permRepo.FirstOrCreate(&models.Permission{
+			Name:        models.PermissionUsersRead,
+			GuardName:   models.PermissionUsersRead,
+			Description: "read users",
+		})
+		if err != nil {
+			return err
+		}
+		return passport.AddPermissionsToUser(s.accountID, models.PermissionUsersRead, nil)
+	})
+	s.Require().Nil(err)
+}
+
+// createKey mint api key for the service account by admin api
+func (s *APIKeyTestSuite) createKey(params schema.APIKeyBodyParams) (string, schema.APIKeyCredentials) {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostAuthRequest("/admin/service-accounts/%s/api-keys", params, s.accountID))
+
+	var res struct {
+		Code string                   `json:"code"`
+		Data schema.APIKeyCredentials `json:"data"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	return res.Code, res.Data
+}
+
+// call request /api/v1/users with the header and return code of the response
+func (s *APIKeyTestSuite) call(header, value string) string {
+	req, _ := http.NewRequest("GET", "/api/v1/users", nil)
+	req.Header.Set(header, value)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var res struct {
+		Code string `json:"code"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	return res.Code
+}
+
+func (s *APIKeyTestSuite) TestAuthenticate() {
+	code, key := s.createKey(schema.APIKeyBodyParams{Name: "nightly-sync", Permissions: []string{models.PermissionUsersRead}})
+	s.Require().Equal("SUCCESS", code)
+	s.NotEmpty(key.Key)
+	s.Contains(key.Key, key.Prefix)
+
+	s.Equal("SUCCESS", s.call("X-API-Key", key.Key))
+	s.Equal("SUCCESS", s.call("Authorization", "ApiKey "+key.Key))
+	s.Equal("ERROR_API_KEY_INVALID", s.call("X-API-Key", key.Key+"x"))
+	s.Equal("ERROR_API_KEY_INVALID", s.call("X-API-Key", "not-an-api-key"))
+
+	// the key is limited to its own permissions
+	code, key = s.createKey(schema.APIKeyBodyParams{Name: "no-permissions"})
+	s.Require().Equal("SUCCESS", code)
+	s.Equal("ERROR_NO_PERMISSION", s.call("X-API-Key", key.Key))
+}
+
+func (s *APIKeyTestSuite) TestRevoke() {
+	code, key := s.createKey(schema.APIKeyBodyParams{Name: "ci", Permissions: []string{models.PermissionUsersRead}})
+	s.Require().Equal("SUCCESS", code)
+	s.Equal("SUCCESS", s.call("X-API-Key", key.Key))
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newDeleteRequest("/admin/service-accounts/%s/api-keys/%s", s.accountID, key.ID))
+	s.Equal(http.StatusOK, w.Code)
+
+	s.Equal("ERROR_API_KEY_INVALID", s.call("X-API-Key", key.Key))
+}
+
+func (s *APIKeyTestSuite) TestExpired() {
+	expiresAt := time.Now().Add(-time.Minute)
+	code, _ := s.createKey(schema.APIKeyBodyParams{Name: "expired", ExpiresAt: &expiresAt})
+	s.Equal("INVALID_PARAMS", code)
+}
+
+func (s *APIKeyTestSuite) TestPermissionNotHeld() {
+	code, _ := s.createKey(schema.APIKeyBodyParams{Name: "too-wide", Permissions: []string{"api-key.test.not-held"}})
+	s.Equal("INVALID_PARAMS", code)
+}
+
+func (s *APIKeyTestSuite) TestInheritedPermission() {
+	suffix := time.Now().Format("20060102150405.000")
+	inherited := "api-key.test.inherited." + suffix
+	err := container.Invoke(func(passport interfaces.IPassport) error {
+		if _, err := passport.CreatePermission(inherited, "inherited by the service account"); err != nil {
+			return err
+		}
+		junior, err := passport.CreateRole("api key junior "+suffix, "")
+		if err != nil {
+			return err
+		}
+		senior, err := passport.CreateRole("api key senior "+suffix, "")
+		if err != nil {
+			return err
+		}
+		if err := passport.AddPermissionsToRole(junior.ID, inherited); err != nil {
+			return err
+		}
+		if err := passport.AddParentsToRole(senior.ID, junior.ID); err != nil {
+			return err
+		}
+		return passport.AddRolesToUser(s.accountID, senior.ID, nil)
+	})
+	s.Require().Nil(err)
+
+	// permissions inherited through the role hierarchy are held by the service account
+	code, _ := s.createKey(schema.APIKeyBodyParams{Name: "inherited", Permissions: []string{inherited}})
+	s.Equal("SUCCESS", code)
+}
+
+func (s *APIKeyTestSuite) TestWildcardPermission() {
+	namespace := fmt.Sprintf("apikeywildcard%d", time.Now().UnixNano())
+	err := container.Invoke(func(passport interfaces.IPassport) error {
+		if _, err := passport.CreatePermission(namespace+".*", namespace); err != nil {
+			return err
+		}
+		return passport.AddPermissionsToUser(s.accountID, namespace+".*", nil)
+	})
+	s.Require().Nil(err)
+
+	// the wildcard held by the service account covers the permissions it matches
+	code, _ := s.createKey(schema.APIKeyBodyParams{Name: "wildcard", Permissions: []string{namespace + ".export"}})
+	s.Equal("SUCCESS", code)
+	code, _ = s.createKey(schema.APIKeyBodyParams{Name: "other-namespace", Permissions: []string{"other" + namespace + ".export"}})
+	s.Equal("INVALID_PARAMS", code)
+}
+
+func TestAPIKeyTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyTestSuite))
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"github.com/casbin/casbin/v2"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/policy"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type CasbinTestSuite struct {
+	suite.Suite
+
+	passport interfaces.IPassport
+	enforcer *casbin.SyncedEnforcer
+	syncer   interfaces.IPolicySyncer
+}
+
+func (s *CasbinTestSuite) SetupSuite() {
+	err := container.Invoke(func(db interfaces.IDatabase, passport interfaces.IPassport) error {
+		m, err := policy.LoadModel("")
+		if err != nil {
+			return err
+		}
+		adapter, err := policy.NewAdapter(db)
+		if err != nil {
+			return err
+		}
+		s.enforcer, err = casbin.NewSyncedEnforcer(m, adapter)
+		if err != nil {
+			return err
+		}
+		s.syncer = policy.NewSyncer(db, s.enforcer)
+		s.passport = passport
+		return nil
+	})
+	s.Require().Nil(err)
+}
+
+func (s *CasbinTestSuite) TestSyncFromPassport() {
+	suffix := time.Now().Format("20060102150405.000")
+	permission, err := s.passport.CreatePermission("casbin users read "+suffix, "")
+	s.Require().Nil(err)
+	_, err = s.passport.SetPermissionResource(permission.ID, "/api/v1/users/:id", "GET")
+	s.Require().Nil(err)
+	role, err := s.passport.CreateRole("casbin reader "+suffix, "")
+	s.Require().Nil(err)
+	s.Require().Nil(s.passport.AddPermissionsToRole(role.ID, permission.ID))
+	s.Require().Nil(s.passport.AddRolesToUser(users[2].ID, role.ID, nil))
+	s.Require().Nil(s.syncer.Sync())
+
+	allowed, err := s.enforcer.Enforce(users[2].ID, "/api/v1/users/"+users[1].ID, "GET")
+	s.Nil(err)
+	s.True(allowed)
+	allowed, _ = s.enforcer.Enforce(users[2].ID, "/api/v1/users/"+users[1].ID, "DELETE")
+	s.False(allowed)
+	allowed, _ = s.enforcer.Enforce(user.ID, "/admin/roles", "POST")
+	s.True(allowed)
+
+	// policies are persisted by the adapter
+	s.Require().Nil(s.enforcer.LoadPolicy())
+	allowed, _ = s.enforcer.Enforce(users[2].ID, "/api/v1/users/"+users[1].ID, "GET")
+	s.True(allowed)
+
+	s.Require().Nil(s.passport.RemoveRolesFromUser(users[2].ID, role.ID))
+	s.Require().Nil(s.syncer.Sync())
+	allowed, _ = s.enforcer.Enforce(users[2].ID, "/api/v1/users/"+users[1].ID, "GET")
+	s.False(allowed)
+
+	s.Nil(s.passport.DeleteRole(role.ID))
+	s.Nil(s.passport.DeletePermission(permission.ID))
+}
+
+func TestCasbinTestSuite(t *testing.T) {
+	suite.Run(t, new(CasbinTestSuite))
+}
@@ -0,0 +1,137 @@
+package test
+
+import (
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/shasw94/projX/pkg/utils"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+const claimsPermission = "claims.test.read"
+
+type ClaimsTestSuite struct {
+	suite.Suite
+
+	jwt      jwt.IJWTAuth
+	passport interfaces.IPassport
+}
+
+func (s *ClaimsTestSuite) SetupSuite() {
+	err := container.Invoke(func(
+		permRepo interfaces.IPermissionRepository,
+		passport interfaces.IPassport,
+		jwtauth jwt.IJWTAuth,
+	) error {
+		s.jwt = jwtauth
+		s.passport = passport
+		return permRepo.FirstOrCreate(&models.Permission{
+			Name:        claimsPermission,
+			GuardName:   claimsPermission,
+			Description: claimsPermission,
+		})
+	})
+	s.Require().Nil(err)
+}
+
+// login sign in as the second test user and return the issued tokens
+func (s *ClaimsTestSuite) login() schema.UserTokenInfo {
+	var tokenInfo schema.UserTokenInfo
+	code := serveJSON("POST", "/login", "", schema.LoginBodyParams{
+		Username: users[1].Username,
+		Password: "test-user-pwd-2",
+	}, &tokenInfo)
+	s.Require().Equal("SUCCESS", code)
+	return tokenInfo
+}
+
+func (s *ClaimsTestSuite) parse(accessToken string) *jwt.AccessClaims {
+	claims, err := s.jwt.ParseAccessToken(accessToken)
+	s.Require().Nil(err)
+	return claims
+}
+
+// rawClaims decode claims of the token as they are serialized
+func (s *ClaimsTestSuite) rawClaims(accessToken string) gojwt.MapClaims {
+	raw := gojwt.MapClaims{}
+	_, _, err := gojwt.NewParser().ParseUnverified(accessToken, raw)
+	s.Require().Nil(err)
+	return raw
+}
+
+func (s *ClaimsTestSuite) TestRoundTrip() {
+	custom := jwt.CustomClaims{
+		Roles:             []string{"editor"},
+		Permissions:       []string{"posts.read", "posts.write"},
+		PermissionVersion: "v1",
+		Extra:             map[string]interface{}{"tenant": "acme"},
+	}
+	tokenInfo, err := s.jwt.GenerateTokenWithClaims(user.ID, custom)
+	s.Require().Nil(err)
+
+	claims := s.parse(tokenInfo.GetAccessToken())
+	s.Equal(user.ID, claims.Subject)
+	s.Equal(custom.Roles, claims.Roles)
+	s.Equal(custom.Permissions, claims.Permissions)
+	s.Equal("v1", claims.PermissionVersion)
+	s.Equal("acme", claims.Extra["tenant"])
+	s.True(claims.HasRole("editor"))
+	s.False(claims.HasRole("admin"))
+	s.True(claims.HasPermission("posts.write"))
+	s.False(claims.HasPermission("posts.delete"))
+
+	// claims use their short names in the token
+	raw := s.rawClaims(tokenInfo.GetAccessToken())
+	s.Contains(raw, "roles")
+	s.Contains(raw, "perms")
+	s.Equal("v1", raw["pver"])
+	s.Equal(claims.SessionID, raw["sid"])
+}
+
+func (s *ClaimsTestSuite) TestEmptyClaimsOmitted() {
+	tokenInfo, err := s.jwt.GenerateToken(user.ID)
+	s.Require().Nil(err)
+
+	raw := s.rawClaims(tokenInfo.GetAccessToken())
+	s.NotContains(raw, "roles")
+	s.NotContains(raw, "perms")
+	s.NotContains(raw, "pver")
+	s.NotContains(raw, "ext")
+	s.NotContains(raw, "act")
+}
+
+func (s *ClaimsTestSuite) TestLoginClaims() {
+	tokenInfo := s.login()
+
+	claims := s.parse(tokenInfo.AccessToken)
+	s.Equal(users[1].ID, claims.Subject)
+	s.Equal(tokenInfo.SessionID, claims.SessionID)
+	s.True(claims.HasRole(utils.Guard(roles[0].Name)))
+	s.NotEmpty(claims.PermissionVersion)
+	s.Equal(users[1].Username, claims.Extra["username"])
+}
+
+func (s *ClaimsTestSuite) TestRefreshUpdatesClaims() {
+	tokenInfo := s.login()
+	before := s.parse(tokenInfo.AccessToken)
+	s.False(before.HasPermission(claimsPermission))
+
+	s.Require().Nil(s.passport.AddPermissionsToUser(users[1].ID, claimsPermission, nil))
+	defer func() {
+		s.Nil(s.passport.RemovePermissionsFromUser(users[1].ID, claimsPermission))
+	}()
+
+	// the refreshed access token carries the current permissions of the user
+	code := serveJSON("POST", "/refresh", "", schema.RefreshBodyParams{RefreshToken: tokenInfo.RefreshToken}, &tokenInfo)
+	s.Require().Equal("SUCCESS", code)
+	after := s.parse(tokenInfo.AccessToken)
+	s.True(after.HasPermission(claimsPermission))
+	s.NotEqual(before.PermissionVersion, after.PermissionVersion)
+}
+
+func TestClaimsTestSuite(t *testing.T) {
+	suite.Run(t, new(ClaimsTestSuite))
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"github.com/shasw94/projX/pkg/hasher"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+type HasherTestSuite struct {
+	suite.Suite
+}
+
+// lightArgon2id keep tests fast
+var lightArgon2id = hasher.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func (s *HasherTestSuite) TestArgon2id() {
+	algorithm := hasher.NewArgon2id(lightArgon2id)
+	encoded, err := algorithm.Hash("s3cret-password")
+	s.Require().Nil(err)
+	s.True(strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	s.True(algorithm.Match(encoded))
+	s.False(algorithm.NeedsRehash(encoded))
+
+	ok, err := algorithm.Verify(encoded, "s3cret-password")
+	s.Nil(err)
+	s.True(ok)
+	ok, err = algorithm.Verify(encoded, "wrong-password")
+	s.Nil(err)
+	s.False(ok)
+
+	stronger := hasher.NewArgon2id(hasher.Argon2idParams{Memory: 2048, Iterations: 1, Parallelism: 1})
+	s.True(stronger.NeedsRehash(encoded))
+	ok, err = stronger.Verify(encoded, "s3cret-password")
+	s.Nil(err)
+	s.True(ok)
+}
+
+func (s *HasherTestSuite) TestBcrypt() {
+	algorithm := hasher.NewBcrypt(4)
+	encoded, err := algorithm.Hash("s3cret-password")
+	s.Require().Nil(err)
+	s.True(algorithm.Match(encoded))
+	s.False(algorithm.NeedsRehash(encoded))
+	s.True(hasher.NewBcrypt(5).NeedsRehash(encoded))
+
+	ok, err := algorithm.Verify(encoded, "s3cret-password")
+	s.Nil(err)
+	s.True(ok)
+	ok, err = algorithm.Verify(encoded, "wrong-password")
+	s.Nil(err)
+	s.False(ok)
+}
+
+func (s *HasherTestSuite) TestHasherMigratesAlgorithm() {
+	bcrypt := hasher.NewBcrypt(4)
+	h := hasher.New(hasher.NewArgon2id(lightArgon2id), bcrypt)
+
+	legacy, err := bcrypt.Hash("s3cret-password")
+	s.Require().Nil(err)
+	ok, err := h.Verify(legacy, "s3cret-password")
+	s.Nil(err)
+	s.True(ok)
+	s.True(h.NeedsRehash(legacy))
+
+	current, err := h.Hash("s3cret-password")
+	s.Require().Nil(err)
+	s.False(h.NeedsRehash(current))
+
+	s.True(h.IsHashed(legacy))
+	s.True(h.IsHashed(current))
+	s.False(h.IsHashed("s3cret-password"))
+	s.False(h.IsHashed("$argon2id$v=19$m=1024,t=1,p=1$not-base64!$"))
+
+	_, err = h.Verify("s3cret-password", "s3cret-password")
+	s.Equal(hasher.ErrUnknownAlgorithm, err)
+}
+
+func TestHasherTestSuite(t *testing.T) {
+	suite.Run(t, new(HasherTestSuite))
+}
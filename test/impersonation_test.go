@@ -0,0 +1,103 @@
+package test
+
+import (
+	"fmt"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/revocation"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ImpersonationTestSuite struct {
+	suite.Suite
+
+	jwt        jwt.IJWTAuth
+	adminToken string
+}
+
+func (s *ImpersonationTestSuite) SetupSuite() {
+	err := container.Invoke(func(jwtauth jwt.IJWTAuth) error {
+		s.jwt = jwtauth
+		tokenInfo, err := jwtauth.GenerateTokenWithClaims(user.ID, jwt.CustomClaims{Roles: []string{models.RoleAdmin}})
+		if err != nil {
+			return err
+		}
+		s.adminToken = tokenInfo.GetAccessToken()
+		return nil
+	})
+	s.Require().Nil(err)
+}
+
+// impersonate request impersonation of the user with the access token
+func (s *ImpersonationTestSuite) impersonate(accessToken, userID string) (string, schema.ImpersonationTokenInfo) {
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/admin/users/%s/impersonate", userID), nil)
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", AuthTokenType, accessToken))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var res struct {
+		Code string                        `json:"code"`
+		Data schema.ImpersonationTokenInfo `json:"data"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	return res.Code, res.Data
+}
+
+func (s *ImpersonationTestSuite) TestImpersonate() {
+	code, tokenInfo := s.impersonate(s.adminToken, users[1].ID)
+	s.Require().Equal("SUCCESS", code)
+	s.Equal(users[1].ID, tokenInfo.UserID)
+	s.Equal(user.ID, tokenInfo.ActorID)
+
+	claims, err := s.jwt.ParseAccessToken(tokenInfo.AccessToken)
+	s.Require().Nil(err)
+	s.Equal(users[1].ID, claims.Subject)
+	s.Equal(user.ID, claims.ActorID())
+	s.Empty(claims.SessionID)
+
+	// impersonation cannot be chained
+	code, _ = s.impersonate(tokenInfo.AccessToken, users[2].ID)
+	s.Equal("ERROR_NO_PERMISSION", code)
+}
+
+func (s *ImpersonationTestSuite) TestImpersonationIsDelegated() {
+	code, tokenInfo := s.impersonate(s.adminToken, users[1].ID)
+	s.Require().Equal("SUCCESS", code)
+
+	// the impersonating admin cannot manage the account of the user
+	s.Equal("ERROR_NO_PERMISSION", serveJSON("GET", "/api/v1/me/sessions", tokenInfo.AccessToken, nil, nil))
+	s.Equal("ERROR_NO_PERMISSION", serveJSON("DELETE", "/api/v1/me/sessions", tokenInfo.AccessToken, nil, nil))
+	s.Equal("ERROR_NO_PERMISSION", serveJSON("POST", "/api/v1/me/mfa/totp", tokenInfo.AccessToken, nil, nil))
+	s.Equal("ERROR_NO_PERMISSION", serveJSON("POST", "/oauth/authorize", tokenInfo.AccessToken, nil, nil))
+}
+
+func (s *ImpersonationTestSuite) TestImpersonationExpiryCapped() {
+	expired := config.Config.Impersonation.Expired
+	config.Config.Impersonation.Expired = int(revocation.TTL()/time.Second) + 3600
+	defer func() { config.Config.Impersonation.Expired = expired }()
+
+	// impersonation tokens never outlive the revocations of the user and the admin
+	code, tokenInfo := s.impersonate(s.adminToken, users[1].ID)
+	s.Require().Equal("SUCCESS", code)
+	s.Equal(int64(revocation.TTL()/time.Second), tokenInfo.ExpiresIn)
+}
+
+func (s *ImpersonationTestSuite) TestImpersonateRequiresAdmin() {
+	code, _ := s.impersonate(token, users[1].ID)
+	s.Equal("ERROR_NO_PERMISSION", code)
+}
+
+func (s *ImpersonationTestSuite) TestImpersonateUnknownUser() {
+	code, _ := s.impersonate(s.adminToken, "not-found-id")
+	s.Equal("ERROR_NOT_EXIST_USER", code)
+}
+
+func TestImpersonationTestSuite(t *testing.T) {
+	suite.Run(t, new(ImpersonationTestSuite))
+}
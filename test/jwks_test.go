@@ -0,0 +1,209 @@
+package test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shasw94/projX/app/api"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type JWKSTestSuite struct {
+	suite.Suite
+
+	rsaPrivateFile string
+	rsaPublicFile  string
+	ecPrivateFile  string
+}
+
+func (s *JWKSTestSuite) SetupSuite() {
+	dir := s.T().TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	s.Require().Nil(err)
+	rsaPublic, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	s.Require().Nil(err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().Nil(err)
+	ecPrivate, err := x509.MarshalECPrivateKey(ecKey)
+	s.Require().Nil(err)
+
+	s.rsaPrivateFile = s.writePEM(dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+	s.rsaPublicFile = s.writePEM(dir, "rsa.pub.pem", "PUBLIC KEY", rsaPublic)
+	s.ecPrivateFile = s.writePEM(dir, "ec.pem", "EC PRIVATE KEY", ecPrivate)
+}
+
+// writePEM write PEM block into the directory and return path of the file
+func (s *JWKSTestSuite) writePEM(dir, name, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	s.Require().Nil(err)
+	return path
+}
+
+// load load key from PEM files, the key is retired at retireAt unless it is zero
+func (s *JWKSTestSuite) load(id, alg, privateFile, publicFile string, retireAt time.Time) *jwt.SigningKey {
+	key, err := jwt.LoadPEMKey(id, alg, privateFile, publicFile, retireAt)
+	s.Require().Nil(err)
+	return key
+}
+
+// keySet return key set signing by the key of signingKID
+func (s *JWKSTestSuite) keySet(signingKID string, keys ...*jwt.SigningKey) *jwt.KeySet {
+	ks, err := jwt.NewKeySet(keys, signingKID)
+	s.Require().Nil(err)
+	return ks
+}
+
+// kidOf return kid header of the token
+func (s *JWKSTestSuite) kidOf(token string) string {
+	parsed, _, err := gojwt.NewParser().ParseUnverified(token, &gojwt.RegisteredClaims{})
+	s.Require().Nil(err)
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func (s *JWKSTestSuite) TestLoadPEMKey() {
+	key := s.load("rsa-1", "RS256", s.rsaPrivateFile, "", time.Time{})
+	s.NotNil(key.PrivateKey)
+	s.IsType(&rsa.PublicKey{}, key.PublicKey)
+
+	key = s.load("ec-1", "ES256", s.ecPrivateFile, "", time.Time{})
+	s.NotNil(key.PrivateKey)
+	s.IsType(&ecdsa.PublicKey{}, key.PublicKey)
+
+	// a key with public key only verifies tokens but never signs them
+	key = s.load("rsa-old", "RS256", "", s.rsaPublicFile, time.Time{})
+	s.Nil(key.PrivateKey)
+	s.IsType(&rsa.PublicKey{}, key.PublicKey)
+
+	_, err := jwt.LoadPEMKey("bad", "XX256", s.rsaPrivateFile, "", time.Time{})
+	s.NotNil(err)
+	_, err = jwt.LoadPEMKey("bad", "HS256", s.rsaPrivateFile, "", time.Time{})
+	s.NotNil(err)
+	_, err = jwt.LoadPEMKey("bad", "ES256", s.rsaPrivateFile, "", time.Time{})
+	s.NotNil(err)
+	_, err = jwt.LoadPEMKey("bad", "RS256", "", "", time.Time{})
+	s.NotNil(err)
+	_, err = jwt.LoadPEMKey("bad", "RS256", filepath.Join(s.T().TempDir(), "missing.pem"), "", time.Time{})
+	s.NotNil(err)
+}
+
+func (s *JWKSTestSuite) TestSigningKeySelection() {
+	rsaKey := s.load("rsa-1", "RS256", s.rsaPrivateFile, "", time.Time{})
+	ecKey := s.load("ec-1", "ES256", s.ecPrivateFile, "", time.Time{})
+	publicOnly := s.load("rsa-old", "RS256", "", s.rsaPublicFile, time.Time{})
+	retired := s.load("rsa-retired", "RS256", s.rsaPrivateFile, "", time.Now().Add(-time.Hour))
+
+	s.Equal("rsa-1", s.keySet("", rsaKey, ecKey).SigningKey().ID)
+	s.Equal("ec-1", s.keySet("ec-1", rsaKey, ecKey).SigningKey().ID)
+	// keys without private key and retired keys are skipped
+	s.Equal("ec-1", s.keySet("", publicOnly, retired, ecKey).SigningKey().ID)
+
+	_, err := jwt.NewKeySet([]*jwt.SigningKey{publicOnly, retired}, "")
+	s.NotNil(err)
+	_, err = jwt.NewKeySet([]*jwt.SigningKey{rsaKey, ecKey}, "unknown")
+	s.NotNil(err)
+}
+
+func (s *JWKSTestSuite) TestVerifyByKid() {
+	rsaKey := s.load("rsa-1", "RS256", s.rsaPrivateFile, "", time.Time{})
+	ecKey := s.load("ec-1", "ES256", s.ecPrivateFile, "", time.Time{})
+
+	before := jwt.NewJWTAuth(jwt.WithKeySet(s.keySet("rsa-1", rsaKey, ecKey)))
+	tokenInfo, err := before.GenerateToken(user.ID)
+	s.Require().Nil(err)
+	oldToken := tokenInfo.GetAccessToken()
+	s.Equal("rsa-1", s.kidOf(oldToken))
+
+	// after rotation new tokens are signed by the new key, tokens of the old key stay valid
+	rotated := jwt.NewJWTAuth(jwt.WithKeySet(s.keySet("ec-1",
+		s.load("rsa-1", "RS256", "", s.rsaPublicFile, time.Time{}), ecKey)))
+	tokenInfo, err = rotated.GenerateToken(user.ID)
+	s.Require().Nil(err)
+	s.Equal("ec-1", s.kidOf(tokenInfo.GetAccessToken()))
+
+	claims, err := rotated.ParseAccessToken(oldToken)
+	s.Require().Nil(err)
+	s.Equal(user.ID, claims.Subject)
+	_, err = rotated.ParseAccessToken(tokenInfo.GetAccessToken())
+	s.Nil(err)
+
+	// tokens of retired or unknown keys are refused
+	retired := jwt.NewJWTAuth(jwt.WithKeySet(s.keySet("ec-1",
+		s.load("rsa-1", "RS256", "", s.rsaPublicFile, time.Now().Add(-time.Minute)), ecKey)))
+	_, err = retired.ParseAccessToken(oldToken)
+	s.NotNil(err)
+
+	unknown := jwt.NewJWTAuth(jwt.WithKeySet(s.keySet("ec-1", ecKey)))
+	_, err = unknown.ParseAccessToken(oldToken)
+	s.NotNil(err)
+}
+
+func (s *JWKSTestSuite) TestJWKS() {
+	rsaKey := s.load("rsa-1", "RS256", s.rsaPrivateFile, "", time.Time{})
+	ecKey := s.load("ec-1", "ES256", s.ecPrivateFile, "", time.Time{})
+	retired := s.load("rsa-retired", "RS256", "", s.rsaPublicFile, time.Now().Add(-time.Hour))
+	auth := jwt.NewJWTAuth(jwt.WithKeySet(s.keySet("rsa-1", rsaKey, ecKey, retired)))
+
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", api.NewWellKnownAPI(auth).JWKS)
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+	s.NotEmpty(w.Header().Get("Cache-Control"))
+
+	var set jwt.JWKSet
+	s.Require().Nil(parseReader(w.Body, &set))
+	s.Require().Len(set.Keys, 2)
+
+	keys := map[string]jwt.JWK{}
+	for _, key := range set.Keys {
+		s.Equal("sig", key.Use)
+		keys[key.Kid] = key
+	}
+	s.NotContains(keys, "rsa-retired")
+
+	s.Equal("RSA", keys["rsa-1"].Kty)
+	s.Equal("RS256", keys["rsa-1"].Alg)
+	s.Equal("AQAB", keys["rsa-1"].E)
+	s.NotEmpty(keys["rsa-1"].N)
+
+	s.Equal("EC", keys["ec-1"].Kty)
+	s.Equal("ES256", keys["ec-1"].Alg)
+	s.Equal("P-256", keys["ec-1"].Crv)
+	s.Len(keys["ec-1"].X, 43)
+	s.Len(keys["ec-1"].Y, 43)
+}
+
+func (s *JWKSTestSuite) TestJWKSWithoutKeySet() {
+	// tokens signed by HMAC publish no keys
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", api.NewWellKnownAPI(jwt.NewJWTAuth()).JWKS)
+	r.ServeHTTP(w, req)
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var set jwt.JWKSet
+	s.Require().Nil(parseReader(w.Body, &set))
+	s.NotNil(set.Keys)
+	s.Empty(set.Keys)
+}
+
+func TestJWKSTestSuite(t *testing.T) {
+	suite.Run(t, new(JWKSTestSuite))
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"github.com/shasw94/projX/app/lockout"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type LockoutTestSuite struct {
+	suite.Suite
+
+	store *lockout.Store
+}
+
+func (s *LockoutTestSuite) SetupTest() {
+	s.store = lockout.NewMemoryStore(lockout.Options{
+		MaxAttempts:   3,
+		IPMaxAttempts: 10,
+		DisableAfter:  6,
+		Window:        time.Minute,
+		BaseDelay:     time.Second,
+		MaxDelay:      5 * time.Second,
+	})
+}
+
+func (s *LockoutTestSuite) fail(n int, username, ip string) {
+	for i := 0; i < n; i++ {
+		s.Require().Nil(s.store.Fail(username, ip))
+	}
+}
+
+func (s *LockoutTestSuite) TestLockAfterMaxAttempts() {
+	s.fail(2, "alice", "10.0.0.1")
+	retryAfter, disabled, err := s.store.Check("alice", "10.0.0.1")
+	s.Nil(err)
+	s.False(disabled)
+	s.Zero(retryAfter)
+
+	s.fail(1, "Alice", "10.0.0.1")
+	retryAfter, _, err = s.store.Check("alice", "10.0.0.2")
+	s.Nil(err)
+	s.True(retryAfter > 0 && retryAfter <= time.Second)
+}
+
+func (s *LockoutTestSuite) TestProgressiveBackoff() {
+	s.fail(5, "alice", "")
+	retryAfter, _, err := s.store.Check("alice", "")
+	s.Nil(err)
+	s.True(retryAfter > 2*time.Second && retryAfter <= 4*time.Second)
+}
+
+func (s *LockoutTestSuite) TestDisableAndUnlock() {
+	s.fail(6, "alice", "10.0.0.1")
+	_, disabled, err := s.store.Check("alice", "10.0.0.1")
+	s.Nil(err)
+	s.True(disabled)
+
+	s.Nil(s.store.Unlock("alice"))
+	retryAfter, disabled, err := s.store.Check("alice", "10.0.0.2")
+	s.Nil(err)
+	s.False(disabled)
+	s.Zero(retryAfter)
+}
+
+func (s *LockoutTestSuite) TestLockIP() {
+	for i := 0; i < 10; i++ {
+		s.fail(1, "user-"+string(rune('a'+i)), "10.0.0.1")
+	}
+	retryAfter, _, err := s.store.Check("someone", "10.0.0.1")
+	s.Nil(err)
+	s.True(retryAfter > 0)
+}
+
+func (s *LockoutTestSuite) TestSucceedResetsFailures() {
+	s.fail(2, "alice", "")
+	s.Nil(s.store.Succeed("alice"))
+	s.fail(2, "alice", "")
+	retryAfter, _, err := s.store.Check("alice", "")
+	s.Nil(err)
+	s.Zero(retryAfter)
+}
+
+func TestLockoutTestSuite(t *testing.T) {
+	suite.Run(t, new(LockoutTestSuite))
+}
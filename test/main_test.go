@@ -7,13 +7,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/shasw94/projX/app"
 	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/mailer"
 	"github.com/shasw94/projX/app/migration"
 	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/config"
 	"github.com/shasw94/projX/logger"
 	"github.com/shasw94/projX/pkg/jwt"
 	"go.uber.org/dig"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -43,6 +46,8 @@ var (
 
 func TestMain(m *testing.M) {
 	logger.Initialize("testing")
+	config.Config.Mailer.Driver = mailer.DriverMemory
+	config.Config.RateLimit.Enable = false
 	container = app.BuildContainer()
 	engine = app.InitGinEngine(container)
 
@@ -60,6 +65,7 @@ func setup() {
 	migrate()
 	createRoleData()
 	createUserData()
+	grantAdmin()
 	setupToken()
 }
 
@@ -100,6 +106,18 @@ func createUserData() {
 	})
 }
 
+// grantAdmin give the admin role to the user of the test token
+func grantAdmin() {
+	container.Invoke(func(
+		passport interfaces.IPassport,
+	) error {
+		if _, err := passport.CreateRole(models.RoleAdmin, "administrator"); err != nil {
+			return err
+		}
+		return passport.AddRolesToUser(user.ID, models.RoleAdmin, nil)
+	})
+}
+
 func setupToken() {
 	container.Invoke(func(
 		jwtauth jwt.IJWTAuth,
@@ -185,3 +203,21 @@ func newDeleteRequest(formatRouter string, args ...interface{}) *http.Request {
 	req.Header.Add("Authorization", fmt.Sprintf("%s %s", AuthTokenType, token))
 	return req
 }
+
+// serveJSON serve json request, authenticated by the access token if any, and return code of the response.
+// Data of the response is decoded into data unless it is nil
+func serveJSON(method, path, accessToken string, body, data interface{}) string {
+	req, _ := http.NewRequest(method, path, toReader(body))
+	if accessToken != "" {
+		req.Header.Add("Authorization", fmt.Sprintf("%s %s", AuthTokenType, accessToken))
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	res := struct {
+		Code string      `json:"code"`
+		Data interface{} `json:"data"`
+	}{Data: data}
+	_ = parseReader(w.Body, &res)
+	return res.Code
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/pkg/totp"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mfaPassword = "mfa-user-pwd-1"
+
+type MFATestSuite struct {
+	suite.Suite
+}
+
+// codeAt totp code of the secret at the time step relative to the current one
+func (s *MFATestSuite) codeAt(secret string, offset int64) string {
+	code, err := totp.CodeAt(secret, totp.Step(time.Now())+offset)
+	s.Require().Nil(err)
+	return code
+}
+
+// enable register user with totp enabled, return the secret and the recovery codes.
+// The previous time step confirms the enrollment so the current one is left for login
+func (s *MFATestSuite) enable(username string) (string, []string) {
+	var tokenInfo schema.UserTokenInfo
+	code := serveJSON("POST", "/register", "", schema.RegisterBodyParams{
+		Username: username,
+		Email:    username + "@tokoin.io",
+		Password: mfaPassword,
+	}, &tokenInfo)
+	s.Require().Equal("SUCCESS", code)
+
+	var enrollment schema.MFAEnrollment
+	s.Require().Equal("SUCCESS", serveJSON("POST", "/api/v1/me/mfa/totp", tokenInfo.AccessToken, nil, &enrollment))
+
+	var recovery schema.MFARecoveryCodes
+	code = serveJSON("POST", "/api/v1/me/mfa/totp/confirm", tokenInfo.AccessToken,
+		schema.MFACodeBodyParams{Code: s.codeAt(enrollment.Secret, -1)}, &recovery)
+	s.Require().Equal("SUCCESS", code)
+	return enrollment.Secret, recovery.RecoveryCodes
+}
+
+// login first step of login, return the mfa pending token
+func (s *MFATestSuite) login(username string) string {
+	var tokenInfo schema.UserTokenInfo
+	code := serveJSON("POST", "/login", "", schema.LoginBodyParams{Username: username, Password: mfaPassword}, &tokenInfo)
+	s.Require().Equal("SUCCESS", code)
+	s.Require().True(tokenInfo.MFARequired)
+	s.Require().NotEmpty(tokenInfo.MFAToken)
+	s.Empty(tokenInfo.AccessToken)
+	s.Empty(tokenInfo.RefreshToken)
+	return tokenInfo.MFAToken
+}
+
+// loginMFA second step of login, return code of the response with the issued tokens
+func (s *MFATestSuite) loginMFA(params schema.LoginMFABodyParams) (string, schema.UserTokenInfo) {
+	var tokenInfo schema.UserTokenInfo
+	code := serveJSON("POST", "/login/mfa", "", params, &tokenInfo)
+	return code, tokenInfo
+}
+
+func (s *MFATestSuite) TestEnrollAndConfirm() {
+	var tokenInfo schema.UserTokenInfo
+	code := serveJSON("POST", "/register", "", schema.RegisterBodyParams{
+		Username: "mfa-enroll",
+		Email:    "mfa-enroll@tokoin.io",
+		Password: mfaPassword,
+	}, &tokenInfo)
+	s.Require().Equal("SUCCESS", code)
+
+	// confirm before enrollment
+	code = serveJSON("POST", "/api/v1/me/mfa/totp/confirm", tokenInfo.AccessToken, schema.MFACodeBodyParams{Code: "123456"}, nil)
+	s.Equal("ERROR_MFA_NOT_ENROLLED", code)
+
+	var enrollment schema.MFAEnrollment
+	s.Require().Equal("SUCCESS", serveJSON("POST", "/api/v1/me/mfa/totp", tokenInfo.AccessToken, nil, &enrollment))
+	s.NotEmpty(enrollment.Secret)
+	s.True(strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/"))
+	s.Contains(enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+
+	// codes outside of the accepted time steps are refused
+	code = serveJSON("POST", "/api/v1/me/mfa/totp/confirm", tokenInfo.AccessToken,
+		schema.MFACodeBodyParams{Code: s.codeAt(enrollment.Secret, 10)}, nil)
+	s.Equal("ERROR_INVALID_OTP", code)
+
+	var recovery schema.MFARecoveryCodes
+	code = serveJSON("POST", "/api/v1/me/mfa/totp/confirm", tokenInfo.AccessToken,
+		schema.MFACodeBodyParams{Code: s.codeAt(enrollment.Secret, 0)}, &recovery)
+	s.Require().Equal("SUCCESS", code)
+	s.Len(recovery.RecoveryCodes, 10)
+
+	s.Equal("ERROR_MFA_ENABLED", serveJSON("POST", "/api/v1/me/mfa/totp", tokenInfo.AccessToken, nil, nil))
+}
+
+func (s *MFATestSuite) TestTwoStepLogin() {
+	secret, _ := s.enable("mfa-login")
+
+	current := s.codeAt(secret, 0)
+	mfaToken := s.login("mfa-login")
+	code, tokenInfo := s.loginMFA(schema.LoginMFABodyParams{MFAToken: mfaToken, Code: current})
+	s.Require().Equal("SUCCESS", code)
+	s.NotEmpty(tokenInfo.AccessToken)
+	s.NotEmpty(tokenInfo.RefreshToken)
+	s.NotEmpty(tokenInfo.SessionID)
+
+	// the pending token is single-use
+	code, _ = s.loginMFA(schema.LoginMFABodyParams{MFAToken: mfaToken, Code: s.codeAt(secret, 1)})
+	s.Equal("ERROR_TOKEN_INVALID", code)
+
+	// a time step is only accepted once
+	code, _ = s.loginMFA(schema.LoginMFABodyParams{MFAToken: s.login("mfa-login"), Code: current})
+	s.Equal("ERROR_INVALID_OTP", code)
+
+	code, _ = s.loginMFA(schema.LoginMFABodyParams{MFAToken: "unknown-mfa-token", Code: s.codeAt(secret, 1)})
+	s.Equal("ERROR_TOKEN_INVALID", code)
+}
+
+func (s *MFATestSuite) TestRecoveryCode() {
+	secret, recoveryCodes := s.enable("mfa-recovery")
+	s.Require().NotEmpty(recoveryCodes)
+
+	// recovery codes ignore case and dashes
+	typed := strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", ""))
+	code, tokenInfo := s.loginMFA(schema.LoginMFABodyParams{MFAToken: s.login("mfa-recovery"), RecoveryCode: typed})
+	s.Require().Equal("SUCCESS", code)
+	s.NotEmpty(tokenInfo.AccessToken)
+
+	code, _ = s.loginMFA(schema.LoginMFABodyParams{MFAToken: s.login("mfa-recovery"), RecoveryCode: recoveryCodes[0]})
+	s.Equal("ERROR_INVALID_OTP", code)
+
+	// regenerated codes replace the previous ones
+	var regenerated schema.MFARecoveryCodes
+	code = serveJSON("POST", "/api/v1/me/mfa/recovery-codes", tokenInfo.AccessToken,
+		schema.MFACodeBodyParams{Code: s.codeAt(secret, 0)}, &regenerated)
+	s.Require().Equal("SUCCESS", code)
+	s.Len(regenerated.RecoveryCodes, 10)
+
+	code, _ = s.loginMFA(schema.LoginMFABodyParams{MFAToken: s.login("mfa-recovery"), RecoveryCode: recoveryCodes[1]})
+	s.Equal("ERROR_INVALID_OTP", code)
+	code, _ = s.loginMFA(schema.LoginMFABodyParams{MFAToken: s.login("mfa-recovery"), RecoveryCode: regenerated.RecoveryCodes[0]})
+	s.Equal("SUCCESS", code)
+}
+
+func (s *MFATestSuite) TestAttemptLimit() {
+	maxAttempts := config.Config.MFA.MaxAttempts
+	config.Config.MFA.MaxAttempts = 3
+	defer func() { config.Config.MFA.MaxAttempts = maxAttempts }()
+	secret, _ := s.enable("mfa-attempts")
+
+	mfaToken := s.login("mfa-attempts")
+	for i := 0; i < 3; i++ {
+		code, _ := s.loginMFA(schema.LoginMFABodyParams{MFAToken: mfaToken, Code: s.codeAt(secret, 10)})
+		s.Equal("ERROR_INVALID_OTP", code)
+	}
+
+	// the pending token is spent once the attempts are used up, even with the right code
+	code, _ := s.loginMFA(schema.LoginMFABodyParams{MFAToken: mfaToken, Code: s.codeAt(secret, 0)})
+	s.Equal("ERROR_TOKEN_INVALID", code)
+
+	code, _ = s.loginMFA(schema.LoginMFABodyParams{MFAToken: s.login("mfa-attempts"), Code: s.codeAt(secret, 0)})
+	s.Equal("SUCCESS", code)
+}
+
+func TestMFATestSuite(t *testing.T) {
+	suite.Run(t, new(MFATestSuite))
+}
@@ -0,0 +1,310 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/shasw94/projX/pkg/utils"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const oauthScope = "oauth.test.read"
+
+// oauthClient in-process oauth2 client, requests are served by the gin engine directly
+type oauthClient struct {
+	id     string
+	secret string
+}
+
+// token request token endpoint with form values, client authenticates by basic auth when it has a secret
+func (o *oauthClient) token(values url.Values) (int, map[string]interface{}) {
+	if o.secret == "" {
+		values.Set("client_id", o.id)
+	}
+	req, _ := http.NewRequest("POST", "/oauth/token", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if o.secret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.id), url.QueryEscape(o.secret))
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var body map[string]interface{}
+	_ = parseReader(w.Body, &body)
+	return w.Code, body
+}
+
+// clientCredentials request token by client_credentials grant
+func (o *oauthClient) clientCredentials(scope string) (int, map[string]interface{}) {
+	return o.token(url.Values{"grant_type": {"client_credentials"}, "scope": {scope}})
+}
+
+// exchange request token by authorization_code grant
+func (o *oauthClient) exchange(code, redirectURI, verifier string) (int, map[string]interface{}) {
+	return o.token(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	})
+}
+
+type OAuthTestSuite struct {
+	suite.Suite
+
+	jwt       jwt.IJWTAuth
+	userToken string
+}
+
+func (s *OAuthTestSuite) SetupSuite() {
+	err := container.Invoke(func(
+		permRepo interfaces.IPermissionRepository,
+		jwtauth jwt.IJWTAuth,
+	) error {
+		s.jwt = jwtauth
+		err := permRepo.FirstOrCreate(&models.Permission{Name: oauthScope, GuardName: oauthScope, Description: oauthScope})
+		if err != nil {
+			return err
+		}
+		err = permRepo.FirstOrCreate(&models.Permission{
+			Name:        models.PermissionUsersRead,
+			GuardName:   models.PermissionUsersRead,
+			Description: "read users",
+		})
+		if err != nil {
+			return err
+		}
+
+		tokenInfo, err := jwtauth.GenerateTokenWithClaims(user.ID, jwt.CustomClaims{Permissions: []string{oauthScope}})
+		if err != nil {
+			return err
+		}
+		s.userToken = tokenInfo.GetAccessToken()
+		return nil
+	})
+	s.Require().Nil(err)
+}
+
+// registerClient register client by admin api
+func (s *OAuthTestSuite) registerClient(params schema.OAuthClientBodyParams) *oauthClient {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostAuthRequest("/admin/oauth/clients", params))
+	s.Require().Equal(http.StatusOK, w.Code)
+
+	var res struct {
+		Code string                        `json:"code"`
+		Data schema.OAuthClientCredentials `json:"data"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	s.Require().Equal("SUCCESS", res.Code)
+	return &oauthClient{id: res.Data.ID, secret: res.Data.ClientSecret}
+}
+
+// authorize approve authorization request as the test user
+func (s *OAuthTestSuite) authorize(params schema.OAuthAuthorizeBodyParams) (string, schema.OAuthAuthorizeResult) {
+	req, _ := http.NewRequest("POST", "/oauth/authorize", toReader(params))
+	req.Header.Add("Authorization", fmt.Sprintf("%s %s", AuthTokenType, s.userToken))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var res struct {
+		Code string                      `json:"code"`
+		Data schema.OAuthAuthorizeResult `json:"data"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	return res.Code, res.Data
+}
+
+func (s *OAuthTestSuite) TestClientCredentials() {
+	client := s.registerClient(schema.OAuthClientBodyParams{
+		Name:       "partner-service",
+		Scopes:     []string{oauthScope, models.PermissionUsersRead},
+		GrantTypes: []string{"client_credentials"},
+	})
+	s.NotEmpty(client.secret)
+
+	status, body := client.clientCredentials(oauthScope)
+	s.Equal(http.StatusOK, status)
+	s.Equal("Bearer", body["token_type"])
+	s.Equal(oauthScope, body["scope"])
+
+	claims, err := s.jwt.ParseAccessToken(body["access_token"].(string))
+	s.Nil(err)
+	s.Equal(client.id, claims.Subject)
+	s.Equal(client.id, claims.ClientID)
+	s.True(claims.HasPermission(oauthScope))
+
+	// the granted scope does not include users.read
+	req, _ := http.NewRequest("GET", "/api/v1/users", nil)
+	req.Header.Add("Authorization", fmt.Sprintf("%s %s", AuthTokenType, body["access_token"]))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	s.Equal(http.StatusForbidden, w.Code)
+
+	status, body = client.clientCredentials(models.PermissionUsersRead)
+	s.Require().Equal(http.StatusOK, status)
+
+	req, _ = http.NewRequest("GET", "/api/v1/users", nil)
+	req.Header.Add("Authorization", fmt.Sprintf("%s %s", AuthTokenType, body["access_token"]))
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	s.Equal(http.StatusOK, w.Code)
+}
+
+func (s *OAuthTestSuite) TestClientCredentialsInvalidClient() {
+	client := s.registerClient(schema.OAuthClientBodyParams{
+		Name:       "partner-service",
+		Scopes:     []string{oauthScope},
+		GrantTypes: []string{"client_credentials"},
+	})
+
+	client.secret = "wrong-secret"
+	status, body := client.clientCredentials("")
+	s.Equal(http.StatusUnauthorized, status)
+	s.Equal("invalid_client", body["error"])
+}
+
+func (s *OAuthTestSuite) TestClientCredentialsInvalidScope() {
+	client := s.registerClient(schema.OAuthClientBodyParams{
+		Name:       "partner-service",
+		Scopes:     []string{oauthScope},
+		GrantTypes: []string{"client_credentials"},
+	})
+
+	status, body := client.clientCredentials("users.delete")
+	s.Equal(http.StatusBadRequest, status)
+	s.Equal("invalid_scope", body["error"])
+}
+
+func (s *OAuthTestSuite) TestUnsupportedGrantType() {
+	client := s.registerClient(schema.OAuthClientBodyParams{
+		Name:       "partner-service",
+		GrantTypes: []string{"client_credentials"},
+	})
+
+	status, body := client.token(url.Values{"grant_type": {"password"}})
+	s.Equal(http.StatusBadRequest, status)
+	s.Equal("unsupported_grant_type", body["error"])
+}
+
+func (s *OAuthTestSuite) TestAuthorizationCodeWithPKCE() {
+	redirectURI := "https://partner.example.com/callback"
+	client := s.registerClient(schema.OAuthClientBodyParams{
+		Name:         "partner-app",
+		RedirectURIs: []string{redirectURI},
+		Scopes:       []string{oauthScope},
+		GrantTypes:   []string{"authorization_code"},
+		Public:       true,
+	})
+	s.Empty(client.secret)
+
+	verifier, err := utils.RandomToken(48)
+	s.Require().Nil(err)
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	code, result := s.authorize(schema.OAuthAuthorizeBodyParams{
+		ResponseType:        "code",
+		ClientID:            client.id,
+		RedirectURI:         redirectURI,
+		Scope:               oauthScope,
+		State:               "xyz",
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: "S256",
+	})
+	s.Require().Equal("SUCCESS", code)
+	s.NotEmpty(result.Code)
+	s.Equal("xyz", result.State)
+
+	callback, err := url.Parse(result.RedirectURI)
+	s.Require().Nil(err)
+	s.Equal(result.Code, callback.Query().Get("code"))
+	s.Equal("xyz", callback.Query().Get("state"))
+
+	status, body := client.exchange(result.Code, redirectURI, "wrong-verifier-wrong-verifier-wrong-verifier")
+	s.Equal(http.StatusBadRequest, status)
+	s.Equal("invalid_grant", body["error"])
+
+	status, body = client.exchange(result.Code, redirectURI, verifier)
+	s.Equal(http.StatusOK, status)
+	s.Equal(oauthScope, body["scope"])
+
+	claims, err := s.jwt.ParseAccessToken(body["access_token"].(string))
+	s.Nil(err)
+	s.Equal(user.ID, claims.Subject)
+	s.Equal(client.id, claims.ClientID)
+	s.Equal([]string{oauthScope}, claims.Permissions)
+
+	// authorization code can only be used once
+	status, body = client.exchange(result.Code, redirectURI, verifier)
+	s.Equal(http.StatusBadRequest, status)
+	s.Equal("invalid_grant", body["error"])
+}
+
+func (s *OAuthTestSuite) TestExchangeRequiresExplicitRedirectURI() {
+	redirectURI := "https://partner.example.com/callback"
+	client := s.registerClient(schema.OAuthClientBodyParams{
+		Name:         "partner-app",
+		RedirectURIs: []string{redirectURI},
+		Scopes:       []string{oauthScope},
+		GrantTypes:   []string{"authorization_code"},
+		Public:       true,
+	})
+
+	verifier, err := utils.RandomToken(48)
+	s.Require().Nil(err)
+	sum := sha256.Sum256([]byte(verifier))
+	code, result := s.authorize(schema.OAuthAuthorizeBodyParams{
+		ResponseType:        "code",
+		ClientID:            client.id,
+		RedirectURI:         redirectURI,
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(sum[:]),
+		CodeChallengeMethod: "S256",
+	})
+	s.Require().Equal("SUCCESS", code)
+
+	// redirect_uri sent to authorize must be repeated on exchange
+	status, body := client.exchange(result.Code, "", verifier)
+	s.Equal(http.StatusBadRequest, status)
+	s.Equal("invalid_grant", body["error"])
+
+	status, body = client.exchange(result.Code, redirectURI+"/", verifier)
+	s.Equal(http.StatusBadRequest, status)
+	s.Equal("invalid_grant", body["error"])
+
+	status, _ = client.exchange(result.Code, redirectURI, verifier)
+	s.Equal(http.StatusOK, status)
+}
+
+func (s *OAuthTestSuite) TestAuthorizeUnregisteredRedirectURI() {
+	client := s.registerClient(schema.OAuthClientBodyParams{
+		Name:         "partner-app",
+		RedirectURIs: []string{"https://partner.example.com/callback"},
+		Scopes:       []string{oauthScope},
+		GrantTypes:   []string{"authorization_code"},
+		Public:       true,
+	})
+
+	code, _ := s.authorize(schema.OAuthAuthorizeBodyParams{
+		ResponseType:        "code",
+		ClientID:            client.id,
+		RedirectURI:         "https://attacker.example.com/callback",
+		CodeChallenge:       strings.Repeat("a", 43),
+		CodeChallengeMethod: "S256",
+	})
+	s.Equal("INVALID_PARAMS", code)
+}
+
+func TestOAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(OAuthTestSuite))
+}
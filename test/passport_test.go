@@ -0,0 +1,191 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type PassportTestSuite struct {
+	suite.Suite
+
+	suffix string
+}
+
+func (s *PassportTestSuite) SetupSuite() {
+	s.suffix = time.Now().Format("20060102150405.000")
+}
+
+// call send authenticated request and decode data of the response, return code of the response
+func (s *PassportTestSuite) call(method, path string, body interface{}, data interface{}) string {
+	req, _ := http.NewRequest(method, path, toReader(body))
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", AuthTokenType, token))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var res struct {
+		Code string          `json:"code"`
+		Data json.RawMessage `json:"data"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	if data != nil && res.Code == "SUCCESS" {
+		s.Require().Nil(json.Unmarshal(res.Data, data))
+	}
+	return res.Code
+}
+
+func (s *PassportTestSuite) TestRolePermissions() {
+	var permission models.Permission
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/permissions", schema.PermissionBodyParams{
+		Name: "passport read " + s.suffix,
+	}, &permission))
+
+	var role models.Role
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles", schema.RoleBodyParams{
+		Name: "passport role " + s.suffix,
+	}, &role))
+
+	s.Equal("SUCCESS", s.call("POST", "/admin/roles/"+role.ID+"/permissions", schema.PermissionsBodyParams{
+		Permissions: []string{permission.Name},
+	}, nil))
+	s.Equal("ERROR_NOT_EXIST_PERMISSION", s.call("POST", "/admin/roles/"+role.ID+"/permissions", schema.PermissionsBodyParams{
+		Permissions: []string{"passport unknown " + s.suffix},
+	}, nil))
+
+	var list schema.PermissionList
+	s.Require().Equal("SUCCESS", s.call("GET", "/admin/roles/"+role.ID+"/permissions?limit=10", nil, &list))
+	s.Require().Len(list.Permissions, 1)
+	s.Equal(permission.ID, list.Permissions[0].ID)
+	s.Equal(int64(1), list.PageInfo.Total)
+
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/roles/"+role.ID+"/permissions/"+permission.ID, nil, nil))
+	s.Require().Equal("SUCCESS", s.call("GET", "/admin/roles/"+role.ID+"/permissions", nil, &list))
+	s.Empty(list.Permissions)
+
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/roles/"+role.ID, nil, nil))
+	s.Equal("ERROR_NOT_EXIST_ROLE", s.call("GET", "/admin/roles/"+role.ID, nil, nil))
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/permissions/"+permission.ID, nil, nil))
+}
+
+func (s *PassportTestSuite) TestUserRoles() {
+	var role models.Role
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles", schema.RoleBodyParams{
+		Name: "passport user role " + s.suffix,
+	}, &role))
+
+	path := "/admin/users/" + users[2].ID + "/roles"
+	s.Equal("SUCCESS", s.call("POST", path, schema.RolesBodyParams{Roles: []string{role.Name}}, nil))
+
+	var list schema.RoleList
+	s.Require().Equal("SUCCESS", s.call("GET", path, nil, &list))
+	s.Contains(schema.Roles(list.Roles).IDs(), role.ID)
+
+	s.Equal("SUCCESS", s.call("DELETE", path+"/"+role.ID, nil, nil))
+	s.Require().Equal("SUCCESS", s.call("GET", path, nil, &list))
+	s.NotContains(schema.Roles(list.Roles).IDs(), role.ID)
+
+	s.Equal("ERROR_NOT_EXIST_USER", s.call("POST", "/admin/users/not-found-id/roles", schema.RolesBodyParams{Roles: []string{role.ID}}, nil))
+}
+
+func (s *PassportTestSuite) TestRoleHierarchy() {
+	var permission models.Permission
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/permissions", schema.PermissionBodyParams{
+		Name: "passport inherited " + s.suffix,
+	}, &permission))
+
+	var junior, senior models.Role
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles", schema.RoleBodyParams{Name: "passport junior " + s.suffix}, &junior))
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles", schema.RoleBodyParams{Name: "passport senior " + s.suffix}, &senior))
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles/"+junior.ID+"/permissions", schema.PermissionsBodyParams{
+		Permissions: []string{permission.ID},
+	}, nil))
+
+	s.Equal("SUCCESS", s.call("POST", "/admin/roles/"+senior.ID+"/parents", schema.RolesBodyParams{Roles: []string{junior.ID}}, nil))
+	s.Equal("ERROR_INVALID_PARENT", s.call("POST", "/admin/roles/"+junior.ID+"/parents", schema.RolesBodyParams{Roles: []string{senior.ID}}, nil))
+	s.Equal("ERROR_INVALID_PARENT", s.call("POST", "/admin/roles/"+junior.ID+"/parents", schema.RolesBodyParams{Roles: []string{junior.ID}}, nil))
+
+	var effective schema.EffectivePermissionList
+	s.Require().Equal("SUCCESS", s.call("GET", "/admin/roles/"+senior.ID+"/effective-permissions", nil, &effective))
+	s.Require().Len(effective.Permissions, 1)
+	s.Equal(permission.ID, effective.Permissions[0].ID)
+	s.True(effective.Permissions[0].Inherited)
+	s.Require().Len(effective.Permissions[0].Sources, 1)
+	s.Equal(junior.ID, effective.Permissions[0].Sources[0].RoleID)
+	s.Equal(1, effective.Permissions[0].Sources[0].Depth)
+
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/roles/"+senior.ID+"/parents/"+junior.ID, nil, nil))
+	s.Require().Equal("SUCCESS", s.call("GET", "/admin/roles/"+senior.ID+"/effective-permissions", nil, &effective))
+	s.Empty(effective.Permissions)
+
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/roles/"+senior.ID, nil, nil))
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/roles/"+junior.ID, nil, nil))
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/permissions/"+permission.ID, nil, nil))
+}
+
+func (s *PassportTestSuite) TestExplainAuthz() {
+	namespace := "explain" + strings.ReplaceAll(s.suffix, ".", "")
+	var read, wildcard models.Permission
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/permissions", schema.PermissionBodyParams{Name: namespace + ".read"}, &read))
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/permissions", schema.PermissionBodyParams{Name: namespace + ".*"}, &wildcard))
+
+	var parent, child models.Role
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles", schema.RoleBodyParams{Name: "explain parent " + s.suffix}, &parent))
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles", schema.RoleBodyParams{Name: "explain child " + s.suffix}, &child))
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles/"+parent.ID+"/permissions", schema.PermissionsBodyParams{
+		Permissions: []string{wildcard.ID},
+	}, nil))
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/roles/"+child.ID+"/parents", schema.RolesBodyParams{Roles: []string{parent.ID}}, nil))
+
+	path := "/admin/authz/explain?user=" + users[2].ID + "&permission=" + namespace + ".read"
+	var explanation schema.AuthzExplanation
+	s.Require().Equal("SUCCESS", s.call("GET", path, nil, &explanation))
+	s.True(explanation.Exists)
+	s.False(explanation.Granted)
+	s.Empty(explanation.Paths)
+
+	startsAt := time.Now().Add(time.Hour)
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/users/"+users[2].ID+"/roles", schema.UserRolesBodyParams{
+		RolesBodyParams: schema.RolesBodyParams{Roles: []string{child.ID}},
+		StartsAt:        &startsAt,
+	}, nil))
+	s.Require().Equal("SUCCESS", s.call("GET", path, nil, &explanation))
+	s.False(explanation.Granted)
+	s.Require().Len(explanation.NearMisses, 1)
+	s.Equal(schema.AuthzNotStarted, explanation.NearMisses[0].Reason)
+
+	s.Require().Equal("SUCCESS", s.call("POST", "/admin/users/"+users[2].ID+"/roles", schema.UserRolesBodyParams{
+		RolesBodyParams: schema.RolesBodyParams{Roles: []string{child.ID}},
+	}, nil))
+	s.Require().Equal("SUCCESS", s.call("GET", path, nil, &explanation))
+	s.True(explanation.Granted)
+	s.Empty(explanation.NearMisses)
+	s.Require().Len(explanation.Paths, 1)
+	s.Equal(schema.AuthzSourceInheritedRole, explanation.Paths[0].Source)
+	s.Equal(parent.ID, explanation.Paths[0].RoleID)
+	s.Equal(wildcard.ID, explanation.Paths[0].Granted.ID)
+	s.True(explanation.Paths[0].Wildcard)
+
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/roles/"+child.ID, nil, nil))
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/roles/"+parent.ID, nil, nil))
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/permissions/"+read.ID, nil, nil))
+	s.Equal("SUCCESS", s.call("DELETE", "/admin/permissions/"+wildcard.ID, nil, nil))
+}
+
+func (s *PassportTestSuite) TestListRolesPaginated() {
+	var list schema.RoleList
+	s.Require().Equal("SUCCESS", s.call("GET", "/admin/roles?page=1&limit=1", nil, &list))
+	s.LessOrEqual(len(list.Roles), 1)
+	s.Equal(1, list.PageInfo.Limit)
+	s.GreaterOrEqual(list.PageInfo.Total, int64(len(roles)))
+}
+
+func TestPassportTestSuite(t *testing.T) {
+	suite.Run(t, new(PassportTestSuite))
+}
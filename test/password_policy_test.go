@@ -0,0 +1,61 @@
+package test
+
+import (
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/validation"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type PasswordPolicyTestSuite struct {
+	suite.Suite
+}
+
+func (s *PasswordPolicyTestSuite) TestCheck() {
+	policy := &validation.PasswordPolicy{
+		MinLength:     10,
+		MaxLength:     20,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		BanUsername:   true,
+		BanEmail:      true,
+		Breached:      map[string]struct{}{"correcthorse1!a": {}},
+	}
+
+	s.Empty(policy.Check("Str0ng-Passphrase", "alice", "alice@example.com"))
+	s.Equal([]string{
+		"must be at least 10 characters",
+		"must contain an uppercase letter",
+		"must contain a digit",
+		"must contain a symbol",
+		"must not contain the username",
+		"must not contain the email address",
+	}, policy.Check("alicebob", "alice", "bob@example.com"))
+	s.Equal([]string{"must be at most 20 characters"}, policy.Check("Str0ng-Passphrase-Too-Long", "", ""))
+	s.Equal([]string{"is too common or has appeared in a data breach"}, policy.Check("CorrectHorse1!A", "", ""))
+}
+
+func (s *PasswordPolicyTestSuite) TestValidateStruct() {
+	err := validation.New().ValidateStruct(schema.RegisterBodyParams{
+		Username: "policy-user",
+		Email:    "policy-user@tokoin.io",
+		Password: "policy-user",
+	})
+	s.Require().NotNil(err)
+	s.Contains(err.Error(), "must contain a digit")
+	s.Contains(err.Error(), "must not contain the username")
+
+	err = validation.New().ValidateStruct(schema.RegisterBodyParams{
+		Username: "policy-user",
+		Email:    "policy-user@tokoin.io",
+		Password: "password123",
+	})
+	s.Require().NotNil(err)
+	s.Contains(err.Error(), "data breach")
+}
+
+func TestPasswordPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordPolicyTestSuite))
+}
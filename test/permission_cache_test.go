@@ -0,0 +1,43 @@
+package test
+
+import (
+	"github.com/shasw94/projX/app/permcache"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type PermissionCacheTestSuite struct {
+	suite.Suite
+}
+
+func (s *PermissionCacheTestSuite) TestVersioned() {
+	store := permcache.NewMemoryStore(time.Minute)
+
+	set, version, err := store.Get(users[1].ID)
+	s.Require().Nil(err)
+	s.Nil(set)
+
+	s.Require().Nil(store.Set(users[1].ID, version, &schema.PermissionSet{IDs: []string{"id-1"}, GuardNames: []string{"read-users"}}))
+	set, _, err = store.Get(users[1].ID)
+	s.Require().Nil(err)
+	s.Require().NotNil(set)
+	s.True(set.Has("id-1"))
+	s.True(set.Has("read users"))
+	s.False(set.Has("write users"))
+
+	// sets computed before invalidation are never served
+	s.Require().Nil(store.Invalidate())
+	set, _, err = store.Get(users[1].ID)
+	s.Require().Nil(err)
+	s.Nil(set)
+
+	s.Require().Nil(store.Set(users[1].ID, version, &schema.PermissionSet{}))
+	set, _, _ = store.Get(users[1].ID)
+	s.Nil(set)
+}
+
+func TestPermissionCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(PermissionCacheTestSuite))
+}
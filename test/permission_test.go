@@ -0,0 +1,216 @@
+package test
+
+import (
+	"fmt"
+	"github.com/casbin/casbin/v2"
+	"github.com/shasw94/projX/app"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/models"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type PermissionTestSuite struct {
+	suite.Suite
+
+	passport interfaces.IPassport
+	jwt      jwt.IJWTAuth
+}
+
+func (s *PermissionTestSuite) SetupSuite() {
+	err := container.Invoke(func(passport interfaces.IPassport, jwtauth jwt.IJWTAuth) error {
+		s.passport = passport
+		s.jwt = jwtauth
+		_, err := passport.CreatePermission(models.PermissionUsersRead, "read users")
+		return err
+	})
+	s.Require().Nil(err)
+}
+
+// tokenOf issue access token of the user with the custom claims
+func (s *PermissionTestSuite) tokenOf(userID string, custom jwt.CustomClaims) string {
+	tokenInfo, err := s.jwt.GenerateTokenWithClaims(userID, custom)
+	s.Require().Nil(err)
+	return tokenInfo.GetAccessToken()
+}
+
+// call send GET request with the access token and return status and code of the response
+func (s *PermissionTestSuite) call(path, accessToken string) (int, string) {
+	req, _ := http.NewRequest("GET", path, nil)
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", AuthTokenType, accessToken))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var res struct {
+		Code string `json:"code"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	return w.Code, res.Code
+}
+
+func (s *PermissionTestSuite) TestAdminRequiresRole() {
+	status, code := s.call("/admin/roles", s.tokenOf(users[1].ID, jwt.CustomClaims{}))
+	s.Equal(http.StatusForbidden, status)
+	s.Equal("ERROR_NO_PERMISSION", code)
+
+	_, code = s.call("/admin/roles", token)
+	s.Equal("SUCCESS", code)
+}
+
+func (s *PermissionTestSuite) TestDelegatedTokenNotAdmin() {
+	// the test user holds the admin role, but roles are not delegated to oauth clients or api keys
+	status, code := s.call("/admin/roles", s.tokenOf(user.ID, jwt.CustomClaims{ClientID: "permission-test-client"}))
+	s.Equal(http.StatusForbidden, status)
+	s.Equal("ERROR_NO_PERMISSION", code)
+
+	status, _ = s.call("/admin/roles", s.tokenOf(user.ID, jwt.CustomClaims{Extra: map[string]interface{}{"api_key": "permission-test-key"}}))
+	s.Equal(http.StatusForbidden, status)
+}
+
+func (s *PermissionTestSuite) TestDelegatedTokenSelfService() {
+	status, _ := s.call("/api/v1/me/sessions", s.tokenOf(user.ID, jwt.CustomClaims{}))
+	s.Equal(http.StatusOK, status)
+
+	status, code := s.call("/api/v1/me/sessions", s.tokenOf(user.ID, jwt.CustomClaims{ClientID: "permission-test-client"}))
+	s.Equal(http.StatusForbidden, status)
+	s.Equal("ERROR_NO_PERMISSION", code)
+
+	status, _ = s.call("/api/v1/me/sessions", s.tokenOf(user.ID, jwt.CustomClaims{Extra: map[string]interface{}{"api_key": "permission-test-key"}}))
+	s.Equal(http.StatusForbidden, status)
+}
+
+func (s *PermissionTestSuite) TestRequirePermission() {
+	accessToken := s.tokenOf(users[1].ID, jwt.CustomClaims{})
+	status, code := s.call("/api/v1/users", accessToken)
+	s.Equal(http.StatusForbidden, status)
+	s.Equal("ERROR_NO_PERMISSION", code)
+
+	s.Require().Nil(s.passport.AddPermissionsToUser(users[1].ID, models.PermissionUsersRead, nil))
+	defer s.passport.RemovePermissionsFromUser(users[1].ID, models.PermissionUsersRead)
+
+	status, _ = s.call("/api/v1/users", accessToken)
+	s.Equal(http.StatusOK, status)
+
+	// delegated tokens only carry the permissions granted to the client
+	status, _ = s.call("/api/v1/users", s.tokenOf(users[1].ID, jwt.CustomClaims{ClientID: "permission-test-client"}))
+	s.Equal(http.StatusForbidden, status)
+}
+
+func (s *PermissionTestSuite) TestOwnerRule() {
+	accessToken := s.tokenOf(users[1].ID, jwt.CustomClaims{})
+	status, _ := s.call("/api/v1/users/"+users[1].ID, accessToken)
+	s.Equal(http.StatusOK, status)
+
+	status, code := s.call("/api/v1/users/"+users[2].ID, accessToken)
+	s.Equal(http.StatusForbidden, status)
+	s.Equal("ERROR_NO_PERMISSION", code)
+
+	// delegated tokens only carry the permissions granted to the client, ownership included
+	status, _ = s.call("/api/v1/users/"+users[1].ID, s.tokenOf(users[1].ID, jwt.CustomClaims{ClientID: "permission-test-client"}))
+	s.Equal(http.StatusForbidden, status)
+
+	s.Require().Nil(s.passport.AddPermissionsToUser(users[1].ID, models.PermissionUsersRead, nil))
+	defer s.passport.RemovePermissionsFromUser(users[1].ID, models.PermissionUsersRead)
+	status, _ = s.call("/api/v1/users/"+users[2].ID, accessToken)
+	s.Equal(http.StatusOK, status)
+}
+
+func (s *PermissionTestSuite) TestOwnerRuleWithCasbin() {
+	var enforcer *casbin.SyncedEnforcer
+	s.Require().Nil(container.Invoke(func(e *casbin.SyncedEnforcer) { enforcer = e }))
+
+	config.Config.Casbin.Enable = true
+	enforcer.EnableEnforce(true)
+	defer func() {
+		config.Config.Casbin.Enable = false
+		enforcer.EnableEnforce(false)
+	}()
+	casbinEngine := app.InitGinEngine(container)
+
+	call := func(path, accessToken string) int {
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", AuthTokenType, accessToken))
+		w := httptest.NewRecorder()
+		casbinEngine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// no policy is loaded, casbin guarded routes are denied
+	s.Equal(http.StatusForbidden, call("/admin/roles", token))
+
+	// ownership rules are not subject to casbin policies
+	accessToken := s.tokenOf(users[1].ID, jwt.CustomClaims{})
+	s.Equal(http.StatusOK, call("/api/v1/users/"+users[1].ID, accessToken))
+	s.Equal(http.StatusForbidden, call("/api/v1/users/"+users[2].ID, accessToken))
+}
+
+func (s *PermissionTestSuite) TestWildcardPermissions() {
+	namespace := fmt.Sprintf("wildcard%d", time.Now().UnixNano())
+	for _, name := range []string{namespace + ".*", namespace + ".export", "*.export"} {
+		_, err := s.passport.CreatePermission(name, name)
+		s.Require().Nil(err)
+		defer s.passport.DeletePermission(name)
+	}
+
+	ok, err := s.passport.UserHasPermission(users[1].ID, namespace+".export")
+	s.Require().Nil(err)
+	s.False(ok)
+
+	s.Require().Nil(s.passport.AddPermissionsToUser(users[1].ID, namespace+".*", nil))
+	defer s.passport.RemovePermissionsFromUser(users[1].ID, namespace+".*")
+	ok, err = s.passport.UserHasPermission(users[1].ID, namespace+".export")
+	s.Require().Nil(err)
+	s.True(ok)
+
+	role, err := s.passport.CreateRole("wildcard role "+namespace, "")
+	s.Require().Nil(err)
+	defer s.passport.DeleteRole(role.ID)
+	s.Require().Nil(s.passport.AddPermissionsToRole(role.ID, "*.export"))
+
+	ok, err = s.passport.RoleHasPermission(role.ID, namespace+".export")
+	s.Require().Nil(err)
+	s.True(ok)
+	ok, err = s.passport.RoleHasAllPermissions(role.ID, []string{namespace + ".export", namespace + ".*"})
+	s.Require().Nil(err)
+	s.False(ok)
+}
+
+func (s *PermissionTestSuite) TestTimeBoundGrants() {
+	name := fmt.Sprintf("timebound%d", time.Now().UnixNano())
+	permission, err := s.passport.CreatePermission(name, name)
+	s.Require().Nil(err)
+	defer s.passport.DeletePermission(name)
+
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	s.NotNil(s.passport.AddPermissionsToUser(users[1].ID, name, &schema.Grant{ExpiresAt: &past}))
+
+	s.Require().Nil(s.passport.AddPermissionsToUser(users[1].ID, name, &schema.Grant{StartsAt: &future, Reason: "on call"}))
+	defer s.passport.RemovePermissionsFromUser(users[1].ID, name)
+	ok, err := s.passport.UserHasPermission(users[1].ID, name)
+	s.Require().Nil(err)
+	s.False(ok)
+
+	expiresAt := time.Now().Add(time.Second)
+	s.Require().Nil(s.passport.AddPermissionsToUser(users[1].ID, name, &schema.Grant{ExpiresAt: &expiresAt}))
+	ok, err = s.passport.UserHasPermission(users[1].ID, name)
+	s.Require().Nil(err)
+	s.True(ok)
+
+	expired, err := s.passport.SweepGrants(time.Now(), expiresAt.Add(time.Second))
+	s.Require().Nil(err)
+	s.Require().Len(expired.Permissions, 1)
+	s.Equal(permission.ID, expired.Permissions[0].PermissionID)
+	ok, err = s.passport.UserHasDirectPermission(users[1].ID, name)
+	s.Require().Nil(err)
+	s.False(ok)
+}
+
+func TestPermissionTestSuite(t *testing.T) {
+	suite.Run(t, new(PermissionTestSuite))
+}
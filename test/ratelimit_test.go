@@ -0,0 +1,101 @@
+package test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/middleware"
+	"github.com/shasw94/projX/app/ratelimit"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+}
+
+func (s *RateLimitTestSuite) TestTokenBucket() {
+	limiter := ratelimit.NewMemoryLimiter()
+	policy := schema.RateLimitPolicy{Algorithm: ratelimit.AlgorithmTokenBucket, Limit: 3, Period: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow("client", policy)
+		s.Nil(err)
+		s.True(result.Allowed)
+		s.Equal(int64(2-i), result.Remaining)
+	}
+
+	result, err := limiter.Allow("client", policy)
+	s.Nil(err)
+	s.False(result.Allowed)
+	s.True(result.RetryAfter > 0 && result.RetryAfter <= 20*time.Second)
+
+	result, err = limiter.Allow("other-client", policy)
+	s.Nil(err)
+	s.True(result.Allowed)
+}
+
+func (s *RateLimitTestSuite) TestSlidingWindow() {
+	limiter := ratelimit.NewMemoryLimiter()
+	policy := schema.RateLimitPolicy{Algorithm: ratelimit.AlgorithmSlidingWindow, Limit: 2, Period: 100 * time.Millisecond}
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow("client", policy)
+		s.Nil(err)
+		s.True(result.Allowed)
+	}
+
+	result, err := limiter.Allow("client", policy)
+	s.Nil(err)
+	s.False(result.Allowed)
+	s.Equal(int64(0), result.Remaining)
+
+	time.Sleep(result.RetryAfter + 10*time.Millisecond)
+	result, err = limiter.Allow("client", policy)
+	s.Nil(err)
+	s.True(result.Allowed)
+}
+
+func (s *RateLimitTestSuite) TestMiddleware() {
+	enabled := config.Config.RateLimit.Enable
+	policy := config.Config.RateLimit.Policies["auth"]
+	policy.Algorithm = ratelimit.AlgorithmSlidingWindow
+	policy.Limit = 1
+	policy.Period = 60
+	policy.KeyBy = middleware.RateLimitByIP
+	config.Config.RateLimit.Enable = true
+	config.Config.RateLimit.Policies["ratelimit-test"] = policy
+	defer func() {
+		config.Config.RateLimit.Enable = enabled
+		delete(config.Config.RateLimit.Policies, "ratelimit-test")
+	}()
+
+	r := gin.New()
+	r.GET("/limited", middleware.RateLimitMiddleware(ratelimit.NewMemoryLimiter(), "ratelimit-test"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/limited", nil))
+	s.Equal(http.StatusNoContent, w.Code)
+	s.Equal("1", w.Header().Get("X-RateLimit-Limit"))
+	s.Equal("0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/limited", nil))
+	s.Equal(http.StatusTooManyRequests, w.Code)
+	s.Equal("60", w.Header().Get("Retry-After"))
+
+	var res struct {
+		Code string `json:"code"`
+	}
+	s.Nil(parseReader(w.Body, &res))
+	s.Equal("ERROR_TOO_MANY_REQUEST", res.Code)
+}
+
+func TestRateLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"fmt"
+	"github.com/shasw94/projX/app/interfaces"
+	"github.com/shasw94/projX/app/migration"
+	"github.com/shasw94/projX/app/rbac"
+	"github.com/stretchr/testify/suite"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type RBACTestSuite struct {
+	suite.Suite
+
+	passport interfaces.IPassport
+}
+
+func (s *RBACTestSuite) SetupSuite() {
+	s.Require().Nil(container.Invoke(func(passport interfaces.IPassport) {
+		s.passport = passport
+	}))
+}
+
+func (s *RBACTestSuite) TestSyncSeed() {
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	path := filepath.Join(s.T().TempDir(), "rbac.yaml")
+	seed := fmt.Sprintf(`
+permissions:
+  - name: seed%[1]s.read
+    description: read
+  - name: seed%[1]s.write
+    description: write
+roles:
+  - name: seed reader %[1]s
+    permissions: [seed%[1]s.read]
+  - name: seed writer %[1]s
+    parents: [seed reader %[1]s]
+    permissions: [seed%[1]s.write]
+`, suffix)
+	s.Require().Nil(os.WriteFile(path, []byte(seed), 0600))
+	defer s.passport.DeleteRole("seed writer " + suffix)
+	defer s.passport.DeleteRole("seed reader " + suffix)
+	defer s.passport.DeletePermission("seed" + suffix + ".read")
+	defer s.passport.DeletePermission("seed" + suffix + ".write")
+
+	plan, err := migration.SyncRBAC(container, path, false, true)
+	s.Require().Nil(err)
+	s.False(plan.Empty())
+	_, err = s.passport.GetRole("seed reader "+suffix, false)
+	s.NotNil(err)
+
+	_, err = migration.SyncRBAC(container, path, false, false)
+	s.Require().Nil(err)
+	ok, err := s.passport.RoleHasAllPermissions("seed writer "+suffix, []string{"seed" + suffix + ".read", "seed" + suffix + ".write"})
+	s.Require().Nil(err)
+	s.True(ok)
+
+	// syncing again is a no-op
+	plan, err = migration.SyncRBAC(container, path, false, true)
+	s.Require().Nil(err)
+	s.True(plan.Empty(), plan.String())
+}
+
+func (s *RBACTestSuite) TestSyncWithoutPruneKeepsExtras() {
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	path := filepath.Join(s.T().TempDir(), "rbac.yaml")
+	seed := fmt.Sprintf(`
+permissions:
+  - name: keep%[1]s.read
+    description: read
+roles:
+  - name: keep reader %[1]s
+    permissions: [keep%[1]s.read]
+`, suffix)
+	s.Require().Nil(os.WriteFile(path, []byte(seed), 0600))
+	defer s.passport.DeleteRole("keep reader " + suffix)
+	defer s.passport.DeleteRole("keep extra " + suffix)
+	defer s.passport.DeletePermission("keep" + suffix + ".read")
+	defer s.passport.DeletePermission("keep" + suffix + ".extra")
+
+	_, err := migration.SyncRBAC(container, path, false, false)
+	s.Require().Nil(err)
+
+	// grants made outside of the seed
+	_, err = s.passport.CreatePermission("keep"+suffix+".extra", "extra")
+	s.Require().Nil(err)
+	_, err = s.passport.CreateRole("keep extra "+suffix, "")
+	s.Require().Nil(err)
+	s.Require().Nil(s.passport.AddPermissionsToRole("keep reader "+suffix, "keep"+suffix+".extra"))
+	s.Require().Nil(s.passport.AddParentsToRole("keep reader "+suffix, "keep extra "+suffix))
+
+	plan, err := migration.SyncRBAC(container, path, false, false)
+	s.Require().Nil(err)
+	s.True(plan.Empty(), plan.String())
+	ok, err := s.passport.RoleHasAllPermissions("keep reader "+suffix, []string{"keep" + suffix + ".read", "keep" + suffix + ".extra"})
+	s.Require().Nil(err)
+	s.True(ok)
+	parents, err := s.passport.GetParentsOfRole("keep reader " + suffix)
+	s.Require().Nil(err)
+	s.Len(*parents, 1)
+
+	// only pruning detaches them
+	plan, err = migration.SyncRBAC(container, path, true, true)
+	s.Require().Nil(err)
+	s.Contains(plan.String(), "-keep"+suffix+".extra")
+	s.Contains(plan.String(), "-keep-extra-"+suffix)
+}
+
+func (s *RBACTestSuite) TestLoadRejectsUndeclaredReferences() {
+	path := filepath.Join(s.T().TempDir(), "rbac.json")
+	s.Require().Nil(os.WriteFile(path, []byte(`{"roles": [{"name": "orphan", "permissions": ["missing"]}]}`), 0600))
+
+	_, err := rbac.Load(path)
+	s.NotNil(err)
+}
+
+func TestRBACTestSuite(t *testing.T) {
+	suite.Run(t, new(RBACTestSuite))
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type RefreshTestSuite struct {
+	suite.Suite
+
+	jwt jwt.IJWTAuth
+}
+
+func (s *RefreshTestSuite) SetupSuite() {
+	err := container.Invoke(func(jwtauth jwt.IJWTAuth) {
+		s.jwt = jwtauth
+	})
+	s.Require().Nil(err)
+}
+
+// login sign in as the second test user and return the issued tokens
+func (s *RefreshTestSuite) login() schema.UserTokenInfo {
+	var tokenInfo schema.UserTokenInfo
+	code := serveJSON("POST", "/login", "", schema.LoginBodyParams{
+		Username: users[1].Username,
+		Password: "test-user-pwd-2",
+	}, &tokenInfo)
+	s.Require().Equal("SUCCESS", code)
+	s.Require().NotEmpty(tokenInfo.RefreshToken)
+	return tokenInfo
+}
+
+// refresh exchange the refresh token and return code of the response with the new tokens
+func (s *RefreshTestSuite) refresh(refreshToken string) (string, schema.UserTokenInfo) {
+	var tokenInfo schema.UserTokenInfo
+	code := serveJSON("POST", "/refresh", "", schema.RefreshBodyParams{RefreshToken: refreshToken}, &tokenInfo)
+	return code, tokenInfo
+}
+
+// familyOf return family id of the refresh token
+func (s *RefreshTestSuite) familyOf(refreshToken string) string {
+	claims, err := s.jwt.ParseRefreshToken(refreshToken)
+	s.Require().Nil(err)
+	return claims.FamilyID
+}
+
+func (s *RefreshTestSuite) TestRotation() {
+	first := s.login()
+
+	code, second := s.refresh(first.RefreshToken)
+	s.Require().Equal("SUCCESS", code)
+	s.NotEqual(first.RefreshToken, second.RefreshToken)
+	s.NotEqual(first.AccessToken, second.AccessToken)
+	s.Equal(s.familyOf(first.RefreshToken), s.familyOf(second.RefreshToken))
+
+	// the rotated token continues the family
+	code, third := s.refresh(second.RefreshToken)
+	s.Require().Equal("SUCCESS", code)
+	s.Equal(s.familyOf(first.RefreshToken), s.familyOf(third.RefreshToken))
+}
+
+func (s *RefreshTestSuite) TestReuseRevokesFamily() {
+	first := s.login()
+
+	code, second := s.refresh(first.RefreshToken)
+	s.Require().Equal("SUCCESS", code)
+
+	// presenting the used token again revokes the whole family
+	code, _ = s.refresh(first.RefreshToken)
+	s.Equal("ERROR_TOKEN_INVALID", code)
+	code, _ = s.refresh(second.RefreshToken)
+	s.Equal("ERROR_TOKEN_INVALID", code)
+
+	// other families of the user are left alone
+	other := s.login()
+	s.NotEqual(s.familyOf(first.RefreshToken), s.familyOf(other.RefreshToken))
+	code, _ = s.refresh(other.RefreshToken)
+	s.Equal("SUCCESS", code)
+}
+
+func (s *RefreshTestSuite) TestRefreshInvalidToken() {
+	code, _ := s.refresh("not-a-refresh-token")
+	s.NotEqual("SUCCESS", code)
+
+	// access tokens are not accepted as refresh tokens
+	code, _ = s.refresh(s.login().AccessToken)
+	s.NotEqual("SUCCESS", code)
+}
+
+func TestRefreshTestSuite(t *testing.T) {
+	suite.Run(t, new(RefreshTestSuite))
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shasw94/projX/app/revocation"
+	"github.com/shasw94/projX/pkg/jwt"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type RevocationTestSuite struct {
+	suite.Suite
+
+	store *revocation.Store
+}
+
+func (s *RevocationTestSuite) SetupTest() {
+	s.store = revocation.NewMemoryStore(time.Hour)
+}
+
+// claimsOf access token claims of the user issued at the time
+func (s *RevocationTestSuite) claimsOf(userID, jti, sessionID string, issuedAt time.Time) *jwt.AccessClaims {
+	claims := &jwt.AccessClaims{SessionID: sessionID}
+	claims.Subject = userID
+	claims.ID = jti
+	claims.IssuedAt = gojwt.NewNumericDate(issuedAt)
+	return claims
+}
+
+func (s *RevocationTestSuite) isRevoked(claims *jwt.AccessClaims) bool {
+	revoked, err := s.store.IsRevoked(claims)
+	s.Require().Nil(err)
+	return revoked
+}
+
+func (s *RevocationTestSuite) TestRevokeToken() {
+	claims := s.claimsOf("alice", "jti-1", "", time.Now())
+	s.False(s.isRevoked(claims))
+
+	s.Nil(s.store.RevokeToken("jti-1", time.Now().Add(time.Hour)))
+	s.True(s.isRevoked(claims))
+	s.False(s.isRevoked(s.claimsOf("alice", "jti-2", "", time.Now())))
+}
+
+func (s *RevocationTestSuite) TestRevokeSession() {
+	claims := s.claimsOf("alice", "jti-1", "session-1", time.Now())
+	s.Nil(s.store.RevokeSession("session-1"))
+
+	// tokens of the session issued within the same second are revoked as well
+	s.True(s.isRevoked(claims))
+	s.False(s.isRevoked(s.claimsOf("alice", "jti-2", "session-2", time.Now())))
+}
+
+func (s *RevocationTestSuite) TestRevokeUser() {
+	before := s.claimsOf("alice", "jti-1", "session-1", time.Now().Add(-time.Minute))
+	s.Nil(s.store.RevokeUser("alice"))
+
+	s.True(s.isRevoked(before))
+	s.False(s.isRevoked(s.claimsOf("bob", "jti-2", "session-2", time.Now().Add(-time.Minute))))
+}
+
+func (s *RevocationTestSuite) TestRevokeUserSameSecond() {
+	s.Nil(s.store.RevokeUser("alice"))
+
+	// iat is in seconds, a token issued right after the revocation must stay valid
+	s.False(s.isRevoked(s.claimsOf("alice", "jti-1", "session-1", time.Now())))
+}
+
+func (s *RevocationTestSuite) TestRevokeActor() {
+	claims := s.claimsOf("alice", "jti-1", "", time.Now().Add(-time.Minute))
+	claims.Actor = &jwt.Actor{Subject: "admin"}
+	s.False(s.isRevoked(claims))
+
+	s.Nil(s.store.RevokeUser("admin"))
+	s.True(s.isRevoked(claims))
+}
+
+func (s *RevocationTestSuite) TestRevokeClient() {
+	// tokens the client holds for users and for itself
+	forUser := s.claimsOf("alice", "jti-1", "", time.Now().Add(-time.Minute))
+	forUser.ClientID = "client-1"
+	own := s.claimsOf("client-1", "jti-2", "", time.Now().Add(-time.Minute))
+	own.ClientID = "client-1"
+	other := s.claimsOf("alice", "jti-3", "", time.Now().Add(-time.Minute))
+	other.ClientID = "client-2"
+
+	s.Nil(s.store.RevokeClient("client-1"))
+	s.True(s.isRevoked(forUser))
+	s.True(s.isRevoked(own))
+	s.False(s.isRevoked(other))
+	s.False(s.isRevoked(s.claimsOf("alice", "jti-4", "session-1", time.Now().Add(-time.Minute))))
+}
+
+func TestRevocationTestSuite(t *testing.T) {
+	suite.Run(t, new(RevocationTestSuite))
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/shasw94/projX/app/middleware"
+	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/pkg/app"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type SessionCookieTestSuite struct {
+	suite.Suite
+}
+
+func (s *SessionCookieTestSuite) SetupSuite() {
+	config.Config.SessionCookie.Enable = true
+}
+
+func (s *SessionCookieTestSuite) TearDownSuite() {
+	config.Config.SessionCookie.Enable = false
+}
+
+// login sign in and return the session cookies by name
+func (s *SessionCookieTestSuite) login() map[string]*http.Cookie {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, newPostRequest("/login", schema.LoginBodyParams{
+		Username: users[0].Username,
+		Password: "test-user-pwd-1",
+	}))
+
+	var res struct {
+		Code string               `json:"code"`
+		Data schema.UserTokenInfo `json:"data"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	s.Require().Equal("SUCCESS", res.Code)
+	s.Empty(res.Data.AccessToken)
+	s.Empty(res.Data.RefreshToken)
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+func (s *SessionCookieTestSuite) TestLoginSetsCookies() {
+	cookies := s.login()
+	s.Require().Contains(cookies, app.AccessTokenCookie)
+	s.Require().Contains(cookies, app.RefreshTokenCookie)
+	s.Require().Contains(cookies, app.CSRFTokenCookie)
+	s.True(cookies[app.AccessTokenCookie].HttpOnly)
+	s.True(cookies[app.RefreshTokenCookie].HttpOnly)
+	s.False(cookies[app.CSRFTokenCookie].HttpOnly)
+
+	// the access token cookie authenticates requests without Authorization header
+	req, _ := http.NewRequest("GET", "/api/v1/me/sessions", nil)
+	req.AddCookie(cookies[app.AccessTokenCookie])
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var res struct {
+		Code string `json:"code"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	s.Equal("SUCCESS", res.Code)
+}
+
+func (s *SessionCookieTestSuite) TestRefreshByCookie() {
+	cookies := s.login()
+
+	req, _ := http.NewRequest("POST", "/refresh", nil)
+	req.AddCookie(cookies[app.RefreshTokenCookie])
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var res struct {
+		Code string `json:"code"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	s.Equal("SUCCESS", res.Code)
+}
+
+func (s *SessionCookieTestSuite) TestLogoutClearsCookies() {
+	// the test token has no session, so it cannot be revoked
+	req, _ := http.NewRequest("POST", "/logout", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("%s %s", AuthTokenType, token))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var res struct {
+		Code string `json:"code"`
+	}
+	s.Require().Nil(parseReader(w.Body, &res))
+	s.Equal("ERROR_TOKEN_INVALID", res.Code)
+
+	cleared := map[string]bool{}
+	for _, cookie := range w.Result().Cookies() {
+		cleared[cookie.Name] = cookie.MaxAge < 0
+	}
+	s.True(cleared[app.AccessTokenCookie])
+	s.True(cleared[app.RefreshTokenCookie])
+	s.True(cleared[app.CSRFTokenCookie])
+}
+
+func (s *SessionCookieTestSuite) TestCSRF() {
+	e := gin.New()
+	e.Use(middleware.CSRFMiddleware())
+	e.POST("/csrf", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	cookies := s.login()
+
+	call := func(csrf string) int {
+		req, _ := http.NewRequest("POST", "/csrf", nil)
+		req.AddCookie(cookies[app.AccessTokenCookie])
+		req.AddCookie(cookies[app.CSRFTokenCookie])
+		if csrf != "" {
+			req.Header.Set(app.CSRFTokenHeader, csrf)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	s.NotEqual(http.StatusNoContent, call(""))
+	s.NotEqual(http.StatusNoContent, call("forged"))
+	s.Equal(http.StatusNoContent, call(cookies[app.CSRFTokenCookie].Value))
+}
+
+func TestSessionCookieTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionCookieTestSuite))
+}
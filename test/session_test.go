@@ -0,0 +1,99 @@
+package test
+
+import (
+	"github.com/shasw94/projX/app/schema"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type SessionTestSuite struct {
+	suite.Suite
+}
+
+// login sign in from the device as the test user
+func (s *SessionTestSuite) login(username, password, device string) schema.UserTokenInfo {
+	var tokenInfo schema.UserTokenInfo
+	code := serveJSON("POST", "/login", "", schema.LoginBodyParams{
+		Username:   username,
+		Password:   password,
+		ClientInfo: schema.ClientInfo{DeviceLabel: device},
+	}, &tokenInfo)
+	s.Require().Equal("SUCCESS", code)
+	return tokenInfo
+}
+
+// loginDevice sign in from the device as the third test user
+func (s *SessionTestSuite) loginDevice(device string) schema.UserTokenInfo {
+	return s.login(users[2].Username, "test-user-pwd-3", device)
+}
+
+// sessions list sessions of the user by id
+func (s *SessionTestSuite) sessions(accessToken string) map[string]schema.Session {
+	var list []schema.Session
+	s.Require().Equal("SUCCESS", serveJSON("GET", "/api/v1/me/sessions", accessToken, nil, &list))
+
+	sessions := map[string]schema.Session{}
+	for _, session := range list {
+		sessions[session.ID] = session
+	}
+	return sessions
+}
+
+// refresh exchange the refresh token and return code of the response
+func (s *SessionTestSuite) refresh(refreshToken string) string {
+	return serveJSON("POST", "/refresh", "", schema.RefreshBodyParams{RefreshToken: refreshToken}, nil)
+}
+
+func (s *SessionTestSuite) TestList() {
+	laptop := s.loginDevice("laptop")
+	phone := s.loginDevice("phone")
+
+	sessions := s.sessions(laptop.AccessToken)
+	s.Require().Contains(sessions, laptop.SessionID)
+	s.Require().Contains(sessions, phone.SessionID)
+	s.Equal("laptop", sessions[laptop.SessionID].DeviceLabel)
+	s.Equal("phone", sessions[phone.SessionID].DeviceLabel)
+	s.True(sessions[laptop.SessionID].Current)
+	s.False(sessions[phone.SessionID].Current)
+}
+
+func (s *SessionTestSuite) TestRevoke() {
+	laptop := s.loginDevice("laptop")
+	phone := s.loginDevice("phone")
+
+	s.Equal("SUCCESS", serveJSON("DELETE", "/api/v1/me/sessions/"+phone.SessionID, laptop.AccessToken, nil, nil))
+	s.NotContains(s.sessions(laptop.AccessToken), phone.SessionID)
+
+	// tokens of the revoked session stop working at once
+	s.Equal("ERROR_TOKEN_REVOKED", serveJSON("GET", "/api/v1/me/sessions", phone.AccessToken, nil, nil))
+	s.Equal("ERROR_TOKEN_INVALID", s.refresh(phone.RefreshToken))
+	s.Equal("SUCCESS", s.refresh(laptop.RefreshToken))
+}
+
+func (s *SessionTestSuite) TestRevokeSessionOfOtherUser() {
+	laptop := s.loginDevice("laptop")
+	other := s.login(users[1].Username, "test-user-pwd-2", "laptop")
+
+	code := serveJSON("DELETE", "/api/v1/me/sessions/"+other.SessionID, laptop.AccessToken, nil, nil)
+	s.Equal("ERROR_NOT_FOUND", code)
+	s.Equal("SUCCESS", s.refresh(other.RefreshToken))
+}
+
+func (s *SessionTestSuite) TestRevokeOthers() {
+	laptop := s.loginDevice("laptop")
+	phone := s.loginDevice("phone")
+	tablet := s.loginDevice("tablet")
+
+	s.Equal("SUCCESS", serveJSON("DELETE", "/api/v1/me/sessions", laptop.AccessToken, nil, nil))
+
+	sessions := s.sessions(laptop.AccessToken)
+	s.Contains(sessions, laptop.SessionID)
+	s.NotContains(sessions, phone.SessionID)
+	s.NotContains(sessions, tablet.SessionID)
+	s.Equal("ERROR_TOKEN_INVALID", s.refresh(phone.RefreshToken))
+	s.Equal("ERROR_TOKEN_INVALID", s.refresh(tablet.RefreshToken))
+}
+
+func TestSessionTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionTestSuite))
+}
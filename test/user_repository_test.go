@@ -7,6 +7,9 @@ import (
 	"github.com/shasw94/projX/app/models"
 	"github.com/shasw94/projX/app/repositories"
 	"github.com/shasw94/projX/app/schema"
+	"github.com/shasw94/projX/pkg/errors"
+	"github.com/shasw94/projX/pkg/hasher"
+	"github.com/shasw94/projX/pkg/utils"
 	"github.com/stretchr/testify/suite"
 	"testing"
 	"time"
@@ -20,11 +23,10 @@ var (
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
 			},
-			Username:     "test-username-1",
-			Email:        "testuseremail1@tokoin.io",
-			Password:     "test-user-pwd-1",
-			RefreshToken: "test-user-refresh-token-1",
-			RoleID:       roles[0].ID,
+			Username: "test-username-1",
+			Email:    "testuseremail1@tokoin.io",
+			Password: "test-user-pwd-1",
+			RoleID:   roles[0].ID,
 		},
 		{
 			Model: models.Model{
@@ -32,11 +34,10 @@ var (
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
 			},
-			Username:     "test-username-2",
-			Email:        "testuseremail2@tokoin.io",
-			Password:     "test-user-pwd-2",
-			RefreshToken: "test-user-refresh-token-2",
-			RoleID:       roles[0].ID,
+			Username: "test-username-2",
+			Email:    "testuseremail2@tokoin.io",
+			Password: "test-user-pwd-2",
+			RoleID:   roles[0].ID,
 		},
 		{
 			Model: models.Model{
@@ -44,11 +45,10 @@ var (
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
 			},
-			Username:     "test-username-3",
-			Email:        "testuseremail3@tokoin.io",
-			Password:     "test-user-pwd-3",
-			RefreshToken: "test-user-refresh-token-3",
-			RoleID:       roles[0].ID,
+			Username: "test-username-3",
+			Email:    "testuseremail3@tokoin.io",
+			Password: "test-user-pwd-3",
+			RoleID:   roles[0].ID,
 		},
 	}
 
@@ -83,19 +83,6 @@ func (s *UserRepositoryTestSuite) TestGetByIDNotFound() {
 	s.Nil(u)
 }
 
-func (s *UserRepositoryTestSuite) TestGetByTokenSuccess() {
-	u, err := s.repo.GetUserByToken(user.RefreshToken)
-	s.Nil(err)
-	s.NotNil(u)
-	s.Equal(user.ID, u.ID)
-}
-
-func (s *UserRepositoryTestSuite) TestGetByTokenNotFound() {
-	u, err := s.repo.GetUserByToken("not-found-token")
-	s.NotNil(err)
-	s.Nil(u)
-}
-
 func (s *UserRepositoryTestSuite) TestListFull() {
 	usrs, err := s.repo.List(&schema.UserQueryParam{
 		Offset: 0,
@@ -126,21 +113,49 @@ func (s *UserRepositoryTestSuite) TestLoginFailed() {
 	user, err := s.repo.Login(item)
 	s.NotNil(err)
 	s.Nil(user)
+	s.Equal(errors.ErrorLoginFailed, errors.GetType(err))
 }
 
-func (s *UserRepositoryTestSuite) TestRemoveTokenSuccess() {
-	u, err := s.repo.RemoveToken(users[1].ID)
-	s.Nil(err)
-	s.NotNil(u)
+func (s *UserRepositoryTestSuite) TestLoginUnknownUser() {
+	item := &schema.LoginBodyParams{
+		Username: "test-username-unknown",
+		Password: "test-user-pwd-1",
+	}
 
-	u, err = s.repo.GetByID(users[1].ID)
-	s.Nil(err)
-	s.NotNil(u)
-	s.Equal("", u.RefreshToken)
+	user, err := s.repo.Login(item)
+	s.Nil(user)
+	s.Equal(errors.ErrorLoginFailed, errors.GetType(err))
+}
+
+func (s *UserRepositoryTestSuite) TestLoginRehashesLegacyPassword() {
+	legacy, err := hasher.NewBcrypt(4).Hash("test-user-pwd-3")
+	s.Require().Nil(err)
+	s.Require().Nil(s.repo.UpdatePassword(users[2].ID, legacy))
+
+	u, err := s.repo.GetByID(users[2].ID)
+	s.Require().Nil(err)
+	s.Equal(legacy, u.Password)
+
+	u, err = s.repo.Login(&schema.LoginBodyParams{Username: users[2].Username, Password: "test-user-pwd-3"})
+	s.Require().Nil(err)
+	s.False(hasher.Default().NeedsRehash(u.Password))
+
+	u, err = s.repo.GetByID(users[2].ID)
+	s.Require().Nil(err)
+	s.NotEqual(legacy, u.Password)
+	s.False(hasher.Default().NeedsRehash(u.Password))
 }
 
-func (s *UserRepositoryTestSuite) TestRemoveTokenNotFound() {
-	_, err := s.repo.RemoveToken("not-found-id")
+func (s *UserRepositoryTestSuite) TestUpdatePassword() {
+	hashed, err := utils.HashPassword([]byte("test-user-pwd-2"))
+	s.Require().Nil(err)
+	s.Require().Nil(s.repo.UpdatePassword(users[1].ID, hashed))
+
+	u, err := s.repo.GetByID(users[1].ID)
+	s.Require().Nil(err)
+	s.Equal(hashed, u.Password)
+
+	_, err = s.repo.Login(&schema.LoginBodyParams{Username: users[1].Username, Password: "test-user-pwd-2"})
 	s.Nil(err)
 }
 
@@ -62,17 +62,20 @@ func getDefaultOption() *option {
 	}
 
 	_ = v.RegisterTranslation("password", trans, func(ut ut.Translator) error {
-		return ut.Add("password", "{0} is not strong enough, password must be at least 6 characters", true)
+		return ut.Add("password", "{0} {1}", true)
 	}, func(ut ut.Translator, fe validator.FieldError) string {
-		t, _ := ut.T("password", fe.Field())
+		value, _ := fe.Value().(string)
+		rules := DefaultPasswordPolicy().Check(value, "", "")
+		if len(rules) == 0 {
+			rules = []string{"must not contain the username or the email address"}
+		}
+		t, _ := ut.T("password", fe.Field(), strings.Join(rules, ", "))
 		return t
 	})
 
 	_ = v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
-		if len(fl.Field().String()) < 6 {
-			return false
-		}
-		return true
+		username, email := passwordOwner(fl.Parent())
+		return len(DefaultPasswordPolicy().Check(fl.Field().String(), username, email)) == 0
 	})
 
 	_ = v.RegisterTranslation("countryCode", trans, func(ut ut.Translator) error {
@@ -0,0 +1,190 @@
+package validation
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/shasw94/projX/config"
+	"github.com/shasw94/projX/logger"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultPasswordMinLength minimum password length when not configured
+	defaultPasswordMinLength = 6
+	// minIdentityLength identities shorter than this are not banned from passwords
+	minIdentityLength = 3
+)
+
+var (
+	breachedOnce sync.Once
+	breached     map[string]struct{}
+)
+
+// PasswordPolicy rules passwords have to satisfy
+type PasswordPolicy struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	BanUsername   bool
+	BanEmail      bool
+	// Breached lowercase passwords which are rejected
+	Breached map[string]struct{}
+}
+
+// PasswordError password does not satisfy the policy
+type PasswordError struct {
+	Rules []string
+}
+
+// Error list every failed rule
+func (e *PasswordError) Error() string {
+	return "password " + strings.Join(e.Rules, ", ")
+}
+
+// DefaultPasswordPolicy return password policy of config
+func DefaultPasswordPolicy() *PasswordPolicy {
+	conf := config.Config.PasswordPolicy
+	policy := &PasswordPolicy{
+		MinLength:     conf.MinLength,
+		MaxLength:     conf.MaxLength,
+		RequireUpper:  conf.RequireUpper,
+		RequireLower:  conf.RequireLower,
+		RequireDigit:  conf.RequireDigit,
+		RequireSymbol: conf.RequireSymbol,
+		BanUsername:   conf.BanUsername,
+		BanEmail:      conf.BanEmail,
+		Breached:      breachedPasswords(),
+	}
+	if policy.MinLength <= 0 {
+		policy.MinLength = defaultPasswordMinLength
+	}
+	return policy
+}
+
+// breachedPasswords load the configured breached password list once
+func breachedPasswords() map[string]struct{} {
+	breachedOnce.Do(func() {
+		path := config.Config.PasswordPolicy.BreachedList
+		if path == "" {
+			return
+		}
+
+		list, err := LoadPasswordList(config.Path(path))
+		if err != nil {
+			logger.Warnf("Failed to load breached password list %s: %s", path, err)
+			return
+		}
+		breached = list
+	})
+	return breached
+}
+
+// LoadPasswordList read newline-delimited password list, empty lines and lines starting with # are skipped
+func LoadPasswordList(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := map[string]struct{}{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		list[strings.ToLower(line)] = struct{}{}
+	}
+	return list, scanner.Err()
+}
+
+// Check return every rule the password fails, username and email of its owner are banned if configured
+func (p *PasswordPolicy) Check(password, username, email string) []string {
+	var rules []string
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		rules = append(rules, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		rules = append(rules, fmt.Sprintf("must be at most %d characters", p.MaxLength))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		rules = append(rules, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		rules = append(rules, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		rules = append(rules, "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		rules = append(rules, "must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if p.BanUsername && containsIdentity(lowered, username) {
+		rules = append(rules, "must not contain the username")
+	}
+	if p.BanEmail && containsIdentity(lowered, strings.SplitN(email, "@", 2)[0]) {
+		rules = append(rules, "must not contain the email address")
+	}
+	if _, ok := p.Breached[lowered]; ok {
+		rules = append(rules, "is too common or has appeared in a data breach")
+	}
+	return rules
+}
+
+// containsIdentity the lowercase password contains the identity
+func containsIdentity(password, identity string) bool {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	return utf8.RuneCountInString(identity) >= minIdentityLength && strings.Contains(password, identity)
+}
+
+// CheckPassword check password of user against the configured policy,
+// return *PasswordError listing every failed rule
+func CheckPassword(password, username, email string) error {
+	if rules := DefaultPasswordPolicy().Check(password, username, email); len(rules) > 0 {
+		return &PasswordError{Rules: rules}
+	}
+	return nil
+}
+
+// passwordOwner username and email fields of the struct holding the password, empty if absent
+func passwordOwner(parent reflect.Value) (string, string) {
+	parent = reflect.Indirect(parent)
+	if parent.Kind() != reflect.Struct {
+		return "", ""
+	}
+
+	field := func(name string) string {
+		f := parent.FieldByName(name)
+		if f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+		return ""
+	}
+	return field("Username"), field("Email")
+}
@@ -1,9 +1,11 @@
 package validation
 
 import (
-	"fmt"
+	"errors"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
 )
 
 type validation struct {
@@ -21,14 +23,51 @@ func New(opts ...Option) Validation {
 func (v *validation) ValidateStruct(s interface{}) error {
 	err := v.validator.Struct(s)
 	if err != nil {
-		return v.Translate(err)
+		return v.translate(s, err)
 	}
 	return nil
 }
 
+// Translate return error listing every failed rule
 func (v *validation) Translate(err error) error {
-	for _, e := range err.(validator.ValidationErrors) {
-		return fmt.Errorf(e.Translate(*v.trans))
+	return v.translate(nil, err)
+}
+
+// translate every validation error, password errors list all failed rules
+// of the policy including the ones about username and email of s
+func (v *validation) translate(s interface{}, err error) error {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, e := range validationErrors {
+		if e.Tag() == "password" && s != nil {
+			value, _ := e.Value().(string)
+			username, email := passwordOwner(parentOf(s, e.StructNamespace()))
+			if rules := DefaultPasswordPolicy().Check(value, username, email); len(rules) > 0 {
+				messages = append(messages, e.Field()+" "+strings.Join(rules, ", "))
+				continue
+			}
+		}
+		messages = append(messages, e.Translate(*v.trans))
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// parentOf return struct holding the field of namespace, e.g. "Params.Password" in s
+func parentOf(s interface{}, namespace string) reflect.Value {
+	parent := reflect.Indirect(reflect.ValueOf(s))
+	names := strings.Split(namespace, ".")
+	if len(names) < 2 {
+		return reflect.Value{}
+	}
+	for _, name := range names[1 : len(names)-1] {
+		if parent.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		parent = reflect.Indirect(parent.FieldByName(name))
 	}
-	return err
+	return parent
 }